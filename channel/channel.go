@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/state"
@@ -192,6 +193,14 @@ func (c Channel) LatestSupportedState() (state.State, error) {
 	return c.SignedStateForTurnNum[c.latestSupportedStateTurnNum].State(), nil
 }
 
+// LatestSupportedSignedState returns the latest supported state, along with the signatures of every participant.
+func (c Channel) LatestSupportedSignedState() (state.SignedState, error) {
+	if c.latestSupportedStateTurnNum == MaxTurnNum {
+		return state.SignedState{}, errors.New(`no state is yet supported`)
+	}
+	return c.SignedStateForTurnNum[c.latestSupportedStateTurnNum], nil
+}
+
 // LatestSignedState fetches the state with the largest turn number signed by at least one participant.
 func (c Channel) LatestSignedState() (state.SignedState, error) {
 	if len(c.SignedStateForTurnNum) == 0 {
@@ -206,6 +215,23 @@ func (c Channel) LatestSignedState() (state.SignedState, error) {
 	return c.SignedStateForTurnNum[latestTurn], nil
 }
 
+// SignedStatesSignedByMe returns every signed state carrying a signature from the calling client, ordered by turn number.
+func (c Channel) SignedStatesSignedByMe() []state.SignedState {
+	turnNums := make([]uint64, 0, len(c.SignedStateForTurnNum))
+	for turnNum, ss := range c.SignedStateForTurnNum {
+		if ss.HasSignatureForParticipant(c.MyIndex) {
+			turnNums = append(turnNums, turnNum)
+		}
+	}
+	sort.Slice(turnNums, func(i, j int) bool { return turnNums[i] < turnNums[j] })
+
+	signed := make([]state.SignedState, len(turnNums))
+	for i, turnNum := range turnNums {
+		signed[i] = c.SignedStateForTurnNum[turnNum]
+	}
+	return signed
+}
+
 // Total() returns the total allocated of each asset allocated by the pre fund setup state of the Channel.
 func (c Channel) Total() types.Funds {
 	return c.PreFundState().Outcome.TotalAllocated()
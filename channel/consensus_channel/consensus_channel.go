@@ -25,6 +25,7 @@ var (
 	ErrDuplicateGuarantee = fmt.Errorf("duplicate guarantee detected")
 	ErrGuaranteeNotFound  = fmt.Errorf("guarantee not found")
 	ErrInvalidAmount      = fmt.Errorf("left amount is greater than the guarantee amount")
+	ErrMalformedProposal  = fmt.Errorf("proposal has a missing or negative amount")
 )
 
 const (
@@ -109,6 +110,9 @@ func (c *ConsensusChannel) FixedPart() state.FixedPart {
 // validates its signature, and performs updates to the proposal queue and
 // consensus state.
 func (c *ConsensusChannel) Receive(sp SignedProposal) error {
+	if err := sp.Proposal.validate(); err != nil {
+		return err
+	}
 	if c.IsFollower() {
 		return c.followerReceive(sp)
 	}
@@ -287,46 +291,49 @@ func (c *ConsensusChannel) validateProposalID(propsal Proposal) error {
 }
 
 // NewBalance returns a new Balance struct with the given destination and amount.
-func NewBalance(destination types.Destination, amount *big.Int) Balance {
-	balanceAmount := big.NewInt(0).Set(amount)
+func NewBalance(destination types.Destination, amount types.Funds) Balance {
 	return Balance{
 		destination: destination,
-		amount:      balanceAmount,
+		amount:      amount.Clone(),
 	}
 
 }
 
-// Balance is a convenient, ergonomic representation of a single-asset Allocation
-// of type 0, ie. a simple allocation.
+// Balance is a convenient, ergonomic representation of a participant's Allocations
+// of type 0, ie. simple allocations, across every asset in the ledger.
 type Balance struct {
 	destination types.Destination
-	amount      *big.Int
+	amount      types.Funds
 }
 
 // Equal returns true if the balances are deeply equal, false otherwise.
 func (b Balance) Equal(b2 Balance) bool {
 	return bytes.Equal(b.destination.Bytes(), b2.destination.Bytes()) &&
-		types.Equal(b.amount, b2.amount)
+		b.amount.Equal(b2.amount)
 }
 
 // Clone returns a deep copy of the receiver.
 func (b *Balance) Clone() Balance {
 	return Balance{
 		destination: b.destination,
-		amount:      big.NewInt(0).Set(b.amount),
+		amount:      b.amount.Clone(),
 	}
 }
 
-// AsAllocation converts a Balance struct into the on-chain outcome.Allocation type.
-func (b Balance) AsAllocation() outcome.Allocation {
-	amount := big.NewInt(0).Set(b.amount)
-	return outcome.Allocation{Destination: b.destination, Amount: amount, AllocationType: outcome.NormalAllocationType}
+// Amount returns the balance held for the given asset.
+func (b Balance) Amount(asset types.Address) *big.Int {
+	return big.NewInt(0).Set(amountOf(b.amount, asset))
+}
+
+// AsAllocation converts the Balance for the given asset into the on-chain outcome.Allocation type.
+func (b Balance) AsAllocation(asset types.Address) outcome.Allocation {
+	return outcome.Allocation{Destination: b.destination, Amount: b.Amount(asset), AllocationType: outcome.NormalAllocationType}
 }
 
-// Guarantee is a convenient, ergonomic representation of a
-// single-asset Allocation of type 1, ie. a guarantee.
+// Guarantee is a convenient, ergonomic representation of the
+// Allocations of type 1, ie. guarantees, for a single target across every asset in the ledger.
 type Guarantee struct {
-	amount *big.Int
+	amount types.Funds
 	target types.Destination
 	left   types.Destination
 	right  types.Destination
@@ -335,7 +342,7 @@ type Guarantee struct {
 // Clone returns a deep copy of the receiver.
 func (g *Guarantee) Clone() Guarantee {
 	return Guarantee{
-		amount: big.NewInt(0).Set(g.amount),
+		amount: g.amount.Clone(),
 		target: g.target,
 		left:   g.left,
 		right:  g.right,
@@ -347,39 +354,54 @@ func (g Guarantee) Target() types.Destination {
 	return g.target
 }
 
+// Amount returns the amount guaranteed for the given asset.
+func (g Guarantee) Amount(asset types.Address) *big.Int {
+	return big.NewInt(0).Set(amountOf(g.amount, asset))
+}
+
 // NewGuarantee constructs a new guarantee.
-func NewGuarantee(amount *big.Int, target types.Destination, left types.Destination, right types.Destination) Guarantee {
+func NewGuarantee(amount types.Funds, target types.Destination, left types.Destination, right types.Destination) Guarantee {
 	return Guarantee{amount, target, left, right}
 }
 
 func (g Guarantee) equal(g2 Guarantee) bool {
-	if !types.Equal(g.amount, g2.amount) {
+	if !g.amount.Equal(g2.amount) {
 		return false
 	}
 	return g.target == g2.target && g.left == g2.left && g.right == g2.right
 }
 
-// AsAllocation converts a Balance struct into the on-chain outcome.Allocation type
-func (g Guarantee) AsAllocation() outcome.Allocation {
-	amount := big.NewInt(0).Set(g.amount)
+// AsAllocation converts the Guarantee for the given asset into the on-chain outcome.Allocation type
+func (g Guarantee) AsAllocation(asset types.Address) outcome.Allocation {
+	// Encoding a pair of destinations cannot fail
+	metadata, _ := outcome.GuaranteeMetadata{Left: g.left, Right: g.right}.Encode()
 	return outcome.Allocation{
 		Destination:    g.target,
-		Amount:         amount,
-		AllocationType: 1,
-		Metadata:       append(g.left.Bytes(), g.right.Bytes()...),
+		Amount:         g.Amount(asset),
+		AllocationType: outcome.GuaranteeAllocationType,
+		Metadata:       metadata,
 	}
 }
 
+// amountOf returns the amount held for the given asset, treating a missing asset as zero.
+func amountOf(f types.Funds, asset types.Address) *big.Int {
+	if amount, ok := f[asset]; ok && amount != nil {
+		return amount
+	}
+	return big.NewInt(0)
+}
+
 // LedgerOutcome encodes the outcome of a ledger channel involving a "leader" and "follower"
-// participant.
+// participant. The ledger may hold any number of assets.
 //
-// This struct does not store items in sorted order. The conventional ordering of allocation items is:
+// This struct does not store items in sorted order. The conventional ordering of allocation items,
+// for each asset in turn, is:
 // [leader, follower, ...guaranteesSortedbyTargetDestination]
 type LedgerOutcome struct {
-	assetAddress types.Address // Address of the asset type
-	leader       Balance       // Balance of participants[0]
-	follower     Balance       // Balance of participants[1]
-	guarantees   map[types.Destination]Guarantee
+	assets     []types.Address // Addresses of the asset types, in the order they appear on chain
+	leader     Balance         // Balance of participants[0]
+	follower   Balance         // Balance of participants[1]
+	guarantees map[types.Destination]Guarantee
 }
 
 // Clone returns a deep copy of the receiver.
@@ -389,13 +411,18 @@ func (lo *LedgerOutcome) Clone() LedgerOutcome {
 		clonedGuarantees[key] = g.Clone()
 	}
 	return LedgerOutcome{
-		assetAddress: lo.assetAddress,
-		leader:       lo.leader.Clone(),
-		follower:     lo.follower.Clone(),
-		guarantees:   clonedGuarantees,
+		assets:     append([]types.Address{}, lo.assets...),
+		leader:     lo.leader.Clone(),
+		follower:   lo.follower.Clone(),
+		guarantees: clonedGuarantees,
 	}
 }
 
+// Assets returns the addresses of the assets held in the ledger.
+func (lo *LedgerOutcome) Assets() []types.Address {
+	return append([]types.Address{}, lo.assets...)
+}
+
 // Leader returns the leader's balance.
 func (lo *LedgerOutcome) Leader() Balance {
 	return lo.leader
@@ -406,17 +433,17 @@ func (lo *LedgerOutcome) Follower() Balance {
 	return lo.follower
 }
 
-// NewLedgerOutcome creates a new ledger outcome with the given asset address, balances, and guarantees.
-func NewLedgerOutcome(assetAddress types.Address, leader, follower Balance, guarantees []Guarantee) *LedgerOutcome {
+// NewLedgerOutcome creates a new ledger outcome with the given asset addresses, balances, and guarantees.
+func NewLedgerOutcome(assets []types.Address, leader, follower Balance, guarantees []Guarantee) *LedgerOutcome {
 	guaranteeMap := make(map[types.Destination]Guarantee, len(guarantees))
 	for _, g := range guarantees {
 		guaranteeMap[g.target] = g
 	}
 	return &LedgerOutcome{
-		assetAddress: assetAddress,
-		leader:       leader,
-		follower:     follower,
-		guarantees:   guaranteeMap,
+		assets:     append([]types.Address{}, assets...),
+		leader:     leader,
+		follower:   follower,
+		guarantees: guaranteeMap,
 	}
 }
 
@@ -436,56 +463,94 @@ func (o *LedgerOutcome) includes(g Guarantee) bool {
 	return g.left == existing.left &&
 		g.right == existing.right &&
 		g.target == existing.target &&
-		types.Equal(existing.amount, g.amount)
+		existing.amount.Equal(g.amount)
 }
 
-// FromExit creates a new LedgerOutcome from the given SingleAssetExit.
+// FromExit creates a new LedgerOutcome from the given Exit.
 //
-// It makes the following assumptions about the exit:
+// It makes the following assumptions about each SingleAssetExit in the exit:
 //  - The first alloction entry is for the ledger leader
 //  - The second alloction entry is for the ledger follower
 //  - All other allocations are guarantees
-func FromExit(sae outcome.SingleAssetExit) (LedgerOutcome, error) {
+//
+// An error is returned if the leader, follower or guarantee metadata are not the same for every asset.
+func FromExit(exit outcome.Exit) (LedgerOutcome, error) {
+	if len(exit) == 0 {
+		return LedgerOutcome{}, fmt.Errorf("a ledger outcome must hold at least one asset")
+	}
+	for _, sae := range exit {
+		if len(sae.Allocations) < 2 {
+			return LedgerOutcome{}, fmt.Errorf("expected leader and follower allocations for asset %s", sae.Asset)
+		}
+	}
 
 	var (
-		leader     = Balance{destination: sae.Allocations[0].Destination, amount: sae.Allocations[0].Amount}
-		follower   = Balance{destination: sae.Allocations[1].Destination, amount: sae.Allocations[1].Amount}
+		assets     = make([]types.Address, 0, len(exit))
+		leader     = Balance{destination: exit[0].Allocations[0].Destination, amount: types.Funds{}}
+		follower   = Balance{destination: exit[0].Allocations[1].Destination, amount: types.Funds{}}
 		guarantees = make(map[types.Destination]Guarantee)
 	)
 
-	for _, a := range sae.Allocations {
-
-		if a.AllocationType == outcome.GuaranteeAllocationType {
-			gM, err := outcome.DecodeIntoGuaranteeMetadata(a.Metadata)
-
-			if err != nil {
-				return LedgerOutcome{}, fmt.Errorf("failed to decode guarantee metadata: %w", err)
+	for _, sae := range exit {
+		asset := sae.Asset
+		for _, existing := range assets {
+			if existing == asset {
+				return LedgerOutcome{}, fmt.Errorf("duplicate asset %s in exit", asset)
 			}
+		}
+		assets = append(assets, asset)
 
-			g := Guarantee{
-				amount: a.Amount,
-				target: a.Destination,
-				left:   gM.Left,
-				right:  gM.Right,
+		if sae.Allocations[0].Destination != leader.destination || sae.Allocations[1].Destination != follower.destination {
+			return LedgerOutcome{}, fmt.Errorf("leader and follower allocations for asset %s do not match the first asset", asset)
+		}
+		for _, a := range sae.Allocations {
+			if a.Amount == nil {
+				return LedgerOutcome{}, fmt.Errorf("missing amount for an allocation of asset %s", asset)
 			}
-			guarantees[a.Destination] = g
 		}
+		leader.amount[asset] = big.NewInt(0).Set(sae.Allocations[0].Amount)
+		follower.amount[asset] = big.NewInt(0).Set(sae.Allocations[1].Amount)
+
+		for _, a := range sae.Allocations {
+
+			if a.AllocationType == outcome.GuaranteeAllocationType {
+				gM, err := outcome.DecodeIntoGuaranteeMetadata(a.Metadata)
+
+				if err != nil {
+					return LedgerOutcome{}, fmt.Errorf("failed to decode guarantee metadata: %w", err)
+				}
+
+				g, found := guarantees[a.Destination]
+				if !found {
+					g = Guarantee{
+						amount: types.Funds{},
+						target: a.Destination,
+						left:   gM.Left,
+						right:  gM.Right,
+					}
+				} else if g.left != gM.Left || g.right != gM.Right {
+					return LedgerOutcome{}, fmt.Errorf("guarantee metadata for target %s does not match across assets", a.Destination)
+				}
+				g.amount[asset] = big.NewInt(0).Set(a.Amount)
+				guarantees[a.Destination] = g
+			}
 
+		}
 	}
 
-	return LedgerOutcome{leader: leader, follower: follower, guarantees: guarantees, assetAddress: sae.Asset}, nil
+	return LedgerOutcome{leader: leader, follower: follower, guarantees: guarantees, assets: assets}, nil
 
 }
 
-// AsOutcome converts a LedgerOutcome to an on-chain exit according to the following convention:
+// AsOutcome converts a LedgerOutcome to an on-chain exit with one SingleAssetExit per asset,
+// each following the convention:
 //  - the "leader" balance is first
 //  - the "follower" balance is second
 //  - guarantees follow, sorted according to their target destinations
+//
+// Every guarantee appears in every SingleAssetExit, with a zero amount for assets it does not guarantee.
 func (o *LedgerOutcome) AsOutcome() outcome.Exit {
-	// The first items are [leader, follower] balances
-	allocations := outcome.Allocations{o.leader.AsAllocation(), o.follower.AsAllocation()}
-
-	// Followed by guarantees, _sorted by the target destination_
+	// Guarantees are _sorted by the target destination_
 	keys := make([]types.Destination, 0, len(o.guarantees))
 	for k := range o.guarantees {
 		keys = append(keys, k)
@@ -494,16 +559,33 @@ func (o *LedgerOutcome) AsOutcome() outcome.Exit {
 		return keys[i].String() < keys[j].String()
 	})
 
-	for _, target := range keys {
-		allocations = append(allocations, o.guarantees[target].AsAllocation())
-	}
+	exit := make(outcome.Exit, 0, len(o.assets))
+	for _, asset := range o.assets {
+		// The first items are [leader, follower] balances
+		allocations := outcome.Allocations{o.leader.AsAllocation(asset), o.follower.AsAllocation(asset)}
+
+		// Followed by guarantees
+		for _, target := range keys {
+			allocations = append(allocations, o.guarantees[target].AsAllocation(asset))
+		}
 
-	return outcome.Exit{
-		outcome.SingleAssetExit{
-			Asset:       o.assetAddress,
+		exit = append(exit, outcome.SingleAssetExit{
+			Asset:       asset,
 			Allocations: allocations,
-		},
+		})
+	}
+
+	return exit
+}
+
+// holds returns true if the given asset is one of the assets held in the ledger.
+func (o *LedgerOutcome) holds(asset types.Address) bool {
+	for _, a := range o.assets {
+		if a == asset {
+			return true
+		}
 	}
+	return false
 }
 
 // fundingTargets returns a list of channels funded by the LedgerOutcome
@@ -533,31 +615,7 @@ func (v *Vars) Clone() Vars {
 
 // clone returns a deep clone of v.
 func (o *LedgerOutcome) clone() LedgerOutcome {
-	assetAddress := o.assetAddress
-
-	leader := Balance{
-		destination: o.leader.destination,
-		amount:      big.NewInt(0).Set(o.leader.amount),
-	}
-
-	follower := Balance{
-		destination: o.follower.destination,
-		amount:      big.NewInt(0).Set(o.follower.amount),
-	}
-
-	guarantees := make(map[types.Destination]Guarantee)
-	for d, g := range o.guarantees {
-		g2 := g
-		g2.amount = big.NewInt(0).Set(g.amount)
-		guarantees[d] = g2
-	}
-
-	return LedgerOutcome{
-		assetAddress: assetAddress,
-		leader:       leader,
-		follower:     follower,
-		guarantees:   guarantees,
-	}
+	return o.Clone()
 }
 
 // SignedVars stores 0-2 signatures for some vars in a consensus channel.
@@ -608,8 +666,7 @@ type ProposalType string
 
 // Type returns the type of the proposal based on whether it contains an Add or a Remove proposal.
 func (p *Proposal) Type() ProposalType {
-	zeroAdd := Add{}
-	if p.ToAdd != zeroAdd {
+	if !p.ToAdd.isZero() {
 		return AddProposal
 	} else {
 		return RemoveProposal
@@ -667,10 +724,10 @@ func (sp *SignedProposal) Clone() SignedProposal {
 // Add encodes a proposal to add a guarantee to a ConsensusChannel.
 type Add struct {
 	Guarantee
-	// LeftDeposit is the portion of the Add's amount that will be deducted from left participant's ledger balance.
+	// LeftDeposit is the portion of the Add's amount, per asset, that will be deducted from left participant's ledger balance.
 	//
 	// The right participant's deduction is computed as the difference between the guarantee amount and LeftDeposit.
-	LeftDeposit *big.Int
+	LeftDeposit types.Funds
 }
 
 // Clone returns a deep copy of the receiver.
@@ -680,12 +737,21 @@ func (a *Add) Clone() Add {
 	}
 	return Add{
 		a.Guarantee.Clone(),
-		big.NewInt(0).Set(a.LeftDeposit),
+		a.LeftDeposit.Clone(),
 	}
 }
 
+// isZero returns true if the receiver is the zero value, ie. if it does not encode a proposal.
+func (a Add) isZero() bool {
+	return a.target == types.Destination{} &&
+		a.left == types.Destination{} &&
+		a.right == types.Destination{} &&
+		len(a.amount) == 0 &&
+		len(a.LeftDeposit) == 0
+}
+
 // NewAdd constructs a new Add proposal.
-func NewAdd(g Guarantee, leftDeposit *big.Int) Add {
+func NewAdd(g Guarantee, leftDeposit types.Funds) Add {
 	return Add{
 		Guarantee:   g,
 		LeftDeposit: leftDeposit,
@@ -693,36 +759,57 @@ func NewAdd(g Guarantee, leftDeposit *big.Int) Add {
 }
 
 // NewAddProposal constucts a proposal with a valid Add proposal and empty remove proposal.
-func NewAddProposal(ledgerID types.Destination, g Guarantee, leftDeposit *big.Int) Proposal {
+func NewAddProposal(ledgerID types.Destination, g Guarantee, leftDeposit types.Funds) Proposal {
 	return Proposal{ToAdd: NewAdd(g, leftDeposit), LedgerID: ledgerID}
 }
 
 // NewRemove constructs a new Remove proposal.
-func NewRemove(target types.Destination, leftAmount *big.Int) Remove {
+func NewRemove(target types.Destination, leftAmount types.Funds) Remove {
 	return Remove{Target: target, LeftAmount: leftAmount}
 }
 
 // NewRemoveProposal constucts a proposal with a valid Remove proposal and empty Add proposal.
-func NewRemoveProposal(ledgerID types.Destination, target types.Destination, leftAmount *big.Int) Proposal {
+func NewRemoveProposal(ledgerID types.Destination, target types.Destination, leftAmount types.Funds) Proposal {
 	return Proposal{ToRemove: NewRemove(target, leftAmount), LedgerID: ledgerID}
 }
 
-// RightDeposit computes the deposit from the right participant such that
+// RightDeposit computes the deposit, per asset, from the right participant such that
 // a.LeftDeposit + a.RightDeposit() fully funds a's guarantee.
-func (a Add) RightDeposit() *big.Int {
-	result := big.NewInt(0)
-	result.Sub(a.amount, a.LeftDeposit)
+func (a Add) RightDeposit() types.Funds {
+	result := types.Funds{}
+	for asset, amount := range a.amount {
+		result[asset] = big.NewInt(0).Sub(amount, amountOf(a.LeftDeposit, asset))
+	}
 
 	return result
 }
 
+// validate returns ErrMalformedProposal if any of the amounts in the proposal is missing or negative.
+// Proposals are received from counterparties, so they are validated before they are compared or applied.
+func (p Proposal) validate() error {
+	if !validAmounts(p.ToAdd.amount) || !validAmounts(p.ToAdd.LeftDeposit) || !validAmounts(p.ToRemove.LeftAmount) {
+		return ErrMalformedProposal
+	}
+	return nil
+}
+
+// validAmounts returns true if every amount in the funds is present and not negative.
+func validAmounts(f types.Funds) bool {
+	for _, amount := range f {
+		if amount == nil || amount.Sign() < 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func (a Add) equal(a2 Add) bool {
-	return a.Guarantee.equal(a2.Guarantee) && types.Equal(a.LeftDeposit, a2.LeftDeposit)
+	return a.Guarantee.equal(a2.Guarantee) && a.LeftDeposit.Equal(a2.LeftDeposit)
 }
 
 func (r Remove) equal(r2 Remove) bool {
 	return bytes.Equal(r.Target.Bytes(), r2.Target.Bytes()) &&
-		types.Equal(r.LeftAmount, r2.LeftAmount)
+		r.LeftAmount.Equal(r2.LeftAmount)
 }
 
 // HandleProposal handles a proposal to add or remove a guarantee.
@@ -758,6 +845,9 @@ func (vars *Vars) HandleProposal(p Proposal) error {
 // If an error is returned, the original vars is not mutated.
 func (vars *Vars) Add(p Add) error {
 	// CHECKS
+	if !validAmounts(p.amount) || !validAmounts(p.LeftDeposit) {
+		return ErrMalformedProposal
+	}
 	o := vars.Outcome
 
 	_, found := o.guarantees[p.target]
@@ -765,16 +855,25 @@ func (vars *Vars) Add(p Add) error {
 		return ErrDuplicateGuarantee
 	}
 
-	if types.Gt(p.LeftDeposit, p.amount) {
-		return ErrInvalidDeposit
+	for asset, leftDeposit := range p.LeftDeposit {
+		if types.Gt(leftDeposit, amountOf(p.amount, asset)) {
+			return ErrInvalidDeposit
+		}
 	}
 
-	if types.Gt(p.LeftDeposit, o.leader.amount) {
-		return ErrInsufficientFunds
-	}
+	rightDeposit := p.RightDeposit()
+	for asset := range p.amount {
+		if !o.holds(asset) {
+			return fmt.Errorf("%w: ledger does not hold asset %s", ErrInsufficientFunds, asset)
+		}
+
+		if types.Gt(amountOf(p.LeftDeposit, asset), amountOf(o.leader.amount, asset)) {
+			return ErrInsufficientFunds
+		}
 
-	if types.Gt(p.RightDeposit(), o.follower.amount) {
-		return ErrInsufficientFunds
+		if types.Gt(rightDeposit[asset], amountOf(o.follower.amount, asset)) {
+			return ErrInsufficientFunds
+		}
 	}
 
 	// EFFECTS
@@ -783,12 +882,13 @@ func (vars *Vars) Add(p Add) error {
 	vars.TurnNum += 1
 
 	// Adjust balances
-	o.leader.amount.Sub(o.leader.amount, p.LeftDeposit)
-	rightDeposit := p.RightDeposit()
-	o.follower.amount.Sub(o.follower.amount, rightDeposit)
+	for asset := range p.amount {
+		o.leader.amount[asset] = big.NewInt(0).Sub(amountOf(o.leader.amount, asset), amountOf(p.LeftDeposit, asset))
+		o.follower.amount[asset] = big.NewInt(0).Sub(amountOf(o.follower.amount, asset), rightDeposit[asset])
+	}
 
 	// Include guarantee
-	o.guarantees[p.target] = p.Guarantee
+	o.guarantees[p.target] = p.Guarantee.Clone()
 
 	return nil
 }
@@ -807,6 +907,9 @@ func (vars *Vars) Add(p Add) error {
 func (vars *Vars) Remove(p Remove) error {
 	// CHECKS
 
+	if !validAmounts(p.LeftAmount) {
+		return ErrMalformedProposal
+	}
 	o := vars.Outcome
 
 	guarantee, found := o.guarantees[p.Target]
@@ -814,8 +917,10 @@ func (vars *Vars) Remove(p Remove) error {
 		return ErrGuaranteeNotFound
 	}
 
-	if p.LeftAmount.Cmp(guarantee.amount) > 0 {
-		return ErrInvalidAmount
+	for asset, leftAmount := range p.LeftAmount {
+		if leftAmount.Cmp(amountOf(guarantee.amount, asset)) > 0 {
+			return ErrInvalidAmount
+		}
 	}
 
 	// EFFECTS
@@ -823,15 +928,18 @@ func (vars *Vars) Remove(p Remove) error {
 	// Increase the turn number
 	vars.TurnNum += 1
 
-	rightAmount := big.NewInt(0).Sub(guarantee.amount, p.LeftAmount)
-
 	// Adjust balances
-	if o.leader.destination == guarantee.left {
-		o.leader.amount.Add(o.leader.amount, p.LeftAmount)
-		o.follower.amount.Add(o.follower.amount, rightAmount)
-	} else {
-		o.leader.amount.Add(o.leader.amount, rightAmount)
-		o.follower.amount.Add(o.follower.amount, p.LeftAmount)
+	for asset, amount := range guarantee.amount {
+		leftAmount := amountOf(p.LeftAmount, asset)
+		rightAmount := big.NewInt(0).Sub(amount, leftAmount)
+
+		if o.leader.destination == guarantee.left {
+			o.leader.amount[asset] = big.NewInt(0).Add(amountOf(o.leader.amount, asset), leftAmount)
+			o.follower.amount[asset] = big.NewInt(0).Add(amountOf(o.follower.amount, asset), rightAmount)
+		} else {
+			o.leader.amount[asset] = big.NewInt(0).Add(amountOf(o.leader.amount, asset), rightAmount)
+			o.follower.amount[asset] = big.NewInt(0).Add(amountOf(o.follower.amount, asset), leftAmount)
+		}
 	}
 
 	// Remove the guarantee
@@ -844,10 +952,10 @@ func (vars *Vars) Remove(p Remove) error {
 type Remove struct {
 	// Target is the address of the virtual channel being defunded
 	Target types.Destination
-	// LeftAmount is the amount to be credited (in the ledger channel) to the participant specified as the "left" in the guarantee.
+	// LeftAmount is the amount, per asset, to be credited (in the ledger channel) to the participant specified as the "left" in the guarantee.
 	//
 	// The amount for the "right" participant is calculated as the difference between the guarantee amount and LeftAmount.
-	LeftAmount *big.Int
+	LeftAmount types.Funds
 }
 
 // Clone returns a deep copy of the receiver
@@ -857,7 +965,7 @@ func (r *Remove) Clone() Remove {
 	}
 	return Remove{
 		Target:     r.Target,
-		LeftAmount: big.NewInt(0).Set(r.LeftAmount),
+		LeftAmount: r.LeftAmount.Clone(),
 	}
 }
 
@@ -45,19 +45,19 @@ func TestConsensusChannel(t *testing.T) {
 	}
 
 	mutatedG := clone1.guarantees[existingChannel]
-	mutatedG.amount.SetInt64(111)
+	mutatedG.amount[asset].SetInt64(111)
 	if f1 != fingerprint(vars) {
 		t.Fatal("vars shares data with clone")
 	}
 
 	clone2 := vars.Outcome.clone()
-	clone2.leader.amount.SetInt64(111)
+	clone2.leader.amount[asset].SetInt64(111)
 	if f1 != fingerprint(vars) {
 		t.Fatal("vars shares data with clone")
 	}
 
 	clone3 := vars.Outcome.clone()
-	clone3.follower.amount.SetInt64(111)
+	clone3.follower.amount[asset].SetInt64(111)
 	if f1 != fingerprint(vars) {
 		t.Fatal("vars shares data with clone")
 	}
@@ -98,8 +98,8 @@ func TestConsensusChannel(t *testing.T) {
 		// Proposing a change that depletes a balance should fail
 		vars = Vars{TurnNum: startingTurnNum, Outcome: outcome()}
 		largeProposal := proposal
-		leftAmount := big.NewInt(0).Set(vars.Outcome.leader.amount)
-		largeProposal.amount = leftAmount.Add(leftAmount, big.NewInt(1))
+		leftAmount := vars.Outcome.leader.Amount(asset)
+		largeProposal.amount = types.Funds{asset: leftAmount.Add(leftAmount, big.NewInt(1))}
 		largeProposal.LeftDeposit = largeProposal.amount
 		err = vars.Add(largeProposal)
 
@@ -146,7 +146,7 @@ func TestConsensusChannel(t *testing.T) {
 		vars = Vars{TurnNum: startingTurnNum, Outcome: outcome()}
 		largeProposal := Remove{
 			Target:     existingChannel,
-			LeftAmount: big.NewInt(10),
+			LeftAmount: funds(10),
 		}
 		err = vars.Remove(largeProposal)
 		if !errors.Is(err, ErrInvalidAmount) {
@@ -210,3 +210,103 @@ func TestConsensusChannel(t *testing.T) {
 	t.Run(`TestApplyingRemoveProposalToVars`, testApplyingRemoveProposalToVars)
 	t.Run(`TestConsensusChannelFunctionality`, testConsensusChannelFunctionality)
 }
+
+func TestMultiAssetLedgerOutcome(t *testing.T) {
+	token := types.Address{'t'}
+	assets := []types.Address{asset, token}
+
+	multiAssetFunds := func(a, b uint64) types.Funds {
+		return types.Funds{asset: big.NewInt(int64(a)), token: big.NewInt(int64(b))}
+	}
+
+	outcome := func() LedgerOutcome {
+		return *NewLedgerOutcome(
+			assets,
+			NewBalance(alice.Destination(), multiAssetFunds(aBal, 20)),
+			NewBalance(bob.Destination(), multiAssetFunds(bBal, 30)),
+			[]Guarantee{NewGuarantee(multiAssetFunds(vAmount, 2), channel1Id, alice.Destination(), bob.Destination())},
+		)
+	}
+
+	testExitRoundTrip := func(t *testing.T) {
+		o := outcome()
+		exit := o.AsOutcome()
+
+		if len(exit) != 2 {
+			t.Fatalf("expected an exit with 2 assets, got %d", len(exit))
+		}
+		for i, sae := range exit {
+			if sae.Asset != assets[i] {
+				t.Fatalf("expected asset %s at index %d, got %s", assets[i], i, sae.Asset)
+			}
+			if len(sae.Allocations) != 3 {
+				t.Fatalf("expected leader, follower and guarantee allocations for asset %s, got %d allocations", sae.Asset, len(sae.Allocations))
+			}
+		}
+
+		recovered, err := FromExit(exit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !(&Vars{Outcome: recovered}).equals(Vars{Outcome: o}) {
+			t.Fatalf("expected FromExit to recover the original outcome")
+		}
+
+		mismatched := o.AsOutcome()
+		mismatched[1].Allocations[0].Destination = brian.Destination()
+		if _, err := FromExit(mismatched); err == nil {
+			t.Fatalf("expected an error when the leader differs between assets")
+		}
+
+		for _, n := range []int{0, 1} {
+			truncated := o.AsOutcome()
+			truncated[0].Allocations = truncated[0].Allocations[:n]
+			if _, err := FromExit(truncated); err == nil {
+				t.Fatalf("expected an error when the first asset has %d allocations", n)
+			}
+		}
+	}
+
+	testAddAndRemove := func(t *testing.T) {
+		vars := Vars{TurnNum: 0, Outcome: outcome()}
+
+		g := NewGuarantee(multiAssetFunds(vAmount, 4), targetChannel, alice.Destination(), bob.Destination())
+		if err := vars.Add(NewAdd(g, multiAssetFunds(vAmount, 1))); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := vars.Outcome.Leader().Amount(token); got.Cmp(big.NewInt(19)) != 0 {
+			t.Errorf("expected leader to hold 19 of %s, got %s", token, got)
+		}
+		if got := vars.Outcome.Follower().Amount(token); got.Cmp(big.NewInt(27)) != 0 {
+			t.Errorf("expected follower to hold 27 of %s, got %s", token, got)
+		}
+		if got := vars.Outcome.Leader().Amount(asset); got.Cmp(big.NewInt(int64(aBal-vAmount))) != 0 {
+			t.Errorf("expected leader to hold %d of %s, got %s", aBal-vAmount, asset, got)
+		}
+
+		if err := vars.Remove(NewRemove(targetChannel, multiAssetFunds(0, 4))); err != nil {
+			t.Fatal(err)
+		}
+
+		if got := vars.Outcome.Leader().Amount(token); got.Cmp(big.NewInt(23)) != 0 {
+			t.Errorf("expected leader to hold 23 of %s, got %s", token, got)
+		}
+		if got := vars.Outcome.Follower().Amount(asset); got.Cmp(big.NewInt(int64(bBal+vAmount))) != 0 {
+			t.Errorf("expected follower to hold %d of %s, got %s", bBal+vAmount, asset, got)
+		}
+
+		unheld := NewGuarantee(types.Funds{types.Address{'u'}: big.NewInt(1)}, targetChannel, alice.Destination(), bob.Destination())
+		if err := vars.Add(NewAdd(unheld, types.Funds{})); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("expected %v when guaranteeing an asset the ledger does not hold, got %v", ErrInsufficientFunds, err)
+		}
+
+		tooLarge := NewGuarantee(multiAssetFunds(1, 60), targetChannel, alice.Destination(), bob.Destination())
+		if err := vars.Add(NewAdd(tooLarge, multiAssetFunds(1, 0))); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("expected %v when the follower cannot afford one of the assets, got %v", ErrInsufficientFunds, err)
+		}
+	}
+
+	t.Run(`TestExitRoundTrip`, testExitRoundTrip)
+	t.Run(`TestAddAndRemove`, testAddAndRemove)
+}
@@ -3,6 +3,7 @@ package consensus_channel
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"path/filepath"
 	"reflect"
 	"runtime"
@@ -180,6 +181,33 @@ func TestFollowerIncorrectlyAddressedProposals(t *testing.T) {
 	}
 }
 
+func TestFollowerRejectsMalformedProposals(t *testing.T) {
+	initialVars := Vars{Outcome: ledgerOutcome(), TurnNum: 0}
+	aliceSig, _ := initialVars.AsState(fp()).Sign(alice.PrivateKey)
+	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
+	sigs := [2]state.Signature{aliceSig, bobsSig}
+
+	followerCh, _ := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs)
+
+	missingDeposit := Proposal{LedgerID: followerCh.Id, ToAdd: add(1, targetChannel, alice, bob)}
+	missingDeposit.ToAdd.LeftDeposit = types.Funds{asset: nil}
+	missingAmount := Proposal{LedgerID: followerCh.Id, ToRemove: remove(channel1Id, 2)}
+	missingAmount.ToRemove.LeftAmount = types.Funds{asset: nil}
+	negativeAmount := Proposal{LedgerID: followerCh.Id, ToRemove: remove(channel1Id, 2)}
+	negativeAmount.ToRemove.LeftAmount = types.Funds{asset: big.NewInt(-1)}
+
+	for _, p := range []Proposal{missingDeposit, missingAmount, negativeAmount} {
+		err := followerCh.Receive(SignedProposal{aliceSig, p, 1})
+		if !errors.Is(err, ErrMalformedProposal) {
+			t.Errorf("expected %v receiving %+v, but got %v", ErrMalformedProposal, p, err)
+		}
+		vars := Vars{Outcome: ledgerOutcome(), TurnNum: 0}
+		if err := vars.HandleProposal(p); !errors.Is(err, ErrMalformedProposal) {
+			t.Errorf("expected %v handling %+v, but got %v", ErrMalformedProposal, p, err)
+		}
+	}
+}
+
 // equals fails the test if exp is not equal to act.
 func equals(tb testing.TB, exp, act interface{}) {
 	if !reflect.DeepEqual(exp, act) {
@@ -29,14 +29,22 @@ func fp() state.FixedPart {
 	}
 }
 
+// asset is the asset held by the ledgers in these tests, unless otherwise specified
+var asset = types.Address{}
+
+// funds returns a Funds object holding the given amount of the test asset
+func funds(amount uint64) types.Funds {
+	return types.Funds{asset: big.NewInt(int64(amount))}
+}
+
 func allocation(d testactors.Actor, a uint64) Balance {
-	return Balance{destination: d.Destination(), amount: big.NewInt(int64(a))}
+	return Balance{destination: d.Destination(), amount: funds(a)}
 }
 
 func guarantee(amount uint64, target types.Destination, left, right testactors.Actor) Guarantee {
 	return Guarantee{
 		target: target,
-		amount: big.NewInt(int64(amount)),
+		amount: funds(amount),
 		left:   left.Destination(),
 		right:  right.Destination(),
 	}
@@ -47,7 +55,7 @@ func makeOutcome(leader, follower Balance, guarantees ...Guarantee) LedgerOutcom
 	for _, g := range guarantees {
 		mappedGuarantees[g.target] = g
 	}
-	return LedgerOutcome{leader: leader, follower: follower, guarantees: mappedGuarantees}
+	return LedgerOutcome{assets: []types.Address{asset}, leader: leader, follower: follower, guarantees: mappedGuarantees}
 }
 
 // ledgerOutcome constructs the LedgerOutcome with items
@@ -63,22 +71,21 @@ func ledgerOutcome() LedgerOutcome {
 }
 
 func add(amount uint64, vId types.Destination, left, right testactors.Actor) Add {
-	bigAmount := big.NewInt(int64(amount))
 	return Add{
 		Guarantee: Guarantee{
-			amount: bigAmount,
+			amount: funds(amount),
 			target: vId,
 			left:   left.Destination(),
 			right:  right.Destination(),
 		},
-		LeftDeposit: bigAmount,
+		LeftDeposit: funds(amount),
 	}
 }
 
 func remove(vId types.Destination, leftAmount uint64) Remove {
 	return Remove{
 		Target:     vId,
-		LeftAmount: big.NewInt(int64(leftAmount)),
+		LeftAmount: funds(leftAmount),
 	}
 }
 
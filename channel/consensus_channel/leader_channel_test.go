@@ -93,14 +93,14 @@ func TestLeaderChannel(t *testing.T) {
 		return NewAddProposal(
 			chID,
 			guarantee(amountAdded, target, alice, bob),
-			big.NewInt(int64(amountAdded)),
+			funds(amountAdded),
 		)
 	}
 	createRemove := func(chID types.Destination, target types.Destination) Proposal {
 		return NewRemoveProposal(
 			chID,
 			target,
-			big.NewInt(int64(aAmount)),
+			funds(aAmount),
 		)
 	}
 
@@ -259,7 +259,7 @@ func TestLeaderChannel(t *testing.T) {
 		c := testChannel(startingOutcome, emptyQueue())
 
 		// LeftAmount > amountAdded
-		newRemove := NewRemoveProposal(cId, channel1Id, big.NewInt(int64(amountAdded+1)))
+		newRemove := NewRemoveProposal(cId, channel1Id, funds(amountAdded+1))
 
 		t.Run(msg, testPropose(c, newRemove, SignedProposal{}, ErrInvalidAmount))
 	}
@@ -3,7 +3,6 @@ package consensus_channel
 import (
 	"encoding/json"
 	"fmt"
-	"math/big"
 
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/types"
@@ -14,7 +13,7 @@ import (
 // embedded structs are moved to name fields for easier serialization
 type jsonAdd struct {
 	Guarantee   Guarantee
-	LeftDeposit *big.Int
+	LeftDeposit types.Funds
 }
 
 // MarshalJSON returns a JSON representation of the Add
@@ -45,7 +44,7 @@ func (a *Add) UnmarshalJSON(data []byte) error {
 // embedded structs are moved to name fields for easier serialization
 type jsonRemove struct {
 	Target     types.Destination
-	LeftAmount *big.Int
+	LeftAmount types.Funds
 }
 
 // MarshalJSON returns a JSON representation of the Remove
@@ -105,7 +104,7 @@ func (p *Proposal) UnmarshalJSON(data []byte) error {
 // making it suitable for serialization
 type jsonBalance struct {
 	Destination types.Destination
-	Amount      *big.Int
+	Amount      types.Funds
 }
 
 // MarshalJSON returns a JSON representation of the Balance
@@ -134,7 +133,7 @@ func (b *Balance) UnmarshalJSON(data []byte) error {
 // jsonGuarantee replaces Guarantee's private fields with public ones,
 // making it suitable for serialization
 type jsonGuarantee struct {
-	Amount *big.Int
+	Amount types.Funds
 	Target types.Destination
 	Left   types.Destination
 	Right  types.Destination
@@ -168,19 +167,19 @@ func (g *Guarantee) UnmarshalJSON(data []byte) error {
 // jsonLedgerOutcome replaces LedgerOutcome's private fields with public ones,
 // making it suitable for serialization
 type jsonLedgerOutcome struct {
-	AssetAddress types.Address // Address of the asset type
-	Leader       Balance       // Balance of participants[0]
-	Follower     Balance       // Balance of participants[1]
-	Guarantees   map[types.Destination]Guarantee
+	Assets     []types.Address // Addresses of the asset types
+	Leader     Balance         // Balance of participants[0]
+	Follower   Balance         // Balance of participants[1]
+	Guarantees map[types.Destination]Guarantee
 }
 
 // MarshalJSON returns a JSON representation of the LedgerOutcome
 func (l LedgerOutcome) MarshalJSON() ([]byte, error) {
 	jsonLo := jsonLedgerOutcome{
-		AssetAddress: l.assetAddress,
-		Leader:       l.leader,
-		Follower:     l.follower,
-		Guarantees:   l.guarantees,
+		Assets:     l.assets,
+		Leader:     l.leader,
+		Follower:   l.follower,
+		Guarantees: l.guarantees,
 	}
 	return json.Marshal(jsonLo)
 }
@@ -194,7 +193,7 @@ func (l *LedgerOutcome) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("error unmarshaling ledger outcome data: %w", err)
 	}
 
-	l.assetAddress = jsonLo.AssetAddress
+	l.assets = jsonLo.Assets
 	l.leader = jsonLo.Leader
 	l.follower = jsonLo.Follower
 	l.guarantees = jsonLo.Guarantees
@@ -11,9 +11,11 @@ import (
 
 type AllocationType uint8
 
+// The values match the AllocationType enum of the exit format used by the adjudicator.
 const (
-	NormalAllocationType AllocationType = iota
-	GuaranteeAllocationType
+	NormalAllocationType         AllocationType = 0
+	WithdrawHelperAllocationType AllocationType = 1
+	GuaranteeAllocationType      AllocationType = 2
 )
 
 // Allocation declares an Amount to be paid to a Destination.
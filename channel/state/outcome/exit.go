@@ -96,6 +96,19 @@ func (e Exit) TotalAllocatedFor(dest types.Destination) types.Funds {
 	return total
 }
 
+// Validate returns an error if any allocation in the exit has a missing or negative amount.
+// Exits received from peers should be validated before they are hashed or inspected.
+func (e Exit) Validate() error {
+	for _, sae := range e {
+		for i, a := range sae.Allocations {
+			if a.Amount == nil || a.Amount.Sign() < 0 {
+				return fmt.Errorf("allocation %d of asset %s has an invalid amount %v", i, sae.Asset, a.Amount)
+			}
+		}
+	}
+	return nil
+}
+
 // ExitTy describes the shape of Exit such that github.com/ethereum/go-ethereum/accounts/abi can parse it
 var ExitTy, _ = abi.NewType("tuple[]", "struct ExitFormat.SingleAssetExit[]", []abi.ArgumentMarshaling{
 	{Name: "asset", Type: "address"},
@@ -248,8 +248,8 @@ func TestExitDivertToGuarantee(t *testing.T) {
 				{
 					Destination:    targetChannel,
 					Amount:         big.NewInt(10),
-					AllocationType: 1,
-					Metadata:       append(aliceDestination.Bytes(), bobDestination.Bytes()...),
+					AllocationType: GuaranteeAllocationType,
+					Metadata:       encodedGuaranteeMetadata,
 				},
 			},
 		},
@@ -288,8 +288,8 @@ func TestExitDivertToGuarantee(t *testing.T) {
 				{
 					Destination:    targetChannel,
 					Amount:         big.NewInt(5),
-					AllocationType: 1,
-					Metadata:       append(aliceDestination.Bytes(), bobDestination.Bytes()...),
+					AllocationType: GuaranteeAllocationType,
+					Metadata:       encodedGuaranteeMetadata,
 				},
 			},
 		},
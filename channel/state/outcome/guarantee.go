@@ -1,6 +1,8 @@
 package outcome
 
 import (
+	"fmt"
+
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -13,28 +15,13 @@ type GuaranteeMetadata struct {
 	Right types.Destination
 }
 
-// guaranteeMetadataTy describes the shape of GuaranteeMetadata, so that the abi encoder knows how to encode it
-var guaranteeMetadataTy, _ = abi.NewType("tuple", "struct GuaranteeMetadata", []abi.ArgumentMarshaling{
-	{Name: "Left", Type: "bytes32"},
-	{Name: "Right", Type: "bytes32"},
-})
+// guaranteeMetadataTy describes the shape of encoded GuaranteeMetadata, so that the abi encoder knows how to encode it.
+// The adjudicator decodes guarantee metadata as a list of destinations, in order of priority.
+var guaranteeMetadataTy, _ = abi.NewType("bytes32[]", "", nil)
 
 // Encode returns the abi.encoded GuaranteeMetadata (suitable for packing in an Allocation.Metadata field)
 func (m GuaranteeMetadata) Encode() (types.Bytes, error) {
-	return abi.Arguments{{Type: guaranteeMetadataTy}}.Pack(m)
-}
-
-// rawGuaranteeMetadataType is an alias to the type returned when using the github.com/ethereum/go-ethereum/accounts/abi Unpack method with guaranteeMetadataTy
-type rawGuaranteeMetadataType = struct {
-	Left  [32]uint8 "json:\"Left\""
-	Right [32]uint8 "json:\"Right\""
-}
-
-// convertToGuaranteeMetadata converts a rawGuaranteeMetadataType to a GuaranteeMetadata
-func convertToGuaranteeMetadata(r rawGuaranteeMetadataType) GuaranteeMetadata {
-
-	guaranteeMetadata := GuaranteeMetadata{Left: types.Destination(r.Left), Right: types.Destination(r.Right)}
-	return guaranteeMetadata
+	return abi.Arguments{{Type: guaranteeMetadataTy}}.Pack([][32]byte{m.Left, m.Right})
 }
 
 // Decode returns a GuaranteeMetaData from an abi encoding
@@ -43,5 +30,9 @@ func DecodeIntoGuaranteeMetadata(m []byte) (GuaranteeMetadata, error) {
 	if err != nil {
 		return GuaranteeMetadata{}, err
 	}
-	return convertToGuaranteeMetadata(unpacked[0].(rawGuaranteeMetadataType)), nil
+	destinations := unpacked[0].([][32]byte)
+	if len(destinations) != 2 {
+		return GuaranteeMetadata{}, fmt.Errorf("expected 2 guarantee destinations, got %d", len(destinations))
+	}
+	return GuaranteeMetadata{Left: destinations[0], Right: destinations[1]}, nil
 }
@@ -10,7 +10,10 @@ import (
 )
 
 var guaranteeMetadata = GuaranteeMetadata{Left: types.AddressToDestination(common.HexToAddress("0x0a")), Right: types.AddressToDestination(common.HexToAddress("0x0b"))}
-var encodedGuaranteeMetadata, _ = hex.DecodeString("000000000000000000000000000000000000000000000000000000000000000a000000000000000000000000000000000000000000000000000000000000000b")
+var encodedGuaranteeMetadata, _ = hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000020" +
+	"0000000000000000000000000000000000000000000000000000000000000002" +
+	"000000000000000000000000000000000000000000000000000000000000000a" +
+	"000000000000000000000000000000000000000000000000000000000000000b")
 
 func TestGuaranteeMetadataEncode(t *testing.T) {
 	encodedG, err := guaranteeMetadata.Encode()
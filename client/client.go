@@ -9,7 +9,9 @@ import (
 	"github.com/statechannels/go-nitro/client/engine/chainservice"
 	"github.com/statechannels/go-nitro/client/engine/messageservice"
 	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
@@ -23,10 +25,14 @@ type Client struct {
 	Address             *types.Address
 	completedObjectives chan protocols.ObjectiveId
 	failedObjectives    chan protocols.ObjectiveId
+	errors              chan error
+	receivedVouchers    chan payments.Voucher
 }
 
 // New is the constructor for a Client. It accepts a messaging service, a chain service, and a store as injected dependencies.
-func New(messageService messageservice.MessageService, chainservice chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker engine.PolicyMaker, metricsApi engine.MetricsApi) Client {
+// Objectives which do not complete within the supplied timeouts are failed; if timeouts is nil, objectives never time out.
+// An error is returned if the engine cannot be constructed, for example because the chain service cannot report its chain id.
+func New(messageService messageservice.MessageService, chainservice chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker engine.PolicyMaker, metricsApi engine.MetricsApi, timeouts engine.ObjectiveTimeouts) (Client, error) {
 	c := Client{}
 	c.Address = store.GetAddress()
 	// If a metrics API is not provided we used the no-op version which does nothing.
@@ -34,9 +40,15 @@ func New(messageService messageservice.MessageService, chainservice chainservice
 		metricsApi = &engine.NoOpMetrics{}
 	}
 
-	c.engine = engine.New(messageService, chainservice, store, logDestination, policymaker, metricsApi)
+	e, err := engine.New(messageService, chainservice, store, logDestination, policymaker, metricsApi, timeouts)
+	if err != nil {
+		return Client{}, err
+	}
+	c.engine = e
 	c.completedObjectives = make(chan protocols.ObjectiveId, 100)
 	c.failedObjectives = make(chan protocols.ObjectiveId, 100)
+	c.errors = make(chan error, 100)
+	c.receivedVouchers = make(chan payments.Voucher, 100)
 
 	// Start the engine in a go routine
 	go c.engine.Run()
@@ -45,7 +57,7 @@ func New(messageService messageservice.MessageService, chainservice chainservice
 	// It will listen for events from the engine and dispatch events to client channels
 	go c.handleEngineEvents()
 
-	return c
+	return c, nil
 }
 
 // handleEngineEvents is responsible for monitoring the ToApi channel on the engine.
@@ -63,6 +75,22 @@ func (c *Client) handleEngineEvents() {
 			c.failedObjectives <- erred
 		}
 
+		for _, err := range update.Errors {
+			// Errors are dropped rather than blocking the engine if the consuming application is not reading them
+			select {
+			case c.errors <- err:
+			default:
+			}
+		}
+
+		for _, voucher := range update.ReceivedVouchers {
+			// As with errors, vouchers are dropped rather than blocking the engine. Each voucher is for the total paid so far, so a later voucher supersedes a dropped one
+			select {
+			case c.receivedVouchers <- voucher:
+			default:
+			}
+		}
+
 	}
 }
 
@@ -73,13 +101,31 @@ func (c *Client) CompletedObjectives() <-chan protocols.ObjectiveId {
 	return c.completedObjectives
 }
 
-// FailedObjectives returns a chan that receives an objective id whenever that objective has failed
+// FailedObjectives returns a chan that receives an objective id whenever that objective has failed.
+// The reason for the failure is reported on the Errors chan as an *engine.ObjectiveError.
 func (c *Client) FailedObjectives() <-chan protocols.ObjectiveId {
 	return c.failedObjectives
 }
 
+// Errors returns a chan that receives errors encountered by the client.
+// Errors concerning a particular objective are of type *engine.ObjectiveError; errors sending messages or transactions
+// are of type *messageservice.SendError or *chainservice.TransactionError respectively.
+// Reading from the chan is optional: errors which arrive while the chan's buffer is full are dropped.
+func (c *Client) Errors() <-chan error {
+	return c.errors
+}
+
+// ReceivedVouchers returns a chan that receives a voucher every time a valid payment is received on a virtual channel.
+// The voucher's Amount is the total paid on the channel so far.
+// Reading from the chan is optional: vouchers which arrive while the chan's buffer is full are dropped.
+func (c *Client) ReceivedVouchers() <-chan payments.Voucher {
+	return c.receivedVouchers
+}
+
 // CreateVirtualChannel creates a virtual channel with the counterParty using ledger channels with the intermediary.
+// The request's ChainId is overwritten with the chain id of the client's chain service.
 func (c *Client) CreateVirtualChannel(objectiveRequest virtualfund.ObjectiveRequest) virtualfund.ObjectiveResponse {
+	objectiveRequest.ChainId = c.engine.GetChainId()
 
 	apiEvent := engine.APIEvent{
 		ObjectiveToSpawn: objectiveRequest,
@@ -91,11 +137,12 @@ func (c *Client) CreateVirtualChannel(objectiveRequest virtualfund.ObjectiveRequ
 }
 
 // CloseVirtualChannel attempts to close and defund the given virtually funded channel.
-func (c *Client) CloseVirtualChannel(channelId types.Destination, paidToBob *big.Int) protocols.ObjectiveId {
+// The final outcome pays the payee the total of the payments made on the channel, as recorded by the calling client.
+// An intermediary does not know what has been paid, so its objective waits for the payer or payee to propose the final state, and then agrees to it.
+func (c *Client) CloseVirtualChannel(channelId types.Destination) protocols.ObjectiveId {
 
 	objectiveRequest := virtualdefund.ObjectiveRequest{
 		ChannelId: channelId,
-		PaidToBob: paidToBob,
 	}
 	apiEvent := engine.APIEvent{
 		ObjectiveToSpawn: objectiveRequest,
@@ -107,8 +154,30 @@ func (c *Client) CloseVirtualChannel(channelId types.Destination, paidToBob *big
 
 }
 
-// CreateDirectChannel creates a directly funded channel with the given counterparty
+// Pay sends a voucher paying the given amount to the payee of the given virtual channel.
+// Failures to pay are reported on the Errors chan.
+func (c *Client) Pay(channelId types.Destination, amount *big.Int) {
+	apiEvent := engine.APIEvent{
+		PaymentToMake: &engine.PaymentRequest{ChannelId: channelId, Amount: amount},
+	}
+	// Send the event to the engine
+	c.engine.FromAPI <- apiEvent
+}
+
+// GuardChannel hands the latest supported state of the given channel to a watchtower, which checkpoints it if the channel is challenged with an older state.
+// GuardChannel should be called again whenever the channel is updated. Failures are reported on the Errors chan.
+func (c *Client) GuardChannel(channelId types.Destination, watchtower types.Address) {
+	apiEvent := engine.APIEvent{
+		ChannelToGuard: &engine.GuardRequest{ChannelId: channelId, Watchtower: watchtower},
+	}
+	// Send the event to the engine
+	c.engine.FromAPI <- apiEvent
+}
+
+// CreateDirectChannel creates a directly funded channel with the given counterparty.
+// The request's ChainId is overwritten with the chain id of the client's chain service.
 func (c *Client) CreateDirectChannel(objectiveRequest directfund.ObjectiveRequest) directfund.ObjectiveResponse {
+	objectiveRequest.ChainId = c.engine.GetChainId()
 
 	// This next line overwrites the requested application address with the consensus app address.
 	// TODO remove this behaviour
@@ -139,3 +208,20 @@ func (c *Client) CloseDirectChannel(channelId types.Destination) protocols.Objec
 	return objectiveRequest.Id(*c.Address)
 
 }
+
+// ChallengeChannel attempts to recover the funds in the given directly funded channel without the cooperation of the counterparty,
+// by registering a challenge on chain, waiting for it to finalize and then transferring the channel's assets.
+// Any objective already in progress on the channel is failed.
+func (c *Client) ChallengeChannel(channelId types.Destination) protocols.ObjectiveId {
+
+	objectiveRequest := challenge.ObjectiveRequest{
+		ChannelId: channelId,
+	}
+	apiEvent := engine.APIEvent{
+		ObjectiveToSpawn: objectiveRequest,
+	}
+	// Send the event to the engine
+	c.engine.FromAPI <- apiEvent
+
+	return objectiveRequest.Id(*c.Address)
+}
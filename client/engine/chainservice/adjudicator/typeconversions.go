@@ -13,11 +13,12 @@ func ConvertVariablePart(vp state.VariablePart) INitroTypesVariablePart {
 		AppData: vp.AppData,
 		TurnNum: big.NewInt(int64(vp.TurnNum)),
 		IsFinal: vp.IsFinal,
-		Outcome: convertOutcome(vp.Outcome),
+		Outcome: ConvertOutcome(vp.Outcome),
 	}
 }
 
-func convertOutcome(o outcome.Exit) []ExitFormatSingleAssetExit {
+// ConvertOutcome converts an outcome to the form expected by the adjudicator.
+func ConvertOutcome(o outcome.Exit) []ExitFormatSingleAssetExit {
 	e := make([]ExitFormatSingleAssetExit, len(o))
 	for i, sae := range o {
 		e[i].Asset = sae.Asset
@@ -2,6 +2,7 @@
 package chainservice // import "github.com/statechannels/go-nitro/client/chainservice"
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -14,18 +15,39 @@ import (
 // Event dictates which methods all chain events must implement
 type Event interface {
 	ChannelID() types.Destination
+	BlockNumber() uint64
+	Sequence() uint64
+
+	withSequence(sequence uint64) Event
 }
 
 // CommonEvent declares fields shared by all chain events
 type CommonEvent struct {
 	channelID types.Destination
 	BlockNum  uint64
+	sequence  uint64
+}
+
+// NewCommonEvent returns a CommonEvent for the channel, occurring in the given block.
+func NewCommonEvent(channelId types.Destination, blockNum uint64) CommonEvent {
+	return CommonEvent{channelID: channelId, BlockNum: blockNum}
 }
 
 func (ce CommonEvent) ChannelID() types.Destination {
 	return ce.channelID
 }
 
+// Sequence returns the position of the event among all the events emitted by the chain service, starting from 1.
+// Sequence numbers are assigned as events are emitted, so a subscriber can use them to discard events it has already received (for example after a replay).
+func (ce CommonEvent) Sequence() uint64 {
+	return ce.sequence
+}
+
+// BlockNumber returns the number of the block in which the event occurred.
+func (ce CommonEvent) BlockNumber() uint64 {
+	return ce.BlockNum
+}
+
 // DepositedEvent is an internal representation of the deposited blockchain event
 type DepositedEvent struct {
 	CommonEvent
@@ -47,63 +69,267 @@ type ConcludedEvent struct {
 	CommonEvent
 }
 
-// todo implement other event types
-// ChallengeRegistered
-// ChallengeCleared
+// ChallengeRegisteredEvent is an internal representation of the ChallengeRegistered blockchain event.
+// FinalizesAt is the chain timestamp (in seconds) at which the challenge expires, unless it is cleared first.
+type ChallengeRegisteredEvent struct {
+	CommonEvent
+	TurnNumRecord uint64
+	FinalizesAt   uint64
+	IsFinal       bool
+}
+
+// ChallengeClearedEvent is an internal representation of the ChallengeCleared blockchain event
+type ChallengeClearedEvent struct {
+	CommonEvent
+	NewTurnNumRecord uint64
+}
+
+// ChallengeFinalizedEvent signals that a registered challenge has expired without being cleared, so that the channel's outcome is final.
+// The adjudicator does not emit an event when this happens: instead the chain service emits one once the time of the latest confirmed block passes FinalizesAt,
+// and stamps it with that block's number.
+type ChallengeFinalizedEvent struct {
+	CommonEvent
+	FinalizesAt uint64
+}
+
+// EventRevertedEvent signals that an event already emitted by the chain service has been removed from the chain by a reorg.
+// Subscribers should undo any effect the reverted event had on their view of the chain.
+type EventRevertedEvent struct {
+	CommonEvent
+	Reverted Event
+}
+
+// TransactionFailedEvent signals that a transaction submitted for the channel was reverted, or was never mined.
+// The adjudicator does not emit an event when this happens: instead the chain service emits one once it learns of the failure.
+type TransactionFailedEvent struct {
+	CommonEvent
+	TxHash common.Hash
+	Err    error
+}
+
+func (e DepositedEvent) withSequence(sequence uint64) Event {
+	e.sequence = sequence
+	return e
+}
+
+func (e AllocationUpdatedEvent) withSequence(sequence uint64) Event {
+	e.sequence = sequence
+	return e
+}
+
+func (e ConcludedEvent) withSequence(sequence uint64) Event {
+	e.sequence = sequence
+	return e
+}
+
+func (e ChallengeRegisteredEvent) withSequence(sequence uint64) Event {
+	e.sequence = sequence
+	return e
+}
+
+func (e ChallengeClearedEvent) withSequence(sequence uint64) Event {
+	e.sequence = sequence
+	return e
+}
+
+func (e ChallengeFinalizedEvent) withSequence(sequence uint64) Event {
+	e.sequence = sequence
+	return e
+}
+
+func (e EventRevertedEvent) withSequence(sequence uint64) Event {
+	e.sequence = sequence
+	return e
+}
+
+func (e TransactionFailedEvent) withSequence(sequence uint64) Event {
+	e.sequence = sequence
+	return e
+}
 
 // ChainEventHandler describes an objective that can handle chain events
 type ChainEventHandler interface {
 	UpdateWithChainEvent(event Event) (protocols.Objective, error)
 }
 
+// ChallengeHandler describes an objective which responds to challenges registered against its channel itself
+// (for example by challenging again with a newer state), so that the engine must not respond to them on its behalf.
+type ChallengeHandler interface {
+	ChainEventHandler
+	// HandlesChallenges returns true if the objective responds to challenges registered with stale states.
+	HandlesChallenges() bool
+}
+
 type ChainService interface {
 	// EventFeed returns a chan for receiving events from the chain service. An error is returned if no subscription exists
 	EventFeed(types.Address) (<-chan Event, error)
-	// SubscribeToEvents creates and returs a subscription channel.
+	// SubscribeToEvents creates and returs a subscription channel. Events are queued until they are received, so none are dropped.
 	SubscribeToEvents(types.Address) <-chan Event
-	// SendTransaction is for sending transactions with the chain service
-	SendTransaction(protocols.ChainTransaction)
+	// ReplayEvents resends the subscriber the events emitted since (and including) the given block, for the channels it monitors.
+	// Implementations backed by a real chain also send events which occurred before the chain service was started.
+	ReplayEvents(subscriber types.Address, fromBlock uint64)
+	// GetHoldings returns the amount of each supplied asset currently held for the channel, along with the number of the block the holdings were read at.
+	GetHoldings(channelId types.Destination, assets []common.Address) (types.Funds, uint64, error)
+	// GetAdjudicationStatus returns the channel's current on chain adjudication status.
+	GetAdjudicationStatus(channelId types.Destination) (protocols.AdjudicationStatus, error)
+	// RequestHoldings emits a DepositedEvent reporting the amount of each supplied asset currently held for the channel.
+	// The event's AmountDeposited is zero, since it does not correspond to a deposit.
+	RequestHoldings(channelId types.Destination, assets []common.Address) error
+	// Monitor registers the subscriber's interest in the channels. A subscriber only receives events for the channels it monitors.
+	// Monitoring many channels in one call is cheaper than monitoring them one at a time.
+	Monitor(subscriber types.Address, channelIds ...types.Destination)
+	// Unmonitor cancels the subscriber's interest in the channel.
+	Unmonitor(subscriber types.Address, channelId types.Destination)
+	// SendTransaction is for sending transactions with the chain service. A *TransactionError is returned if the transaction could not be sent.
+	// If the transaction is sent, but later fails, a TransactionFailedEvent is emitted for the channel.
+	SendTransaction(protocols.ChainTransaction) error
 	// GetConsensusAppAddress returns the address of a deployed ConsensusApp (for ledger channels)
 	GetConsensusAppAddress() types.Address
+	// ChainID returns the id of the chain the chain service submits transactions to. Only states with this chain id can be enforced on chain.
+	ChainID() (*big.Int, error)
+}
+
+// ErrUnexpectedTransaction is returned when a chain service is asked to send a transaction of a type it does not support.
+var ErrUnexpectedTransaction = errors.New("unexpected chain transaction")
+
+// TransactionError is returned when a chain service fails to send a transaction for a channel.
+type TransactionError struct {
+	ChannelId types.Destination
+	Err       error
+}
+
+func (te *TransactionError) Error() string {
+	return fmt.Sprintf("could not send transaction for channel %s: %v", te.ChannelId, te.Err)
+}
+
+func (te *TransactionError) Unwrap() error {
+	return te.Err
+}
+
+// TokenTransferError is wrapped in a *TransactionError when an ERC20 deposit fails because the token refuses to transfer
+// the deposit to the adjudicator, for example because the depositor's balance is too low.
+type TokenTransferError struct {
+	Token  common.Address
+	Amount *big.Int
+	Err    error
+}
+
+func (tte *TokenTransferError) Error() string {
+	return fmt.Sprintf("could not transfer %s of token %s: %v", tte.Amount, tte.Token, tte.Err)
+}
+
+func (tte *TokenTransferError) Unwrap() error {
+	return tte.Err
 }
 
 type ChainServiceBase struct {
-	out safesync.Map[chan Event]
+	out safesync.Map[*subscription]
+	// monitored records the channels each subscriber is interested in. It is keyed by subscriber address and channel id (see monitorKey).
+	monitored safesync.Map[types.Destination]
+	// history records the events emitted in recent blocks, so that they can be replayed to subscribers
+	history *eventHistory
 }
 
 // newChainServiceBase constructs a ChainServiceBase. Only implementations of ChainService interface should call the constructor.
 func newChainServiceBase() ChainServiceBase {
-	return ChainServiceBase{out: safesync.Map[chan Event]{}}
+	return ChainServiceBase{out: safesync.Map[*subscription]{}, monitored: safesync.Map[types.Destination]{}, history: newEventHistory()}
 }
 
 // Subscribe inserts a go chan (for the supplied address) into the ChainService.
 func (csb *ChainServiceBase) SubscribeToEvents(a types.Address) <-chan Event {
-	// Use a buffered channel so we don't have to worry about blocking on writing to the channel.
-	c := make(chan Event, 10)
-	csb.out.Store(a.String(), c)
-	return c
+	sub := newSubscription()
+	csb.out.Store(a.String(), sub)
+	return sub.out
 }
 
 // EventFeed returns the out chan for a particular ChainService, and narrows the type so that external consumers may only receive on it.
 func (csb *ChainServiceBase) EventFeed(a types.Address) (<-chan Event, error) {
-	c, ok := csb.out.Load(a.String())
+	sub, ok := csb.out.Load(a.String())
 	if !ok {
 		return nil, fmt.Errorf("no subscription for address %v", a)
 	}
-	return c, nil
+	return sub.out, nil
+}
+
+// ReplayEvents resends the subscriber the events emitted since (and including) the given block, for the channels it monitors.
+// Replayed events keep their sequence numbers, and are delivered before any events emitted after ReplayEvents returns.
+// Only events from the most recent blocks are kept for replay (see historyDepth).
+func (csb *ChainServiceBase) ReplayEvents(subscriber types.Address, fromBlock uint64) {
+	csb.history.mu.Lock()
+	defer csb.history.mu.Unlock()
+
+	sub, ok := csb.out.Load(subscriber.String())
+	if !ok {
+		return
+	}
+	for _, event := range csb.history.events {
+		if event.BlockNumber() >= fromBlock && csb.isMonitored(subscriber.String(), event.ChannelID()) {
+			sub.push(event)
+		}
+	}
+}
+
+// Monitor registers the subscriber's interest in the channels.
+func (csb *ChainServiceBase) Monitor(subscriber types.Address, channelIds ...types.Destination) {
+	for _, channelId := range channelIds {
+		csb.monitored.Store(monitorKey(subscriber.String(), channelId), channelId)
+	}
+}
+
+// Unmonitor cancels the subscriber's interest in the channel.
+func (csb *ChainServiceBase) Unmonitor(subscriber types.Address, channelId types.Destination) {
+	csb.monitored.Delete(monitorKey(subscriber.String(), channelId))
+}
+
+// isMonitored returns true if the subscriber (identified by its address string) monitors the channel.
+func (csb *ChainServiceBase) isMonitored(subscriber string, channelId types.Destination) bool {
+	_, ok := csb.monitored.Load(monitorKey(subscriber, channelId))
+	return ok
 }
 
-func (csb *ChainServiceBase) broadcast(event Event) {
-	csb.out.Range(func(_ string, channel chan Event) bool {
-		attemptSend(channel, event)
+// monitoredChannels returns every channel monitored by at least one subscriber.
+func (csb *ChainServiceBase) monitoredChannels() []types.Destination {
+	seen := make(map[types.Destination]bool)
+	channelIds := []types.Destination{}
+	csb.monitored.Range(func(_ string, channelId types.Destination) bool {
+		if !seen[channelId] {
+			seen[channelId] = true
+			channelIds = append(channelIds, channelId)
+		}
 		return true
 	})
+	return channelIds
 }
 
-// attemptSend sends event to the supplied chan, and drops it if the chan is full
-func attemptSend(out chan Event, event Event) {
-	select {
-	case out <- event:
-	default:
-	}
+// channelsMonitoredBy returns the ids of the channels the subscriber monitors.
+func (csb *ChainServiceBase) channelsMonitoredBy(subscriber types.Address) []types.Destination {
+	channelIds := []types.Destination{}
+	csb.monitored.Range(func(key string, channelId types.Destination) bool {
+		if key == monitorKey(subscriber.String(), channelId) {
+			channelIds = append(channelIds, channelId)
+		}
+		return true
+	})
+	return channelIds
+}
+
+func monitorKey(subscriber string, channelId types.Destination) string {
+	return subscriber + channelId.String()
+}
+
+// broadcast assigns the event the next sequence number, records it and sends it to every subscriber which monitors the event's channel.
+// It returns the event with its sequence number.
+func (csb *ChainServiceBase) broadcast(event Event) Event {
+	csb.history.mu.Lock()
+	defer csb.history.mu.Unlock()
+
+	event = csb.history.record(event)
+
+	csb.out.Range(func(subscriber string, sub *subscription) bool {
+		if csb.isMonitored(subscriber, event.ChannelID()) {
+			sub.push(event)
+		}
+		return true
+	})
+	return event
 }
@@ -1,18 +1,20 @@
 package chainservice
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
 )
 
 // getChainHolding reads on-chain holdings for a channel and an asset address given a transaction and an event generated by the transaction.
 func getChainHolding(na *NitroAdjudicator.NitroAdjudicator, tx *types.Transaction, event *NitroAdjudicator.NitroAdjudicatorAllocationUpdated) (common.Address, *big.Int, error) {
-	assetAddress, err := assetAddressForIndex(na, tx, event.AssetIndex)
+	assetAddress, err := assetAddressForIndex(na, tx, event.ChannelId, event.AssetIndex)
 	if err != nil {
 		return assetAddress, &big.Int{}, err
 	}
@@ -23,19 +25,42 @@ func getChainHolding(na *NitroAdjudicator.NitroAdjudicator, tx *types.Transactio
 	return assetAddress, amount, nil
 }
 
-// assetAddressForIndex uses the input parameters of a transaction to map an asset index to an asset address
-func assetAddressForIndex(na *NitroAdjudicator.NitroAdjudicator, tx *types.Transaction, index *big.Int) (common.Address, error) {
-	abi, err := NitroAdjudicator.NitroAdjudicatorMetaData.GetAbi()
+// assetAddressForIndex uses the input parameters of a transaction to map an asset index of the channel's outcome to an asset address
+func assetAddressForIndex(na *NitroAdjudicator.NitroAdjudicator, tx *types.Transaction, channelId [32]byte, index *big.Int) (common.Address, error) {
+	contractAbi, err := NitroAdjudicator.NitroAdjudicatorMetaData.GetAbi()
 	if err != nil {
 		return common.Address{}, err
 	}
-	params, err := decodeTxParams(abi, tx.Data())
+	params, err := decodeTxParams(contractAbi, tx.Data())
 	if err != nil {
 		return common.Address{}, err
 	}
-	// TODO remove the assumption that the tx incudes signedVariableParts parameter
-	// 	concludeAndTransferAllAssets includes this parameter, but transferAllAssets, transfer, and claim do not.
-	//  https://github.com/statechannels/go-nitro/issues/759
+	// transfer includes the encoded outcome as a parameter
+	if outcomeBytes, ok := params["outcomeBytes"]; ok {
+		return assetOfEncodedOutcome(outcomeBytes.([]byte), index)
+	}
+	// claim includes the encoded outcomes of both the source and the target channel
+	if claimArgs, ok := params["claimArgs"]; ok {
+		args := *abi.ConvertType(claimArgs, new(NitroAdjudicator.IMultiAssetHolderClaimArgs)).(*NitroAdjudicator.IMultiAssetHolderClaimArgs)
+		if channelId == args.SourceChannelId {
+			return assetOfEncodedOutcome(args.SourceOutcomeBytes, index)
+		}
+		return assetOfEncodedOutcome(args.TargetOutcomeBytes, index)
+	}
+	// transferAllAssets includes the outcome as a parameter
+	if outcome, ok := params["outcome"]; ok {
+		return outcome.([]struct {
+			Asset       common.Address "json:\"asset\""
+			Metadata    []uint8        "json:\"metadata\""
+			Allocations []struct {
+				Destination    [32]uint8 "json:\"destination\""
+				Amount         *big.Int  "json:\"amount\""
+				AllocationType uint8     "json:\"allocationType\""
+				Metadata       []uint8   "json:\"metadata\""
+			} "json:\"allocations\""
+		})[index.Int64()].Asset, nil
+	}
+	// concludeAndTransferAllAssets includes the signedVariableParts parameter
 	signedVariableParts := params["signedVariableParts"].([]struct {
 		VariablePart struct {
 			Outcome []struct {
@@ -63,6 +88,18 @@ func assetAddressForIndex(na *NitroAdjudicator.NitroAdjudicator, tx *types.Trans
 
 }
 
+// assetOfEncodedOutcome returns the asset address at the given index of an abi encoded outcome.
+func assetOfEncodedOutcome(outcomeBytes []byte, index *big.Int) (common.Address, error) {
+	decoded, err := outcome.Decode(outcomeBytes)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if !index.IsInt64() || index.Int64() >= int64(len(decoded)) {
+		return common.Address{}, fmt.Errorf("asset index %s is out of range", index)
+	}
+	return decoded[index.Int64()].Asset, nil
+}
+
 func decodeTxParams(abi *abi.ABI, data []byte) (map[string]interface{}, error) {
 	m, err := abi.MethodById(data[:4])
 	if err != nil {
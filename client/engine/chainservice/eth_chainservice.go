@@ -2,27 +2,34 @@ package chainservice
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"math/big"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/channel/state"
 	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
 	Token "github.com/statechannels/go-nitro/client/engine/chainservice/erc20"
-	"github.com/statechannels/go-nitro/client/engine/store/safesync"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
 )
 
 var allocationUpdatedTopic = crypto.Keccak256Hash([]byte("AllocationUpdated(bytes32,uint256,uint256)"))
 var concludedTopic = crypto.Keccak256Hash([]byte("Concluded(bytes32,uint48)"))
 var depositedTopic = crypto.Keccak256Hash([]byte("Deposited(bytes32,address,uint256,uint256)"))
+var challengeRegisteredTopic = crypto.Keccak256Hash([]byte("ChallengeRegistered(bytes32,uint48,uint48,bool,(uint256,address[],uint48,address,uint48),(((address,bytes,(bytes32,uint256,uint8,bytes)[])[],bytes,uint48,bool),(uint8,bytes32,bytes32)[],uint256)[])"))
+var challengeClearedTopic = crypto.Keccak256Hash([]byte("ChallengeCleared(bytes32,uint48)"))
 
 type ethChain interface {
 	bind.ContractBackend
 	ethereum.TransactionReader
+	SubscribeNewHead(ctx context.Context, ch chan<- *ethTypes.Header) (ethereum.Subscription, error)
 }
 
 type EthChainService struct {
@@ -31,148 +38,756 @@ type EthChainService struct {
 	na                  *NitroAdjudicator.NitroAdjudicator
 	naAddress           common.Address
 	consensusAppAddress common.Address
-	txSigner            *bind.TransactOpts
+	txm                 *txManager // submits transactions from the chain service's account
+	errorOut            chan error // for reporting errors encountered while listening for chain events
+	confirmations       uint64     // the number of blocks which must be mined on top of a log's block before the log is converted into an event
+
+	allowanceMu sync.Mutex                  // serializes the approval and submission of token deposits
+	reservedMu  sync.Mutex                  // guards reserved, which is released once deposits are mined
+	reserved    map[common.Address]*big.Int // the amount of each token which submitted deposits, not yet mined, will transfer out of the allowance
+
+	logs     chan ethTypes.Log     // receives adjudicator logs for the monitored channels
+	logSubMu sync.Mutex            // guards logSub
+	logSub   ethereum.Subscription // the current subscription to adjudicator logs, or nil if no channels are monitored
 }
 
 // NewEthChainService constructs a chain service that submits transactions to a NitroAdjudicator
 // and listens to events from an eventSource
 func NewEthChainService(chain ethChain, na *NitroAdjudicator.NitroAdjudicator, naAddress common.Address, caAddress common.Address, txSigner *bind.TransactOpts) *EthChainService {
+	return NewEthChainServiceWithConfirmations(chain, na, naAddress, caAddress, txSigner, 0)
+}
+
+// NewEthChainServiceWithConfirmations constructs a chain service which only emits an event once the supplied number of blocks
+// have been mined on top of the block containing it. This makes it less likely that an event is reverted by a reorg.
+func NewEthChainServiceWithConfirmations(chain ethChain, na *NitroAdjudicator.NitroAdjudicator, naAddress common.Address, caAddress common.Address, txSigner *bind.TransactOpts, confirmations uint64) *EthChainService {
 	ecs := EthChainService{ChainServiceBase: newChainServiceBase()}
-	ecs.out = safesync.Map[chan Event]{}
+	ecs.confirmations = confirmations
+	ecs.reserved = make(map[common.Address]*big.Int)
 	ecs.chain = chain
 	ecs.na = na
 	ecs.naAddress = naAddress
 	ecs.consensusAppAddress = caAddress
-	ecs.txSigner = txSigner
+	ecs.txm = newTxManager(chain, txSigner, ecs.reportTransactionFailure)
+	ecs.errorOut = make(chan error, 10)
+	ecs.logs = make(chan ethTypes.Log)
 
 	go ecs.listenForLogEvents()
 
 	return &ecs
 }
 
-// defaultTxOpts returns transaction options suitable for most transaction submissions
-func (ecs *EthChainService) defaultTxOpts() *bind.TransactOpts {
-	return &bind.TransactOpts{
-		From:     ecs.txSigner.From,
-		Nonce:    ecs.txSigner.Nonce,
-		Signer:   ecs.txSigner.Signer,
-		GasPrice: big.NewInt(10000000000),
+// Errors returns a chan for receiving errors encountered while listening for chain events.
+func (ecs *EthChainService) Errors() <-chan error {
+	return ecs.errorOut
+}
+
+// reportError sends the error to the error chan, and drops it if the chan is full
+func (ecs *EthChainService) reportError(err error) {
+	select {
+	case ecs.errorOut <- err:
+	default:
+	}
+}
+
+// Monitor registers the subscriber's interest in the channels, and subscribes to the channels' adjudicator logs.
+// Logs emitted after Monitor returns are guaranteed to be received.
+//
+// The log subscription is replaced at most once per call, so callers monitoring many channels should pass them together.
+func (ecs *EthChainService) Monitor(subscriber types.Address, channelIds ...types.Destination) {
+	unmonitored := []types.Destination{}
+	for _, channelId := range channelIds {
+		if !ecs.isMonitored(subscriber.String(), channelId) {
+			unmonitored = append(unmonitored, channelId)
+		}
+	}
+	if len(unmonitored) == 0 {
+		return
+	}
+	ecs.ChainServiceBase.Monitor(subscriber, unmonitored...)
+	ecs.updateLogSubscription()
+}
+
+// Unmonitor cancels the subscriber's interest in the channel, and unsubscribes from the channel's adjudicator logs if no other subscriber monitors it.
+func (ecs *EthChainService) Unmonitor(subscriber types.Address, channelId types.Destination) {
+	if !ecs.isMonitored(subscriber.String(), channelId) {
+		return
+	}
+	ecs.ChainServiceBase.Unmonitor(subscriber, channelId)
+	ecs.updateLogSubscription()
+}
+
+// updateLogSubscription replaces the subscription to adjudicator logs with one filtered by the (indexed) ids of the monitored channels.
+// The new subscription is made before the old one is cancelled so that no logs are missed. Logs delivered by both are discarded by listenForLogEvents.
+func (ecs *EthChainService) updateLogSubscription() {
+	ecs.logSubMu.Lock()
+	defer ecs.logSubMu.Unlock()
+
+	var sub ethereum.Subscription
+	if channelIds := ecs.monitoredChannels(); len(channelIds) > 0 {
+		var err error
+		sub, err = ecs.chain.SubscribeFilterLogs(context.Background(), ecs.logQuery(channelIds), ecs.logs)
+		if err != nil {
+			ecs.reportError(fmt.Errorf("could not subscribe to chain events: %w", err))
+			return
+		}
+		go func() {
+			// The chan is closed without an error when the subscription is cancelled
+			if err, ok := <-sub.Err(); ok {
+				ecs.reportError(fmt.Errorf("chain event subscription failed: %w", err))
+			}
+		}()
+	}
+
+	if ecs.logSub != nil {
+		ecs.logSub.Unsubscribe()
+	}
+	ecs.logSub = sub
+}
+
+// logQuery returns a query for the adjudicator logs concerning the supplied channels.
+func (ecs *EthChainService) logQuery(channelIds []types.Destination) ethereum.FilterQuery {
+	topics := make([]common.Hash, len(channelIds))
+	for i, channelId := range channelIds {
+		topics[i] = common.Hash(channelId)
+	}
+	return ethereum.FilterQuery{
+		Addresses: []common.Address{ecs.naAddress},
+		Topics:    [][]common.Hash{nil, topics}, // every event emitted by the adjudicator has the channel id as its first indexed topic
+	}
+}
+
+// ReplayEvents resends the subscriber the events emitted since (and including) the given block, for the channels it monitors.
+//
+// Events which the chain service has not emitted (for example because they occurred while the node was offline) are read from the adjudicator's logs,
+// and emitted to every subscriber which monitors their channel.
+func (ecs *EthChainService) ReplayEvents(subscriber types.Address, fromBlock uint64) {
+	ecs.ChainServiceBase.ReplayEvents(subscriber, fromBlock)
+
+	channelIds := ecs.channelsMonitoredBy(subscriber)
+	if len(channelIds) == 0 {
+		return
+	}
+	query := ecs.logQuery(channelIds)
+	query.FromBlock = new(big.Int).SetUint64(fromBlock)
+	logs, err := ecs.chain.FilterLogs(context.Background(), query)
+	if err != nil {
+		ecs.reportError(fmt.Errorf("could not read past chain events: %w", err))
+		return
+	}
+	// The listener discards the logs it has already converted into events
+	for _, l := range logs {
+		ecs.logs <- l
+	}
+}
+
+// RequestHoldings emits a DepositedEvent reporting the amount of each supplied asset held for the channel, as of the latest confirmed block.
+func (ecs *EthChainService) RequestHoldings(channelId types.Destination, assets []common.Address) error {
+	holdings, blockNum, err := ecs.GetHoldings(channelId, assets)
+	if err != nil {
+		return err
+	}
+	if blockNum == 0 {
+		return nil // nothing has been confirmed yet
+	}
+	for _, asset := range assets {
+		ecs.broadcast(DepositedEvent{
+			CommonEvent:     CommonEvent{channelID: channelId, BlockNum: blockNum},
+			Asset:           asset,
+			AmountDeposited: new(big.Int),
+			NowHeld:         holdings[asset],
+		})
+	}
+	return nil
+}
+
+// GetHoldings returns the amount of each supplied asset held for the channel as of the latest confirmed block, and the number of that block.
+func (ecs *EthChainService) GetHoldings(channelId types.Destination, assets []common.Address) (types.Funds, uint64, error) {
+	callOpts, blockNum, err := ecs.confirmedCallOpts()
+	if err != nil {
+		return nil, 0, err
+	}
+	holdings := types.Funds{}
+	for _, asset := range assets {
+		if callOpts == nil {
+			holdings[asset] = new(big.Int) // nothing has been confirmed yet
+			continue
+		}
+		held, err := ecs.na.Holdings(callOpts, asset, channelId)
+		if err != nil {
+			return nil, 0, fmt.Errorf("could not read holdings of %s for channel %s: %w", asset, channelId, err)
+		}
+		holdings[asset] = held
+	}
+	return holdings, blockNum, nil
+}
+
+// GetAdjudicationStatus returns the channel's adjudication status as of the latest confirmed block.
+func (ecs *EthChainService) GetAdjudicationStatus(channelId types.Destination) (protocols.AdjudicationStatus, error) {
+	callOpts, _, err := ecs.confirmedCallOpts()
+	if err != nil {
+		return protocols.AdjudicationStatus{}, err
+	}
+	if callOpts == nil {
+		return protocols.AdjudicationStatus{}, nil // nothing has been confirmed yet
+	}
+	status, err := ecs.na.UnpackStatus(callOpts, channelId)
+	if err != nil {
+		return protocols.AdjudicationStatus{}, fmt.Errorf("could not read the status of channel %s: %w", channelId, err)
+	}
+	var fingerprint [20]byte
+	status.Fingerprint.FillBytes(fingerprint[:])
+	return protocols.AdjudicationStatus{
+		TurnNumRecord: status.TurnNumRecord.Uint64(),
+		FinalizesAt:   status.FinalizesAt.Uint64(),
+		Fingerprint:   fingerprint,
+	}, nil
+}
+
+// ChainID returns the id of the chain the adjudicator is deployed on.
+func (ecs *EthChainService) ChainID() (*big.Int, error) {
+	chainId, err := ecs.na.GetChainID(&bind.CallOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("could not read the chain id: %w", err)
+	}
+	return chainId, nil
+}
+
+// confirmedCallOpts returns the options for reading contract state as of the latest confirmed block, and the number of that block.
+// If no block has been confirmed yet, the returned options are nil.
+func (ecs *EthChainService) confirmedCallOpts() (*bind.CallOpts, uint64, error) {
+	head, err := ecs.chain.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not read the latest block: %w", err)
+	}
+	blockNum := head.Number.Uint64()
+	if ecs.confirmations == 0 {
+		return &bind.CallOpts{}, blockNum, nil
 	}
+	if blockNum < ecs.confirmations {
+		return nil, 0, nil
+	}
+	blockNum -= ecs.confirmations
+	return &bind.CallOpts{BlockNumber: new(big.Int).SetUint64(blockNum)}, blockNum, nil
 }
 
 // SendTransaction sends the transaction and blocks until it has been submitted.
-func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) []*ethTypes.Transaction {
+// The submitted transactions are watched until they are mined, and a TransactionFailedEvent is emitted for the channel if one of them fails.
+func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) ([]*ethTypes.Transaction, error) {
 	switch tx := tx.(type) {
 	case protocols.DepositTransaction:
 		ethTxs := []*ethTypes.Transaction{}
 		for tokenAddress, amount := range tx.Deposit {
-			txOpts := ecs.defaultTxOpts()
-			ethTokenAddress := common.Address{}
-			if tokenAddress == ethTokenAddress {
-				txOpts.Value = amount
-			} else {
-				tokenTransactor, err := Token.NewTokenTransactor(tokenAddress, ecs.chain)
-				if err != nil {
-					panic(err)
-				}
-				_, err = tokenTransactor.Approve(ecs.defaultTxOpts(), ecs.naAddress, amount)
-				if err != nil {
-					panic(err)
-				}
-			}
-			holdings, err := ecs.na.Holdings(&bind.CallOpts{}, tokenAddress, tx.ChannelId())
+			ethTx, err := ecs.deposit(tx, tokenAddress, amount)
 			if err != nil {
-				panic(err)
+				return ethTxs, &TransactionError{ChannelId: tx.ChannelId(), Err: err}
+			}
+			if ethTx != nil {
+				ethTxs = append(ethTxs, ethTx)
 			}
+		}
+		return ethTxs, nil
+	case protocols.WithdrawAllTransaction:
+		nitroFixedPart, nitroSignedVariableParts := convertSupportedState(tx.SignedState)
+		ethTx, err := ecs.txm.send(tx.ChannelId(), func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.ConcludeAndTransferAllAssets(opts, nitroFixedPart, nitroSignedVariableParts)
+		})
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not conclude and transfer: %w", err)}
+		}
+		return []*ethTypes.Transaction{ethTx}, nil
+	case protocols.ChallengeTransaction:
+		nitroFixedPart, nitroSignedVariableParts := convertSupportedState(tx.Candidate)
+		ethTx, err := ecs.txm.send(tx.ChannelId(), func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.Challenge(opts, nitroFixedPart, nitroSignedVariableParts, NitroAdjudicator.ConvertSignature(tx.ChallengerSig))
+		})
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not challenge: %w", err)}
+		}
+		return []*ethTypes.Transaction{ethTx}, nil
+	case protocols.CheckpointTransaction:
+		nitroFixedPart, nitroSignedVariableParts := convertSupportedState(tx.SignedState)
+		ethTx, err := ecs.txm.send(tx.ChannelId(), func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.Checkpoint(opts, nitroFixedPart, nitroSignedVariableParts)
+		})
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not checkpoint: %w", err)}
+		}
+		return []*ethTypes.Transaction{ethTx}, nil
+	case protocols.TransferAllTransaction:
+		stateHash, err := tx.State.Hash()
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not hash state: %w", err)}
+		}
+		ethTx, err := ecs.txm.send(tx.ChannelId(), func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.TransferAllAssets(opts, tx.ChannelId(), NitroAdjudicator.ConvertOutcome(tx.State.Outcome), stateHash)
+		})
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not transfer: %w", err)}
+		}
+		return []*ethTypes.Transaction{ethTx}, nil
+	case protocols.TransferTransaction:
+		stateHash, err := tx.State.Hash()
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not hash state: %w", err)}
+		}
+		outcomeBytes, err := tx.Outcome.Encode()
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not encode outcome: %w", err)}
+		}
+		ethTx, err := ecs.txm.send(tx.ChannelId(), func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.Transfer(opts, new(big.Int).SetUint64(uint64(tx.AssetIndex)), tx.ChannelId(), outcomeBytes, stateHash, toBigInts(tx.Indices))
+		})
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not transfer: %w", err)}
+		}
+		return []*ethTypes.Transaction{ethTx}, nil
+	case protocols.ClaimTransaction:
+		claimArgs, err := convertClaim(tx)
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: err}
+		}
+		ethTx, err := ecs.txm.send(tx.ChannelId(), func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+			return ecs.na.Claim(opts, claimArgs)
+		})
+		if err != nil {
+			return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("could not claim: %w", err)}
+		}
+		return []*ethTypes.Transaction{ethTx}, nil
+
+	default:
+		return []*ethTypes.Transaction{}, &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("%w: %T", ErrUnexpectedTransaction, tx)}
+	}
+}
 
-			ethTx, err := ecs.na.Deposit(txOpts, tokenAddress, tx.ChannelId(), holdings, amount)
+// deposit submits a deposit of a single asset. ERC20 tokens are first approved for transfer by the adjudicator, if necessary.
+//
+// The adjudicator tops the channel's holdings up to expectedHeld + amount, where expectedHeld is taken from the transaction if it is known there.
+// If another participant's deposit means the holdings are already sufficient, no transaction is sent and a nil transaction is returned.
+func (ecs *EthChainService) deposit(tx protocols.DepositTransaction, tokenAddress common.Address, amount *big.Int) (*ethTypes.Transaction, error) {
+	isToken := tokenAddress != common.Address{}
+	send := ecs.txm.send
+	if isToken {
+		// The allowance is shared by every deposit of the token, so deposits are approved and submitted one at a time
+		ecs.allowanceMu.Lock()
+		defer ecs.allowanceMu.Unlock()
+		if err := ecs.approve(tokenAddress, amount); err != nil {
+			return nil, err
+		}
+		send = func(channelId types.Destination, build func(*bind.TransactOpts) (*ethTypes.Transaction, error)) (*ethTypes.Transaction, error) {
+			// The amount is reserved before the deposit is submitted, since it may be mined (and released) before sendThen returns
+			ecs.reserve(tokenAddress, amount)
+			ethTx, err := ecs.txm.sendThen(channelId, build, func() { ecs.release(tokenAddress, amount) })
 			if err != nil {
-				panic(err)
+				ecs.release(tokenAddress, amount)
 			}
-			ethTxs = append(ethTxs, ethTx)
+			return ethTx, err
 		}
-		return ethTxs
-	case protocols.WithdrawAllTransaction:
-		state := tx.SignedState.State()
-		signatures := tx.SignedState.Signatures()
-		nitroFixedPart := NitroAdjudicator.INitroTypesFixedPart(state.FixedPart())
-		nitroVariablePart := NitroAdjudicator.ConvertVariablePart(state.VariablePart())
-		nitroSignatures := []NitroAdjudicator.INitroTypesSignature{NitroAdjudicator.ConvertSignature(signatures[0]), NitroAdjudicator.ConvertSignature(signatures[1])}
-		nitroSignedVariableParts := []NitroAdjudicator.INitroTypesSignedVariablePart{{
-			VariablePart: nitroVariablePart,
-			Sigs:         nitroSignatures,
-			SignedBy:     big.NewInt(0b11),
-		}}
-		ethTx, err := ecs.na.ConcludeAndTransferAllAssets(ecs.defaultTxOpts(), nitroFixedPart, nitroSignedVariableParts)
-		if err != nil {
-			panic(err)
-		}
-		return []*ethTypes.Transaction{ethTx}
+	}
+
+	expectedHeld, ok := tx.ExpectedHeld[tokenAddress]
+	if !ok {
+		var err error
+		expectedHeld, err = ecs.na.Holdings(&bind.CallOpts{}, tokenAddress, tx.ChannelId())
+		if err != nil {
+			return nil, fmt.Errorf("could not read holdings: %w", err)
+		}
+	}
 
+	ethTx, err := send(tx.ChannelId(), func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+		if !isToken {
+			opts.Value = amount
+		}
+		return ecs.na.Deposit(opts, tokenAddress, tx.ChannelId(), expectedHeld, amount)
+	})
+	switch {
+	case err == nil:
+		return ethTx, nil
+	case isRevertedWith(err, "holdings already sufficient"):
+		return nil, nil
+	case isToken && (isRevertedWith(err, "Could not deposit ERC20s") || isRevertedWith(err, "ERC20:")):
+		return nil, &TokenTransferError{Token: tokenAddress, Amount: amount, Err: err}
 	default:
-		panic("unexpected chain transaction")
+		return nil, fmt.Errorf("could not deposit: %w", err)
 	}
 }
 
-func (ecs *EthChainService) listenForLogEvents() {
-	query := ethereum.FilterQuery{
-		Addresses: []common.Address{ecs.naAddress},
+// approve ensures that the adjudicator is allowed to transfer the supplied amount of the token from the chain service's account,
+// on top of the amount reserved by deposits which have been submitted but not yet mined.
+// If the current allowance is too low, an approval is submitted and approve waits for it to be mined. The caller must hold ecs.allowanceMu.
+func (ecs *EthChainService) approve(tokenAddress common.Address, amount *big.Int) error {
+	token, err := Token.NewToken(tokenAddress, ecs.chain)
+	if err != nil {
+		return err
 	}
-	logs := make(chan ethTypes.Log)
-	sub, err := ecs.chain.SubscribeFilterLogs(context.Background(), query, logs)
+	allowance, err := token.Allowance(&bind.CallOpts{}, ecs.txm.signer.From, ecs.naAddress)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("could not read allowance of token %s: %w", tokenAddress, err)
+	}
+	required := new(big.Int).Add(ecs.reservedAllowance(tokenAddress), amount)
+	if allowance.Cmp(required) >= 0 {
+		return nil
+	}
+	_, err = ecs.txm.sendAndWait(func(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+		return token.Approve(opts, ecs.naAddress, required)
+	})
+	if err != nil {
+		return fmt.Errorf("could not approve token %s: %w", tokenAddress, err)
+	}
+	return nil
+}
+
+// reservedAllowance returns the amount of the token which submitted deposits, not yet mined, will transfer out of the allowance.
+func (ecs *EthChainService) reservedAllowance(tokenAddress common.Address) *big.Int {
+	ecs.reservedMu.Lock()
+	defer ecs.reservedMu.Unlock()
+	if reserved, ok := ecs.reserved[tokenAddress]; ok {
+		return new(big.Int).Set(reserved)
+	}
+	return new(big.Int)
+}
+
+// reserve records that a submitted deposit will transfer the amount of the token out of the allowance.
+func (ecs *EthChainService) reserve(tokenAddress common.Address, amount *big.Int) {
+	ecs.reservedMu.Lock()
+	defer ecs.reservedMu.Unlock()
+	reserved, ok := ecs.reserved[tokenAddress]
+	if !ok {
+		reserved = new(big.Int)
+	}
+	ecs.reserved[tokenAddress] = new(big.Int).Add(reserved, amount)
+}
+
+// release records that a deposit of the amount of the token has been mined (and so no longer needs to be reserved), or given up on.
+func (ecs *EthChainService) release(tokenAddress common.Address, amount *big.Int) {
+	ecs.reservedMu.Lock()
+	defer ecs.reservedMu.Unlock()
+	reserved, ok := ecs.reserved[tokenAddress]
+	if !ok {
+		return
+	}
+	remaining := new(big.Int).Sub(reserved, amount)
+	if remaining.Sign() <= 0 {
+		delete(ecs.reserved, tokenAddress)
+		return
+	}
+	ecs.reserved[tokenAddress] = remaining
+}
+
+// isRevertedWith returns true if the error reports that a transaction would revert with a reason containing the supplied string.
+func isRevertedWith(err error, reason string) bool {
+	return strings.Contains(err.Error(), "execution reverted") && strings.Contains(err.Error(), reason)
+}
+
+// reportTransactionFailure emits a TransactionFailedEvent for the channel.
+func (ecs *EthChainService) reportTransactionFailure(channelId types.Destination, blockNum uint64, txHash common.Hash, err error) {
+	ecs.broadcast(TransactionFailedEvent{CommonEvent: CommonEvent{channelID: channelId, BlockNum: blockNum}, TxHash: txHash, Err: err})
+}
+
+// listenForLogEvents converts the adjudicator logs received for monitored channels into chain events.
+//
+// A log is converted once it has been confirmed by the configured number of blocks. If a reorg removes a log which has already been converted,
+// an EventRevertedEvent is emitted. Logs removed before they are confirmed are discarded.
+func (ecs *EthChainService) listenForLogEvents() {
+	// New block headers tell us the chain's time, which determines when challenges finalize
+	heads := make(chan *ethTypes.Header)
+	headSub, err := ecs.chain.SubscribeNewHead(context.Background(), heads)
+	if err != nil {
+		ecs.reportError(fmt.Errorf("could not subscribe to new blocks: %w", err))
+		return
+	}
+	// challenges records the time at which each registered (and not yet cleared or finalized) challenge finalizes
+	challenges := make(map[types.Destination]uint64)
+	// latest is the most recent block we have seen, which may arrive before or after the logs it contains
+	latest := &ethTypes.Header{Number: new(big.Int)}
+	// recent records the headers of the blocks which are not yet confirmed, by block number, so that the confirmed block's header is known once it is confirmed
+	recent := make(map[uint64]*ethTypes.Header)
+	// confirmed is the most recent block which has been confirmed by the configured number of blocks, or nil if no block has been confirmed yet
+	var confirmed *ethTypes.Header
+	// received records the logs of recent blocks, since a log may be delivered twice while the subscription is replaced, and may later be removed by a reorg
+	received := newLogSet()
+	// finalizeChallenges emits a ChallengeFinalizedEvent for each challenge which has expired as of the confirmed block
+	finalizeChallenges := func() {
+		if confirmed == nil {
+			return
+		}
+		for channelId, finalizesAt := range challenges {
+			if finalizesAt <= confirmed.Time {
+				event := ChallengeFinalizedEvent{CommonEvent: CommonEvent{channelID: channelId, BlockNum: confirmed.Number.Uint64()}, FinalizesAt: finalizesAt}
+				ecs.broadcast(event)
+				delete(challenges, channelId)
+			}
+		}
+	}
+	emit := func(rl *receivedLog) {
+		rl.confirmed = true
+		event, err := ecs.eventFromLog(rl.log)
+		if err != nil {
+			ecs.reportError(err)
+			return
+		}
+		rl.event = ecs.broadcast(event)
+		switch e := event.(type) {
+		case ConcludedEvent, ChallengeClearedEvent:
+			delete(challenges, e.ChannelID())
+		case ChallengeRegisteredEvent:
+			challenges[e.ChannelID()] = e.FinalizesAt
+			finalizeChallenges()
+		}
+	}
+	revert := func(rl *receivedLog) {
+		if rl.event == nil {
+			return
+		}
+		ecs.broadcast(EventRevertedEvent{CommonEvent: CommonEvent{channelID: rl.event.ChannelID(), BlockNum: rl.log.BlockNumber}, Reverted: rl.event})
+		// A reverted challenge will not finalize. Reverted checkpoints and conclusions are not undone: the chain will report the channel's status again once the reorg is complete
+		if registered, ok := rl.event.(ChallengeRegisteredEvent); ok && challenges[registered.ChannelID()] == registered.FinalizesAt {
+			delete(challenges, registered.ChannelID())
+		}
 	}
 	for {
 		select {
-		case err := <-sub.Err():
-			log.Fatal(err)
-		case chainEvent := <-logs:
-			switch chainEvent.Topics[0] {
-			case depositedTopic:
-				nad, err := ecs.na.ParseDeposited(chainEvent)
-				if err != nil {
-					log.Fatal(err)
+		case err := <-headSub.Err():
+			ecs.reportError(fmt.Errorf("new block subscription failed: %w", err))
+			return
+		case head := <-heads:
+			latest = head
+			// A reorg replaces the headers of the blocks it removes
+			recent[latest.Number.Uint64()] = latest
+			if latest.Number.Uint64() >= ecs.confirmations {
+				confirmedBlock := latest.Number.Uint64() - ecs.confirmations
+				for _, rl := range received.unconfirmed(confirmedBlock) {
+					emit(rl)
 				}
-
-				event := DepositedEvent{
-					CommonEvent: CommonEvent{
-						channelID: nad.Destination,
-						BlockNum:  chainEvent.BlockNumber,
-					},
-					Asset:           nad.Asset,
-					AmountDeposited: nad.AmountDeposited,
-					NowHeld:         nad.DestinationHoldings,
+				received.prune(confirmedBlock)
+				if header, ok := recent[confirmedBlock]; ok {
+					confirmed = header
 				}
-				ecs.broadcast(event)
-			case allocationUpdatedTopic:
-				au, err := ecs.na.ParseAllocationUpdated(chainEvent)
-				if err != nil {
-					panic(err)
+				for number := range recent {
+					if number < confirmedBlock {
+						delete(recent, number)
+					}
 				}
-
-				tx, pending, err := ecs.chain.TransactionByHash(context.Background(), chainEvent.TxHash)
-				if pending || err != nil {
-					panic("Expected transacion to be part of the chain")
+			}
+			finalizeChallenges()
+		case chainEvent := <-ecs.logs:
+			if chainEvent.Removed {
+				if rl, ok := received.remove(chainEvent); ok {
+					revert(rl)
 				}
+				continue
+			}
+			rl, ok := received.add(chainEvent)
+			if !ok {
+				continue
+			}
+			if chainEvent.BlockNumber+ecs.confirmations <= latest.Number.Uint64() || ecs.confirmations == 0 {
+				emit(rl)
+			}
+		}
+	}
+}
 
-				assetAddress, amount, err := getChainHolding(ecs.na, tx, au)
-				if err != nil {
-					panic(err)
-				}
-				event := AllocationUpdatedEvent{CommonEvent: CommonEvent{channelID: au.ChannelId, BlockNum: chainEvent.BlockNumber}, AssetAddress: assetAddress, AssetAmount: amount}
-				ecs.broadcast(event)
-			case concludedTopic:
-				ce, err := ecs.na.ParseConcluded(chainEvent)
-				if err != nil {
-					panic(err)
-				}
+// eventFromLog converts an adjudicator log into a chain event.
+func (ecs *EthChainService) eventFromLog(chainEvent ethTypes.Log) (Event, error) {
+	switch chainEvent.Topics[0] {
+	case depositedTopic:
+		nad, err := ecs.na.ParseDeposited(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse Deposited event: %w", err)
+		}
 
-				event := ConcludedEvent{CommonEvent: CommonEvent{channelID: ce.ChannelId, BlockNum: chainEvent.BlockNumber}}
-				ecs.broadcast(event)
-			default:
-				panic("Unknown chain event")
-			}
+		event := DepositedEvent{
+			CommonEvent: CommonEvent{
+				channelID: nad.Destination,
+				BlockNum:  chainEvent.BlockNumber,
+			},
+			Asset:           nad.Asset,
+			AmountDeposited: nad.AmountDeposited,
+			NowHeld:         nad.DestinationHoldings,
+		}
+		return event, nil
+	case allocationUpdatedTopic:
+		au, err := ecs.na.ParseAllocationUpdated(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse AllocationUpdated event: %w", err)
+		}
+
+		tx, pending, err := ecs.chain.TransactionByHash(context.Background(), chainEvent.TxHash)
+		if err != nil {
+			return nil, fmt.Errorf("could not fetch transaction %s: %w", chainEvent.TxHash, err)
+		}
+		if pending {
+			return nil, fmt.Errorf("expected transaction %s to be part of the chain", chainEvent.TxHash)
 		}
+
+		assetAddress, amount, err := getChainHolding(ecs.na, tx, au)
+		if err != nil {
+			return nil, fmt.Errorf("could not determine holdings for channel %s: %w", au.ChannelId, err)
+		}
+		event := AllocationUpdatedEvent{CommonEvent: CommonEvent{channelID: au.ChannelId, BlockNum: chainEvent.BlockNumber}, AssetAddress: assetAddress, AssetAmount: amount}
+		return event, nil
+	case concludedTopic:
+		ce, err := ecs.na.ParseConcluded(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse Concluded event: %w", err)
+		}
+
+		event := ConcludedEvent{CommonEvent: CommonEvent{channelID: ce.ChannelId, BlockNum: chainEvent.BlockNumber}}
+		return event, nil
+	case challengeRegisteredTopic:
+		cr, err := ecs.na.ParseChallengeRegistered(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse ChallengeRegistered event: %w", err)
+		}
+
+		event := ChallengeRegisteredEvent{
+			CommonEvent: CommonEvent{
+				channelID: cr.ChannelId,
+				BlockNum:  chainEvent.BlockNumber,
+			},
+			TurnNumRecord: cr.TurnNumRecord.Uint64(),
+			FinalizesAt:   cr.FinalizesAt.Uint64(),
+			IsFinal:       cr.IsFinal,
+		}
+		return event, nil
+	case challengeClearedTopic:
+		cc, err := ecs.na.ParseChallengeCleared(chainEvent)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse ChallengeCleared event: %w", err)
+		}
+
+		event := ChallengeClearedEvent{CommonEvent: CommonEvent{channelID: cc.ChannelId, BlockNum: chainEvent.BlockNumber}, NewTurnNumRecord: cc.NewTurnNumRecord.Uint64()}
+		return event, nil
+	default:
+		return nil, fmt.Errorf("unknown chain event with topic %s", chainEvent.Topics[0])
+	}
+}
+
+// receivedLog is a log received from the chain, along with the event it was converted into (if any).
+type receivedLog struct {
+	log       ethTypes.Log
+	confirmed bool  // whether the log has been confirmed by enough blocks to be converted
+	event     Event // the event emitted for the log, or nil if none was emitted
+}
+
+// logKey identifies a log. Logs are identified by block hash rather than block number, since a reorg may replace the block at a given height.
+type logKey struct {
+	blockHash common.Hash
+	index     uint
+}
+
+// logSet records the logs of recent blocks.
+type logSet struct {
+	logs map[logKey]*receivedLog
+}
+
+// logSetDepth is the number of blocks for which a logSet remembers confirmed logs. Older logs are forgotten, and can no longer be reverted.
+const logSetDepth = 16
+
+func newLogSet() *logSet {
+	return &logSet{logs: make(map[logKey]*receivedLog)}
+}
+
+// add records the log, and returns false if it was already recorded.
+func (ls *logSet) add(l ethTypes.Log) (*receivedLog, bool) {
+	key := logKey{l.BlockHash, l.Index}
+	if _, ok := ls.logs[key]; ok {
+		return nil, false
+	}
+	rl := &receivedLog{log: l}
+	ls.logs[key] = rl
+	return rl, true
+}
+
+// remove forgets the log, and returns false if it was not recorded.
+func (ls *logSet) remove(l ethTypes.Log) (*receivedLog, bool) {
+	key := logKey{l.BlockHash, l.Index}
+	rl, ok := ls.logs[key]
+	delete(ls.logs, key)
+	return rl, ok
+}
+
+// unconfirmed returns the unconfirmed logs in blocks up to and including the given block, in the order they were emitted.
+func (ls *logSet) unconfirmed(upToBlock uint64) []*receivedLog {
+	unconfirmed := []*receivedLog{}
+	for _, rl := range ls.logs {
+		if !rl.confirmed && rl.log.BlockNumber <= upToBlock {
+			unconfirmed = append(unconfirmed, rl)
+		}
+	}
+	sort.Slice(unconfirmed, func(i, j int) bool {
+		if unconfirmed[i].log.BlockNumber != unconfirmed[j].log.BlockNumber {
+			return unconfirmed[i].log.BlockNumber < unconfirmed[j].log.BlockNumber
+		}
+		return unconfirmed[i].log.Index < unconfirmed[j].log.Index
+	})
+	return unconfirmed
+}
+
+// prune forgets the confirmed logs more than logSetDepth blocks older than the given block.
+func (ls *logSet) prune(block uint64) {
+	for key, rl := range ls.logs {
+		if rl.confirmed && rl.log.BlockNumber+logSetDepth < block {
+			delete(ls.logs, key)
+		}
+	}
+}
+
+// convertClaim converts a ClaimTransaction into the arguments expected by the adjudicator's claim method.
+func convertClaim(tx protocols.ClaimTransaction) (NitroAdjudicator.IMultiAssetHolderClaimArgs, error) {
+	sourceStateHash, err := tx.Source.Hash()
+	if err != nil {
+		return NitroAdjudicator.IMultiAssetHolderClaimArgs{}, fmt.Errorf("could not hash source state: %w", err)
 	}
+	sourceOutcomeBytes, err := tx.SourceOutcome.Encode()
+	if err != nil {
+		return NitroAdjudicator.IMultiAssetHolderClaimArgs{}, fmt.Errorf("could not encode source outcome: %w", err)
+	}
+	targetStateHash, err := tx.Target.Hash()
+	if err != nil {
+		return NitroAdjudicator.IMultiAssetHolderClaimArgs{}, fmt.Errorf("could not hash target state: %w", err)
+	}
+	targetOutcomeBytes, err := tx.TargetOutcome.Encode()
+	if err != nil {
+		return NitroAdjudicator.IMultiAssetHolderClaimArgs{}, fmt.Errorf("could not encode target outcome: %w", err)
+	}
+	return NitroAdjudicator.IMultiAssetHolderClaimArgs{
+		SourceChannelId:                 tx.ChannelId(),
+		SourceStateHash:                 sourceStateHash,
+		SourceOutcomeBytes:              sourceOutcomeBytes,
+		SourceAssetIndex:                new(big.Int).SetUint64(uint64(tx.SourceAssetIndex)),
+		IndexOfTargetInSource:           new(big.Int).SetUint64(uint64(tx.IndexOfTargetInSource)),
+		TargetStateHash:                 targetStateHash,
+		TargetOutcomeBytes:              targetOutcomeBytes,
+		TargetAssetIndex:                new(big.Int).SetUint64(uint64(tx.TargetAssetIndex)),
+		TargetAllocationIndicesToPayout: toBigInts(tx.TargetAllocationIndicesToPayout),
+	}, nil
+}
+
+// toBigInts converts allocation indices into the form expected by the adjudicator.
+func toBigInts(indices []uint) []*big.Int {
+	converted := make([]*big.Int, len(indices))
+	for i, index := range indices {
+		converted[i] = new(big.Int).SetUint64(uint64(index))
+	}
+	return converted
+}
+
+// convertSupportedState converts a state signed by every participant into the fixed part and signed variable parts expected by the adjudicator.
+func convertSupportedState(ss state.SignedState) (NitroAdjudicator.INitroTypesFixedPart, []NitroAdjudicator.INitroTypesSignedVariablePart) {
+	s := ss.State()
+	nitroSignatures := []NitroAdjudicator.INitroTypesSignature{}
+	for _, sig := range ss.Signatures() {
+		nitroSignatures = append(nitroSignatures, NitroAdjudicator.ConvertSignature(sig))
+	}
+	// Every participant has signed, so every bit of signedBy is set
+	signedBy := new(big.Int).Lsh(big.NewInt(1), uint(len(s.Participants)))
+	signedBy.Sub(signedBy, big.NewInt(1))
+
+	nitroSignedVariableParts := []NitroAdjudicator.INitroTypesSignedVariablePart{{
+		VariablePart: NitroAdjudicator.ConvertVariablePart(s.VariablePart()),
+		Sigs:         nitroSignatures,
+		SignedBy:     signedBy,
+	}}
+	return NitroAdjudicator.INitroTypesFixedPart(s.FixedPart()), nitroSignedVariableParts
 }
@@ -1,7 +1,16 @@
 package chainservice
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/client/engine/store/safesync"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -10,20 +19,69 @@ import (
 // ChainServices connect to with Go chans.
 //
 // It keeps a record of of holdings and adjudication status for each channel, accepts transactions and emits events.
+// Time on the mock chain is simulated: it starts at the time the chain is constructed, and only moves on when AdvanceTime is called.
 type MockChain struct {
 	ChainServiceBase
 
-	holdings   map[types.Destination]types.Funds // holdings tracks funds for each channel
-	blockNum   *uint64                           // MockChain is often passed around by value. The pointer allows for shared state.
-	txListener chan protocols.ChainTransaction   // this is used to broadcast transactions that have been received
+	holdings   safesync.Map[types.Funds]       // holdings tracks funds for each channel. Recorded funds are replaced rather than modified, since they may be read concurrently.
+	blockNum   *uint64                         // MockChain is often passed around by value. The pointer allows for shared state.
+	clock      *uint64                         // the timestamp of the latest block, in seconds since the unix epoch. It is read and written atomically.
+	txListener chan protocols.ChainTransaction // this is used to broadcast transactions that have been received
+	chainId    *big.Int
+
+	// status records the adjudication status of each channel. It must be safe for concurrent use, since time may be advanced
+	// while transactions are being sent.
+	status safesync.Map[mockAdjudicationStatus]
+}
+
+// mockAdjudicationStatus is the part of a channel's adjudication status that the mock chain keeps track of.
+// It mirrors the fingerprint stored by the adjudicator.
+type mockAdjudicationStatus struct {
+	turnNumRecord uint64
+	finalizesAt   uint64        // in seconds since the unix epoch, or 0 if no challenge is registered
+	stateHash     types.Bytes32 // the hash of the challenged state, or zero if the channel was checkpointed or concluded
+	outcomeHash   types.Bytes32 // the hash of the channel's outcome, as updated by any transfers since the outcome was finalized
+}
+
+// isFinalized returns true if the status records an outcome which is final at the given time.
+func (s mockAdjudicationStatus) isFinalized(now uint64) bool {
+	return s.finalizesAt != 0 && s.finalizesAt <= now
 }
 
-// NewMockChain returns a new MockChain.
+// fingerprint returns the low 160 bits of the hash of the status's state hash and outcome hash, as the adjudicator computes it.
+func (s mockAdjudicationStatus) fingerprint() [20]byte {
+	h := crypto.Keccak256(s.stateHash[:], s.outcomeHash[:])
+	var fingerprint [20]byte
+	copy(fingerprint[:], h[12:])
+	return fingerprint
+}
+
+var (
+	// ErrChannelNotFinalized is returned when a mock chain is asked to pay out a channel whose outcome is not yet final.
+	ErrChannelNotFinalized = errors.New("channel not finalized")
+	// ErrChannelFinalized is returned when a mock chain is asked to challenge, checkpoint or conclude a channel whose outcome is already final.
+	ErrChannelFinalized = errors.New("channel finalized")
+	// ErrStaleState is returned when a mock chain is asked to challenge or checkpoint with a state older than the one it has recorded.
+	ErrStaleState = errors.New("turn number not increased")
+	// ErrNotFinalState is returned when a mock chain is asked to conclude a channel with a state which is not final.
+	ErrNotFinalState = errors.New("state is not final")
+	// ErrIncorrectOutcome is returned when a mock chain is asked to pay out a channel with an outcome other than the one it has recorded.
+	ErrIncorrectOutcome = errors.New("incorrect outcome")
+)
+
+// NewMockChain returns a new MockChain, with chain id 1337 (the chain id of the simulated backend).
 func NewMockChain() *MockChain {
+	return NewMockChainWithChainID(big.NewInt(1337))
+}
+
+// NewMockChainWithChainID returns a new MockChain with the supplied chain id.
+func NewMockChainWithChainID(chainId *big.Int) *MockChain {
 	mc := MockChain{ChainServiceBase: newChainServiceBase()}
-	mc.holdings = make(map[types.Destination]types.Funds)
+	mc.chainId = new(big.Int).Set(chainId)
 	mc.blockNum = new(uint64)
 	*mc.blockNum = 1
+	mc.clock = new(uint64)
+	*mc.clock = uint64(time.Now().Unix())
 
 	return &mc
 }
@@ -37,15 +95,17 @@ func NewMockChainWithTransactionListener(txListener chan protocols.ChainTransact
 }
 
 // SendTransaction responds to the given tx.
-func (mc *MockChain) SendTransaction(tx protocols.ChainTransaction) {
+func (mc *MockChain) SendTransaction(tx protocols.ChainTransaction) error {
 	*mc.blockNum++
 	if mc.txListener != nil {
 		mc.txListener <- tx
 	}
 	switch tx := tx.(type) {
 	case protocols.DepositTransaction:
+		held, _ := mc.holdings.Load(tx.ChannelId().String())
 		if tx.Deposit.IsNonZero() {
-			mc.holdings[tx.ChannelId()] = mc.holdings[tx.ChannelId()].Add(tx.Deposit)
+			held = held.Add(tx.Deposit)
+			mc.holdings.Store(tx.ChannelId().String(), held)
 		}
 		for address, amount := range tx.Deposit {
 			event := DepositedEvent{
@@ -54,25 +114,277 @@ func (mc *MockChain) SendTransaction(tx protocols.ChainTransaction) {
 					BlockNum:  *mc.blockNum},
 				Asset:           address,
 				AmountDeposited: amount,
-				NowHeld:         mc.holdings[tx.ChannelId()][address],
+				NowHeld:         held[address],
 			}
 			mc.broadcast(event)
 		}
 	case protocols.WithdrawAllTransaction:
-		for assetAddress := range mc.holdings[tx.ChannelId()] {
-			event := AllocationUpdatedEvent{
-				CommonEvent: CommonEvent{
-					channelID: tx.ChannelId(),
-					BlockNum:  *mc.blockNum},
-				AssetAddress: assetAddress,
-				AssetAmount:  common.Big0,
-			}
-			mc.broadcast(event)
-		}
-		mc.holdings[tx.ChannelId()] = types.Funds{}
+		return mc.concludeAndTransferAll(tx)
+	case protocols.ChallengeTransaction:
+		return mc.registerChallenge(tx)
+	case protocols.CheckpointTransaction:
+		return mc.checkpoint(tx)
+	case protocols.TransferAllTransaction:
+		return mc.transferAll(tx.ChannelId(), tx.State.Outcome)
+	case protocols.TransferTransaction:
+		return mc.transfer(tx)
 	default:
-		panic("unexpected chain transaction")
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("%w: %T", ErrUnexpectedTransaction, tx)}
 	}
+	return nil
+}
+
+// AdvanceTime moves the mock chain's time on by the given duration (rounded down to the second), as if a new block had been mined,
+// and announces the finalization of any challenges which expire in the meantime.
+func (mc *MockChain) AdvanceTime(d time.Duration) {
+	*mc.blockNum++
+	after := atomic.AddUint64(mc.clock, uint64(d/time.Second))
+	before := after - uint64(d/time.Second)
+	mc.status.Range(func(key string, status mockAdjudicationStatus) bool {
+		if status.finalizesAt > before && status.finalizesAt <= after {
+			mc.broadcast(ChallengeFinalizedEvent{
+				CommonEvent: CommonEvent{channelID: types.Destination(common.HexToHash(key)), BlockNum: *mc.blockNum},
+				FinalizesAt: status.finalizesAt,
+			})
+		}
+		return true
+	})
+}
+
+// now returns the timestamp of the mock chain's latest block, in seconds since the unix epoch.
+func (mc *MockChain) now() uint64 {
+	return atomic.LoadUint64(mc.clock)
+}
+
+// GetHoldings returns the amount of each supplied asset currently held for the channel, and the number of the latest block.
+func (mc *MockChain) GetHoldings(channelId types.Destination, assets []common.Address) (types.Funds, uint64, error) {
+	blockNum := *mc.blockNum
+	held, _ := mc.holdings.Load(channelId.String())
+	holdings := types.Funds{}
+	for _, asset := range assets {
+		holdings[asset] = new(big.Int)
+		if amount, ok := held[asset]; ok && amount != nil {
+			holdings[asset].Set(amount)
+		}
+	}
+	return holdings, blockNum, nil
+}
+
+// GetAdjudicationStatus returns the channel's current adjudication status.
+func (mc *MockChain) GetAdjudicationStatus(channelId types.Destination) (protocols.AdjudicationStatus, error) {
+	status, ok := mc.status.Load(channelId.String())
+	if !ok {
+		return protocols.AdjudicationStatus{}, nil // the adjudicator has not recorded anything for the channel
+	}
+	return protocols.AdjudicationStatus{
+		TurnNumRecord: status.turnNumRecord,
+		FinalizesAt:   status.finalizesAt,
+		Fingerprint:   status.fingerprint(),
+	}, nil
+}
+
+// RequestHoldings emits a DepositedEvent reporting the amount of each supplied asset currently held for the channel.
+func (mc *MockChain) RequestHoldings(channelId types.Destination, assets []common.Address) error {
+	holdings, blockNum, err := mc.GetHoldings(channelId, assets)
+	if err != nil {
+		return err
+	}
+	for _, asset := range assets {
+		mc.broadcast(DepositedEvent{
+			CommonEvent:     CommonEvent{channelID: channelId, BlockNum: blockNum},
+			Asset:           asset,
+			AmountDeposited: new(big.Int),
+			NowHeld:         holdings[asset],
+		})
+	}
+	return nil
+}
+
+// registerChallenge records the challenge and announces it. If the challenge duration is zero, its finalization is announced at once.
+// Otherwise finalization is announced once time is advanced past the challenge's expiry.
+//
+// The mock chain does not check the signatures on the candidate state.
+func (mc *MockChain) registerChallenge(tx protocols.ChallengeTransaction) error {
+	candidate := tx.Candidate.State()
+	now := mc.now()
+	if existing, ok := mc.status.Load(tx.ChannelId().String()); ok {
+		if existing.isFinalized(now) {
+			return &TransactionError{ChannelId: tx.ChannelId(), Err: ErrChannelFinalized}
+		}
+		if candidate.TurnNum < existing.turnNumRecord {
+			return &TransactionError{ChannelId: tx.ChannelId(), Err: ErrStaleState}
+		}
+	}
+	outcomeHash, err := hashOutcome(candidate.Outcome)
+	if err != nil {
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: err}
+	}
+
+	stateHash, err := candidate.Hash()
+	if err != nil {
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: err}
+	}
+
+	status := mockAdjudicationStatus{
+		turnNumRecord: candidate.TurnNum,
+		finalizesAt:   now + candidate.ChallengeDuration.Uint64(),
+		stateHash:     stateHash,
+		outcomeHash:   outcomeHash,
+	}
+	mc.status.Store(tx.ChannelId().String(), status)
+
+	mc.broadcast(ChallengeRegisteredEvent{
+		CommonEvent:   CommonEvent{channelID: tx.ChannelId(), BlockNum: *mc.blockNum},
+		TurnNumRecord: status.turnNumRecord,
+		FinalizesAt:   status.finalizesAt,
+		IsFinal:       candidate.IsFinal,
+	})
+	if status.isFinalized(now) {
+		mc.broadcast(ChallengeFinalizedEvent{
+			CommonEvent: CommonEvent{channelID: tx.ChannelId(), BlockNum: *mc.blockNum},
+			FinalizesAt: status.finalizesAt,
+		})
+	}
+	return nil
+}
+
+// checkpoint records the turn number of the checkpointed state, clearing any challenge registered with an older state.
+//
+// The mock chain does not check the signatures on the checkpointed state.
+func (mc *MockChain) checkpoint(tx protocols.CheckpointTransaction) error {
+	turnNum := tx.SignedState.State().TurnNum
+	existing, ok := mc.status.Load(tx.ChannelId().String())
+	if ok && existing.isFinalized(mc.now()) {
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: ErrChannelFinalized}
+	}
+	if ok && turnNum <= existing.turnNumRecord {
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: ErrStaleState}
+	}
+
+	mc.status.Store(tx.ChannelId().String(), mockAdjudicationStatus{turnNumRecord: turnNum})
+	if ok && existing.finalizesAt != 0 {
+		mc.broadcast(ChallengeClearedEvent{
+			CommonEvent:      CommonEvent{channelID: tx.ChannelId(), BlockNum: *mc.blockNum},
+			NewTurnNumRecord: turnNum,
+		})
+	}
+	return nil
+}
+
+// concludeAndTransferAll finalizes the channel with the supplied final state, announces its conclusion and then pays out its outcome.
+//
+// The mock chain does not check the signatures on the final state.
+func (mc *MockChain) concludeAndTransferAll(tx protocols.WithdrawAllTransaction) error {
+	s := tx.SignedState.State()
+	if !s.IsFinal {
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: ErrNotFinalState}
+	}
+	now := mc.now()
+	if existing, ok := mc.status.Load(tx.ChannelId().String()); ok && existing.isFinalized(now) {
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: ErrChannelFinalized}
+	}
+	outcomeHash, err := hashOutcome(s.Outcome)
+	if err != nil {
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: err}
+	}
+
+	mc.status.Store(tx.ChannelId().String(), mockAdjudicationStatus{finalizesAt: now, outcomeHash: outcomeHash})
+	mc.broadcast(ConcludedEvent{CommonEvent: CommonEvent{channelID: tx.ChannelId(), BlockNum: *mc.blockNum}})
+	return mc.transferAll(tx.ChannelId(), s.Outcome)
+}
+
+// transferAll pays out every allocation of the finalized channel's outcome.
+func (mc *MockChain) transferAll(channelId types.Destination, o outcome.Exit) error {
+	status, err := mc.finalizedStatus(channelId, o)
+	if err != nil {
+		return err
+	}
+	newOutcome := o.Clone()
+	for assetIndex := range o {
+		newOutcome[assetIndex].Allocations = mc.payOut(channelId, o[assetIndex], []uint{})
+	}
+	return mc.recordOutcome(channelId, status, newOutcome)
+}
+
+// transfer pays out the requested allocations of a single asset of the finalized channel's outcome.
+func (mc *MockChain) transfer(tx protocols.TransferTransaction) error {
+	status, err := mc.finalizedStatus(tx.ChannelId(), tx.Outcome)
+	if err != nil {
+		return err
+	}
+	if tx.AssetIndex >= uint(len(tx.Outcome)) {
+		return &TransactionError{ChannelId: tx.ChannelId(), Err: fmt.Errorf("asset index %d is out of range", tx.AssetIndex)}
+	}
+	newOutcome := tx.Outcome.Clone()
+	newOutcome[tx.AssetIndex].Allocations = mc.payOut(tx.ChannelId(), tx.Outcome[tx.AssetIndex], tx.Indices)
+	return mc.recordOutcome(tx.ChannelId(), status, newOutcome)
+}
+
+// finalizedStatus returns the adjudication status of the channel, if its outcome is final and matches the supplied outcome.
+func (mc *MockChain) finalizedStatus(channelId types.Destination, o outcome.Exit) (mockAdjudicationStatus, error) {
+	status, ok := mc.status.Load(channelId.String())
+	if !ok || !status.isFinalized(mc.now()) {
+		return mockAdjudicationStatus{}, &TransactionError{ChannelId: channelId, Err: ErrChannelNotFinalized}
+	}
+	outcomeHash, err := hashOutcome(o)
+	if err != nil {
+		return mockAdjudicationStatus{}, &TransactionError{ChannelId: channelId, Err: err}
+	}
+	if outcomeHash != status.outcomeHash {
+		return mockAdjudicationStatus{}, &TransactionError{ChannelId: channelId, Err: ErrIncorrectOutcome}
+	}
+	return status, nil
+}
+
+// payOut pays the requested allocations (or all of them, if indices is empty) of a single asset out of the channel's holdings,
+// and announces the channel's new holdings. It returns the allocations which remain.
+func (mc *MockChain) payOut(channelId types.Destination, sae outcome.SingleAssetExit, indices []uint) outcome.Allocations {
+	holdings, _ := mc.holdings.Load(channelId.String())
+	held := new(big.Int)
+	if h, ok := holdings[sae.Asset]; ok && h != nil {
+		held.Set(h)
+	}
+	newAllocations, exitAllocations := outcome.ComputeTransferEffectsAndInteractions(*held, sae.Allocations, indices)
+	for _, exit := range exitAllocations {
+		if exit.Amount != nil {
+			held.Sub(held, exit.Amount)
+		}
+	}
+	holdings = holdings.Clone()
+	holdings[sae.Asset] = held
+	mc.holdings.Store(channelId.String(), holdings)
+
+	mc.broadcast(AllocationUpdatedEvent{
+		CommonEvent:  CommonEvent{channelID: channelId, BlockNum: *mc.blockNum},
+		AssetAddress: sae.Asset,
+		AssetAmount:  new(big.Int).Set(held),
+	})
+	return newAllocations
+}
+
+// recordOutcome records the channel's outcome, once it has been updated by a transfer.
+func (mc *MockChain) recordOutcome(channelId types.Destination, status mockAdjudicationStatus, o outcome.Exit) error {
+	outcomeHash, err := hashOutcome(o)
+	if err != nil {
+		return &TransactionError{ChannelId: channelId, Err: err}
+	}
+	status.outcomeHash = outcomeHash
+	mc.status.Store(channelId.String(), status)
+	return nil
+}
+
+// hashOutcome returns the hash of the abi encoded outcome, as recorded by the adjudicator.
+func hashOutcome(o outcome.Exit) (types.Bytes32, error) {
+	encoded, err := o.Encode()
+	if err != nil {
+		return types.Bytes32{}, fmt.Errorf("could not encode outcome: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// ChainID returns the mock chain's chain id.
+func (mc *MockChain) ChainID() (*big.Int, error) {
+	return new(big.Int).Set(mc.chainId), nil
 }
 
 // GetConsensusAppAddress returns the zero address, since the mock chain will not run any application logic.
@@ -1,10 +1,14 @@
 package chainservice
 
 import (
+	"errors"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -36,15 +40,23 @@ func TestDeposit(t *testing.T) {
 		common.HexToAddress("0x00"): big.NewInt(1),
 	}
 	testTx := protocols.NewDepositTransaction(types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`)), testDeposit)
+	chain.Monitor(a, testTx.ChannelId())
+	chain.Monitor(b, testTx.ChannelId())
 
 	// Send one transaction and receive one event from it.
-	chain.SendTransaction(testTx)
+	err = chain.SendTransaction(testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
 	event := <-eventFeedA
 
 	checkReceivedEventIsValid(t, event, testTx.Deposit, testTx.ChannelId())
 
 	// Send the transaction again and receive another event
-	chain.SendTransaction(testTx)
+	err = chain.SendTransaction(testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
 	event = <-eventFeedA
 
 	// The expectation is that the MockChainService remembered the previous deposit and added this one to it:
@@ -61,6 +73,325 @@ func TestDeposit(t *testing.T) {
 	checkReceivedEventIsValid(t, eventB, expectedHoldings, testTx.ChannelId())
 }
 
+func TestMonitor(t *testing.T) {
+	// The MockChain should only send events to subscribers which monitor the event's channel
+
+	var a = types.Address(common.HexToAddress(`a`))
+	var b = types.Address(common.HexToAddress(`b`))
+
+	var chain = NewMockChain()
+	eventFeedA := chain.SubscribeToEvents(a)
+	eventFeedB := chain.SubscribeToEvents(b)
+
+	testDeposit := types.Funds{common.HexToAddress("0x00"): big.NewInt(1)}
+	testTx := protocols.NewDepositTransaction(types.Destination{'c'}, testDeposit)
+	chain.Monitor(a, testTx.ChannelId())
+
+	err := chain.SendTransaction(testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkReceivedEventIsValid(t, <-eventFeedA, testTx.Deposit, testTx.ChannelId())
+	select {
+	case event := <-eventFeedB:
+		t.Fatalf("expected no events for a subscriber which does not monitor the channel, got %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// Once a stops monitoring the channel, it receives no further events
+	chain.Unmonitor(a, testTx.ChannelId())
+	err = chain.SendTransaction(testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-eventFeedA:
+		t.Fatalf("expected no events after unmonitoring the channel, got %v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestNoEventsDropped(t *testing.T) {
+	// The MockChain should queue events for a subscriber which is not receiving them, rather than dropping them
+
+	var a = types.Address(common.HexToAddress(`a`))
+	var chain = NewMockChain()
+	eventFeed := chain.SubscribeToEvents(a)
+
+	testDeposit := types.Funds{common.HexToAddress("0x00"): big.NewInt(1)}
+	testTx := protocols.NewDepositTransaction(types.Destination{'c'}, testDeposit)
+	chain.Monitor(a, testTx.ChannelId())
+
+	const numDeposits = 100
+	for i := 0; i < numDeposits; i++ {
+		err := chain.SendTransaction(testTx)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := 1; i <= numDeposits; i++ {
+		event := <-eventFeed
+		checkReceivedEventIsValid(t, event, types.Funds{common.HexToAddress("0x00"): big.NewInt(int64(i))}, testTx.ChannelId())
+		if event.Sequence() != uint64(i) {
+			t.Fatalf("expected sequence number %d, got %d", i, event.Sequence())
+		}
+	}
+}
+
+func TestReplayEvents(t *testing.T) {
+	// The MockChain should resend the events emitted since a given block for the channels a subscriber monitors
+
+	var a = types.Address(common.HexToAddress(`a`))
+	var chain = NewMockChain()
+	eventFeed := chain.SubscribeToEvents(a)
+
+	testDeposit := types.Funds{common.HexToAddress("0x00"): big.NewInt(1)}
+	monitored := protocols.NewDepositTransaction(types.Destination{'m'}, testDeposit)
+	unmonitored := protocols.NewDepositTransaction(types.Destination{'u'}, testDeposit)
+	chain.Monitor(a, monitored.ChannelId())
+
+	received := []Event{}
+	for _, tx := range []protocols.DepositTransaction{monitored, unmonitored, monitored, monitored} {
+		err := chain.SendTransaction(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.ChannelId() == monitored.ChannelId() {
+			received = append(received, <-eventFeed)
+		}
+	}
+
+	// Replay the events from the block of the second deposit into the monitored channel
+	fromBlock := received[1].(DepositedEvent).BlockNum
+	chain.ReplayEvents(a, fromBlock)
+	for _, expected := range received[1:] {
+		replayed := <-eventFeed
+		if replayed.Sequence() != expected.Sequence() || replayed.(DepositedEvent).BlockNum != expected.(DepositedEvent).BlockNum {
+			t.Fatalf("expected replayed event %+v, got %+v", expected, replayed)
+		}
+	}
+	select {
+	case event := <-eventFeed:
+		t.Fatalf("expected no further events, got %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRequestHoldings(t *testing.T) {
+	// The MockChain should report the current holdings of a channel on request
+
+	var a = types.Address(common.HexToAddress(`a`))
+	var chain = NewMockChain()
+	eventFeed := chain.SubscribeToEvents(a)
+
+	held, unheld := common.HexToAddress("0x00"), common.HexToAddress("0x01")
+	testDeposit := types.Funds{held: big.NewInt(2)}
+	testTx := protocols.NewDepositTransaction(types.Destination{'c'}, testDeposit)
+	err := chain.SendTransaction(testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The deposit is not reported, since a did not monitor the channel at the time
+	chain.Monitor(a, testTx.ChannelId())
+	err = chain.RequestHoldings(testTx.ChannelId(), []common.Address{held, unheld})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkReceivedEventIsValid(t, <-eventFeed, testDeposit, testTx.ChannelId())
+	checkReceivedEventIsValid(t, <-eventFeed, types.Funds{unheld: big.NewInt(0)}, testTx.ChannelId())
+}
+
+// mockChannelState returns a state for a channel between Alice and Bob, funded with 2 of the native asset and split equally between them.
+func mockChannelState(turnNum uint64, challengeDuration int64, isFinal bool) state.State {
+	return state.State{
+		ChainId:           big.NewInt(1337),
+		Participants:      []types.Address{Alice.Address(), Bob.Address()},
+		ChannelNonce:      big.NewInt(37140676580),
+		AppDefinition:     common.HexToAddress(`0x5e29E5Ab8EF33F050c7cc10B5a0456D975C5F88d`),
+		ChallengeDuration: big.NewInt(challengeDuration),
+		AppData:           []byte{},
+		Outcome:           concludeOutcome,
+		TurnNum:           turnNum,
+		IsFinal:           isFinal,
+	}
+}
+
+func TestChallengeMockChain(t *testing.T) {
+	// The MockChain should finalize a challenged channel once its time is advanced past the challenge's expiry, and then pay it out
+
+	var a = types.Address(common.HexToAddress(`a`))
+	var chain = NewMockChain()
+	eventFeed := chain.SubscribeToEvents(a)
+
+	s := mockChannelState(5, 60, false)
+	cId := s.ChannelId()
+	chain.Monitor(a, cId)
+	err := chain.SendTransaction(protocols.NewDepositTransaction(cId, types.Funds{common.Address{}: big.NewInt(2)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-eventFeed
+
+	err = chain.SendTransaction(protocols.NewChallengeTransaction(cId, state.NewSignedState(s), state.Signature{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registered := (<-eventFeed).(ChallengeRegisteredEvent)
+	if registered.TurnNumRecord != 5 || registered.FinalizesAt != chain.now()+60 {
+		t.Fatalf("unexpected challenge registered event %+v", registered)
+	}
+	checkAdjudicationStatus(t, chain, s, registered.FinalizesAt)
+
+	// A challenge with an older state is rejected
+	err = chain.SendTransaction(protocols.NewChallengeTransaction(cId, state.NewSignedState(mockChannelState(4, 60, false)), state.Signature{}))
+	if !errors.Is(err, ErrStaleState) {
+		t.Fatalf("expected %v, got %v", ErrStaleState, err)
+	}
+
+	// The channel cannot be paid out before the challenge expires
+	chain.AdvanceTime(59 * time.Second)
+	err = chain.SendTransaction(protocols.NewTransferAllTransaction(cId, s))
+	if !errors.Is(err, ErrChannelNotFinalized) {
+		t.Fatalf("expected %v, got %v", ErrChannelNotFinalized, err)
+	}
+
+	chain.AdvanceTime(time.Second)
+	finalized := (<-eventFeed).(ChallengeFinalizedEvent)
+	if finalized.FinalizesAt != registered.FinalizesAt {
+		t.Fatalf("expected the challenge to finalize at %d, got %d", registered.FinalizesAt, finalized.FinalizesAt)
+	}
+
+	// A finalized channel cannot be checkpointed
+	err = chain.SendTransaction(protocols.NewCheckpointTransaction(cId, state.NewSignedState(mockChannelState(6, 60, false))))
+	if !errors.Is(err, ErrChannelFinalized) {
+		t.Fatalf("expected %v, got %v", ErrChannelFinalized, err)
+	}
+
+	// Pay out the first allocation, then the rest of the channel's outcome as updated by the first transfer
+	err = chain.SendTransaction(protocols.NewTransferTransaction(cId, s, s.Outcome, 0, []uint{0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated := (<-eventFeed).(AllocationUpdatedEvent)
+	if updated.AssetAmount.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("expected holdings of 1 after the first transfer, got %v", updated.AssetAmount)
+	}
+
+	err = chain.SendTransaction(protocols.NewTransferTransaction(cId, s, s.Outcome, 0, []uint{}))
+	if !errors.Is(err, ErrIncorrectOutcome) {
+		t.Fatalf("expected %v, got %v", ErrIncorrectOutcome, err)
+	}
+	remaining := s.Outcome.Clone()
+	remaining[0].Allocations[0].Amount = big.NewInt(0)
+	err = chain.SendTransaction(protocols.NewTransferTransaction(cId, s, remaining, 0, []uint{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated = (<-eventFeed).(AllocationUpdatedEvent)
+	if updated.AssetAmount.Sign() != 0 {
+		t.Fatalf("expected holdings of 0 after the second transfer, got %v", updated.AssetAmount)
+	}
+}
+
+func TestCheckpointMockChain(t *testing.T) {
+	// The MockChain should clear a challenge when a newer state is checkpointed, so that the challenge never finalizes
+
+	var a = types.Address(common.HexToAddress(`a`))
+	var chain = NewMockChain()
+	eventFeed := chain.SubscribeToEvents(a)
+
+	s := mockChannelState(5, 60, false)
+	cId := s.ChannelId()
+	chain.Monitor(a, cId)
+
+	err := chain.SendTransaction(protocols.NewChallengeTransaction(cId, state.NewSignedState(s), state.Signature{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-eventFeed
+
+	err = chain.SendTransaction(protocols.NewCheckpointTransaction(cId, state.NewSignedState(s)))
+	if !errors.Is(err, ErrStaleState) {
+		t.Fatalf("expected %v, got %v", ErrStaleState, err)
+	}
+	err = chain.SendTransaction(protocols.NewCheckpointTransaction(cId, state.NewSignedState(mockChannelState(6, 60, false))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleared := (<-eventFeed).(ChallengeClearedEvent)
+	if cleared.NewTurnNumRecord != 6 {
+		t.Fatalf("expected new turn num record 6, got %d", cleared.NewTurnNumRecord)
+	}
+
+	chain.AdvanceTime(time.Hour)
+	select {
+	case event := <-eventFeed:
+		t.Fatalf("expected no events once the challenge is cleared, got %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestConcludeMockChain(t *testing.T) {
+	// The MockChain should conclude a channel with a final state and pay out its outcome
+
+	var a = types.Address(common.HexToAddress(`a`))
+	var chain = NewMockChain()
+	eventFeed := chain.SubscribeToEvents(a)
+
+	s := mockChannelState(5, 60, false)
+	cId := s.ChannelId()
+	chain.Monitor(a, cId)
+	err := chain.SendTransaction(protocols.NewDepositTransaction(cId, types.Funds{common.Address{}: big.NewInt(2)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-eventFeed
+
+	err = chain.SendTransaction(protocols.NewWithdrawAllTransaction(cId, state.NewSignedState(s)))
+	if !errors.Is(err, ErrNotFinalState) {
+		t.Fatalf("expected %v, got %v", ErrNotFinalState, err)
+	}
+
+	final := mockChannelState(6, 60, true)
+	err = chain.SendTransaction(protocols.NewWithdrawAllTransaction(cId, state.NewSignedState(final)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := (<-eventFeed).(ConcludedEvent); !ok {
+		t.Fatalf("expected a concluded event")
+	}
+	updated := (<-eventFeed).(AllocationUpdatedEvent)
+	if updated.AssetAmount.Sign() != 0 {
+		t.Fatalf("expected holdings of 0 after concluding, got %v", updated.AssetAmount)
+	}
+
+	// A concluded channel cannot be challenged
+	err = chain.SendTransaction(protocols.NewChallengeTransaction(cId, state.NewSignedState(mockChannelState(7, 60, false)), state.Signature{}))
+	if !errors.Is(err, ErrChannelFinalized) {
+		t.Fatalf("expected %v, got %v", ErrChannelFinalized, err)
+	}
+}
+
+// checkAdjudicationStatus checks that the chain service reports the status recorded by the adjudicator after a challenge with the given state.
+func checkAdjudicationStatus(t *testing.T, cs ChainService, challenged state.State, finalizesAt uint64) {
+	status, err := cs.GetAdjudicationStatus(challenged.ChannelId())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stateHash, _ := challenged.Hash()
+	encodedOutcome, _ := challenged.Outcome.Encode()
+	outcomeHash := crypto.Keccak256Hash(encodedOutcome)
+	var fingerprint [20]byte
+	copy(fingerprint[:], crypto.Keccak256(stateHash[:], outcomeHash[:])[12:])
+
+	expected := protocols.AdjudicationStatus{TurnNumRecord: challenged.TurnNum, FinalizesAt: finalizesAt, Fingerprint: fingerprint}
+	if status != expected {
+		t.Fatalf("expected adjudication status %+v, got %+v", expected, status)
+	}
+}
+
 func checkReceivedEventIsValid(t *testing.T, receivedEvent Event, holdings types.Funds, channelId types.Destination) {
 	if receivedEvent.ChannelID() != channelId {
 		t.Fatalf(`channelId mismatch: expected %v but got %v`, channelId, receivedEvent.ChannelID())
@@ -71,3 +402,39 @@ func checkReceivedEventIsValid(t *testing.T, receivedEvent Event, holdings types
 		t.Fatalf(`holdings mismatch: expected %v but got %v`, holdings[depositEvent.Asset], depositEvent.NowHeld)
 	}
 }
+
+func TestReplayEventsOnlyResendsRecentBlocks(t *testing.T) {
+	// The MockChain should only keep the events of the most recent blocks for replay
+
+	var a = types.Address(common.HexToAddress(`a`))
+	var chain = NewMockChain()
+	chain.history.depth = 2
+	eventFeed := chain.SubscribeToEvents(a)
+
+	testDeposit := types.Funds{common.HexToAddress("0x00"): big.NewInt(1)}
+	tx := protocols.NewDepositTransaction(types.Destination{'m'}, testDeposit)
+	chain.Monitor(a, tx.ChannelId())
+
+	received := []Event{}
+	for i := 0; i < 5; i++ {
+		err := chain.SendTransaction(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received = append(received, <-eventFeed)
+	}
+
+	// Each deposit is mined in its own block, so only the events of the last depth+1 blocks are kept
+	chain.ReplayEvents(a, 0)
+	for _, expected := range received[2:] {
+		replayed := <-eventFeed
+		if replayed.Sequence() != expected.Sequence() {
+			t.Fatalf("expected replayed event %+v, got %+v", expected, replayed)
+		}
+	}
+	select {
+	case event := <-eventFeed:
+		t.Fatalf("expected no further events, got %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -1,6 +1,7 @@
 package chainservice
 
 import (
+	"context"
 	"errors"
 	"math/big"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
 	ConsensusApp "github.com/statechannels/go-nitro/client/engine/chainservice/consensusapp"
@@ -44,17 +46,38 @@ type SimulatedBackendChainService struct {
 // and listens to events from an eventSource
 func NewSimulatedBackendChainService(sim simulatedChain, bindings bindings,
 	txSigner *bind.TransactOpts) ChainService {
-	return &SimulatedBackendChainService{sim: sim, EthChainService: NewEthChainService(sim,
+	return NewSimulatedBackendChainServiceWithConfirmations(sim, bindings, txSigner, 0)
+}
+
+// NewSimulatedBackendChainServiceWithConfirmations constructs a chain service which only emits an event once the supplied number of blocks
+// have been mined on top of the block containing it.
+func NewSimulatedBackendChainServiceWithConfirmations(sim simulatedChain, bindings bindings,
+	txSigner *bind.TransactOpts, confirmations uint64) ChainService {
+	return &SimulatedBackendChainService{sim: sim, EthChainService: NewEthChainServiceWithConfirmations(autoMiningChain{sim},
 		bindings.Adjudicator.Contract,
 		bindings.Adjudicator.Address,
 		bindings.ConsensusApp.Address,
-		txSigner)}
+		txSigner,
+		confirmations)}
 }
 
 // SendTransaction sends the transaction and blocks until it has been mined.
-func (sbcs *SimulatedBackendChainService) SendTransaction(tx protocols.ChainTransaction) {
-	sbcs.EthChainService.SendTransaction(tx)
-	sbcs.sim.Commit()
+func (sbcs *SimulatedBackendChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	_, err := sbcs.EthChainService.SendTransaction(tx)
+	return err
+}
+
+// autoMiningChain mines a block for every transaction sent to the simulated chain, so that the chain service can wait for its transactions to be mined.
+type autoMiningChain struct {
+	simulatedChain
+}
+
+func (amc autoMiningChain) SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	if err := amc.simulatedChain.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	amc.Commit()
+	return nil
 }
 
 // SetupSimulatedBackend creates a new SimulatedBackend with the supplied number of transacting accounts, deploys the Nitro Adjudicator and returns both.
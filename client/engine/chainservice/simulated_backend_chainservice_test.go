@@ -2,14 +2,20 @@ package chainservice
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/google/go-cmp/cmp"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
 	"github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
@@ -58,14 +64,22 @@ func TestDepositSimulatedBackendChainService(t *testing.T) {
 	testTx := protocols.NewDepositTransaction(channelID, testDeposit)
 
 	out := cs.SubscribeToEvents(ethAccounts[0].From)
+	cs.Monitor(ethAccounts[0].From, channelID)
 	// Submit transactiom
-	cs.SendTransaction(testTx)
+	err = cs.SendTransaction(testTx)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Check that the recieved events matches the expected event
 	for i := 0; i < 2; i++ {
 		receivedEvent := <-out
 		dEvent := receivedEvent.(DepositedEvent)
-		expectedEvent := DepositedEvent{CommonEvent: CommonEvent{channelID: channelID, BlockNum: 2}, Asset: dEvent.Asset, NowHeld: testDeposit[dEvent.Asset], AmountDeposited: testDeposit[dEvent.Asset]}
+		// Each transaction is mined in its own block, and the assets may be deposited in either order
+		if dEvent.BlockNum < 2 {
+			t.Fatalf("expected the deposit to be mined after block 1, got block %d", dEvent.BlockNum)
+		}
+		expectedEvent := DepositedEvent{CommonEvent: CommonEvent{channelID: channelID, BlockNum: dEvent.BlockNum, sequence: uint64(i + 1)}, Asset: dEvent.Asset, NowHeld: testDeposit[dEvent.Asset], AmountDeposited: testDeposit[dEvent.Asset]}
 		if diff := cmp.Diff(expectedEvent, dEvent, cmp.AllowUnexported(CommonEvent{}, big.Int{})); diff != "" {
 			t.Fatalf("Received event did not match expectation; (-want +got):\n%s", diff)
 		}
@@ -79,6 +93,82 @@ func TestDepositSimulatedBackendChainService(t *testing.T) {
 	sim.Close()
 }
 
+func TestMonitorSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	cs := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	out := cs.SubscribeToEvents(ethAccounts[0].From)
+
+	deposit := types.Funds{common.HexToAddress("0x00"): big.NewInt(1)}
+	monitored, unmonitored := types.Destination{'m'}, types.Destination{'u'}
+	cs.Monitor(ethAccounts[0].From, monitored)
+
+	// Only the deposit into the monitored channel is reported
+	for _, channelId := range []types.Destination{unmonitored, monitored} {
+		err = cs.SendTransaction(protocols.NewDepositTransaction(channelId, deposit))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case event := <-out:
+		if event.ChannelID() != monitored {
+			t.Fatalf("expected an event for channel %s, got %+v", monitored, event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an event for the monitored channel")
+	}
+
+	// Once the channel is unmonitored, deposits into it are not reported
+	cs.Unmonitor(ethAccounts[0].From, monitored)
+	err = cs.SendTransaction(protocols.NewDepositTransaction(monitored, deposit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case event := <-out:
+		t.Fatalf("expected no events, got %+v", event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// subscriptionCountingChain counts the log subscriptions made through it.
+type subscriptionCountingChain struct {
+	ethChain
+	subscriptions int
+}
+
+func (c *subscriptionCountingChain) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethTypes.Log) (ethereum.Subscription, error) {
+	c.subscriptions++
+	return c.ethChain.SubscribeFilterLogs(ctx, q, ch)
+}
+
+func TestMonitorManyChannelsSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	chain := &subscriptionCountingChain{ethChain: sim}
+	cs := NewEthChainService(chain, bindings.Adjudicator.Contract, bindings.Adjudicator.Address, bindings.ConsensusApp.Address, ethAccounts[0])
+
+	// Monitoring many channels at once resubscribes to the adjudicator's logs once
+	channelIds := []types.Destination{{'a'}, {'b'}, {'c'}}
+	cs.Monitor(ethAccounts[0].From, channelIds...)
+	if chain.subscriptions != 1 {
+		t.Fatalf("expected 1 log subscription, got %d", chain.subscriptions)
+	}
+
+	// Monitoring channels which are already monitored does not resubscribe
+	cs.Monitor(ethAccounts[0].From, channelIds...)
+	if chain.subscriptions != 1 {
+		t.Fatalf("expected 1 log subscription, got %d", chain.subscriptions)
+	}
+}
+
 func TestConcludeSimulatedBackendChainService(t *testing.T) {
 
 	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
@@ -112,9 +202,13 @@ func TestConcludeSimulatedBackendChainService(t *testing.T) {
 		common.HexToAddress("0x00"): big.NewInt(3),
 	}
 	cId := concludeState.ChannelId()
+	cs.Monitor(ethAccounts[0].From, cId)
 
 	depositTx := protocols.NewDepositTransaction(cId, testDeposit)
-	cs.SendTransaction(depositTx)
+	err = cs.SendTransaction(depositTx)
+	if err != nil {
+		t.Fatal(err)
+	}
 	<-out
 
 	signedConcludeState := state.NewSignedState(concludeState)
@@ -127,11 +221,14 @@ func TestConcludeSimulatedBackendChainService(t *testing.T) {
 		t.Fatal(err)
 	}
 	concludeTx := protocols.NewWithdrawAllTransaction(cId, signedConcludeState)
-	cs.SendTransaction(concludeTx)
+	err = cs.SendTransaction(concludeTx)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	// Check that the recieved event matches the expected event
 	concludedEvent := <-out
-	expectedEvent := ConcludedEvent{CommonEvent: CommonEvent{channelID: cId, BlockNum: 3}}
+	expectedEvent := ConcludedEvent{CommonEvent: CommonEvent{channelID: cId, BlockNum: 3, sequence: 2}}
 	if diff := cmp.Diff(expectedEvent, concludedEvent, cmp.AllowUnexported(CommonEvent{})); diff != "" {
 		t.Fatalf("Received event did not match expectation; (-want +got):\n%s", diff)
 	}
@@ -139,7 +236,7 @@ func TestConcludeSimulatedBackendChainService(t *testing.T) {
 	// Check that the recieved event matches the expected event
 	allocationUpdatedEvent := <-out
 	expectedEvent2 := AllocationUpdatedEvent{
-		CommonEvent:  CommonEvent{channelID: cId, BlockNum: 3},
+		CommonEvent:  CommonEvent{channelID: cId, BlockNum: 3, sequence: 3},
 		AssetAddress: common.Address{},
 		AssetAmount:  new(big.Int).SetInt64(1)}
 
@@ -162,3 +259,565 @@ func TestConcludeSimulatedBackendChainService(t *testing.T) {
 	// Not sure if this is necessary
 	sim.Close()
 }
+
+func TestChallengeSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	cs := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	out := cs.SubscribeToEvents(ethAccounts[0].From)
+
+	var challengeState = state.State{
+		ChainId: big.NewInt(1337),
+		Participants: []types.Address{
+			Alice.Address(),
+			Bob.Address(),
+		},
+		ChannelNonce:      big.NewInt(37140676581),
+		AppDefinition:     bindings.ConsensusApp.Address,
+		ChallengeDuration: big.NewInt(60),
+		AppData:           []byte{},
+		Outcome:           concludeOutcome,
+		TurnNum:           uint64(1),
+		IsFinal:           false,
+	}
+	cId := challengeState.ChannelId()
+	cs.Monitor(ethAccounts[0].From, cId)
+
+	// Fund channel
+	err = cs.SendTransaction(protocols.NewDepositTransaction(cId, types.Funds{common.HexToAddress("0x00"): big.NewInt(2)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-out
+
+	signedChallengeState := state.NewSignedState(challengeState)
+	for _, pk := range [][]byte{Alice.PrivateKey, Bob.PrivateKey} {
+		sig, _ := challengeState.Sign(pk)
+		if err := signedChallengeState.AddSignature(sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(challengeState, Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.SendTransaction(protocols.NewChallengeTransaction(cId, signedChallengeState, challengerSig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered, ok := (<-out).(ChallengeRegisteredEvent)
+	if !ok {
+		t.Fatalf("expected a ChallengeRegisteredEvent")
+	}
+	if registered.ChannelID() != cId || registered.TurnNumRecord != challengeState.TurnNum || registered.IsFinal {
+		t.Fatalf("Received event did not match expectation: %+v", registered)
+	}
+	checkAdjudicationStatus(t, cs, challengeState, registered.FinalizesAt)
+	holdings, _, err := cs.GetHoldings(cId, []common.Address{{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holdings[common.Address{}].Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("expected holdings of 2, got %v", holdings)
+	}
+
+	// The channel cannot be paid out before the challenge finalizes
+	transferTx := protocols.NewTransferAllTransaction(cId, challengeState)
+	if err := cs.SendTransaction(transferTx); err == nil {
+		t.Fatalf("expected an error transferring assets from a channel which is not finalized")
+	}
+
+	// Move the chain past the challenge duration
+	if err := sim.AdjustTime(time.Duration(challengeState.ChallengeDuration.Int64()) * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	finalized, ok := (<-out).(ChallengeFinalizedEvent)
+	if !ok {
+		t.Fatalf("expected a ChallengeFinalizedEvent")
+	}
+	if finalized.ChannelID() != cId || finalized.FinalizesAt != registered.FinalizesAt {
+		t.Fatalf("Received event did not match expectation: %+v", finalized)
+	}
+
+	err = cs.SendTransaction(transferTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocationUpdated, ok := (<-out).(AllocationUpdatedEvent)
+	if !ok {
+		t.Fatalf("expected an AllocationUpdatedEvent")
+	}
+	if allocationUpdated.ChannelID() != cId || allocationUpdated.AssetAmount.Sign() != 0 {
+		t.Fatalf("Received event did not match expectation: %+v", allocationUpdated)
+	}
+}
+
+func TestReorgSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	cs := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	out := cs.SubscribeToEvents(ethAccounts[0].From)
+
+	channelID := types.Destination{'r'}
+	cs.Monitor(ethAccounts[0].From, channelID)
+	parent := sim.Blockchain().CurrentBlock().Hash()
+
+	err = cs.SendTransaction(protocols.NewDepositTransaction(channelID, types.Funds{common.HexToAddress("0x00"): big.NewInt(1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deposited, ok := (<-out).(DepositedEvent)
+	if !ok {
+		t.Fatalf("expected a DepositedEvent")
+	}
+
+	// Replace the block containing the deposit with a longer chain which does not contain it
+	if err := sim.Fork(context.Background(), parent); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	sim.Commit()
+
+	select {
+	case event := <-out:
+		reverted, ok := event.(EventRevertedEvent)
+		if !ok {
+			t.Fatalf("expected an EventRevertedEvent, got %+v", event)
+		}
+		if reverted.ChannelID() != channelID || reverted.Reverted.Sequence() != deposited.Sequence() {
+			t.Fatalf("expected the deposit %+v to be reverted, got %+v", deposited, reverted.Reverted)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected an EventRevertedEvent")
+	}
+}
+
+func TestConfirmationsSimulatedBackendChainService(t *testing.T) {
+	const confirmations = 2
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	cs := NewSimulatedBackendChainServiceWithConfirmations(sim, bindings, ethAccounts[0], confirmations)
+	out := cs.SubscribeToEvents(ethAccounts[0].From)
+
+	channelID := types.Destination{'c'}
+	cs.Monitor(ethAccounts[0].From, channelID)
+	deposit := protocols.NewDepositTransaction(channelID, types.Funds{common.HexToAddress("0x00"): big.NewInt(1)})
+	expectNoEvent := func() {
+		select {
+		case event := <-out:
+			t.Fatalf("expected no events, got %+v", event)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// The deposit is only reported once it has been confirmed
+	err = cs.SendTransaction(deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	depositBlock := sim.Blockchain().CurrentBlock().NumberU64()
+	for i := 0; i < confirmations; i++ {
+		expectNoEvent()
+		sim.Commit()
+	}
+	select {
+	case event := <-out:
+		if deposited, ok := event.(DepositedEvent); !ok || deposited.BlockNum != depositBlock {
+			t.Fatalf("expected a DepositedEvent in block %d, got %+v", depositBlock, event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a DepositedEvent")
+	}
+
+	// A deposit which is removed by a reorg before it is confirmed is never reported
+	parent := sim.Blockchain().CurrentBlock().Hash()
+	err = cs.SendTransaction(deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.Fork(context.Background(), parent); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < confirmations+1; i++ {
+		sim.Commit()
+		expectNoEvent()
+	}
+}
+
+func TestChallengeFinalizedWithConfirmationsSimulatedBackendChainService(t *testing.T) {
+	const confirmations = 2
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	cs := NewSimulatedBackendChainServiceWithConfirmations(sim, bindings, ethAccounts[0], confirmations)
+	out := cs.SubscribeToEvents(ethAccounts[0].From)
+	expectNoEvent := func() {
+		select {
+		case event := <-out:
+			t.Fatalf("expected no events, got %+v", event)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	expectEvent := func() Event {
+		select {
+		case event := <-out:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("expected an event")
+			return nil
+		}
+	}
+
+	challengeState := state.State{
+		ChainId:           big.NewInt(1337),
+		Participants:      []types.Address{Alice.Address(), Bob.Address()},
+		ChannelNonce:      big.NewInt(37140676585),
+		AppDefinition:     bindings.ConsensusApp.Address,
+		ChallengeDuration: big.NewInt(60),
+		AppData:           []byte{},
+		Outcome:           concludeOutcome,
+		TurnNum:           uint64(1),
+	}
+	cs.Monitor(ethAccounts[0].From, challengeState.ChannelId())
+	signedChallengeState := state.NewSignedState(challengeState)
+	for _, pk := range [][]byte{Alice.PrivateKey, Bob.PrivateKey} {
+		sig, _ := challengeState.Sign(pk)
+		if err := signedChallengeState.AddSignature(sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(challengeState, Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.SendTransaction(protocols.NewChallengeTransaction(challengeState.ChannelId(), signedChallengeState, challengerSig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < confirmations; i++ {
+		sim.Commit()
+	}
+	if _, ok := expectEvent().(ChallengeRegisteredEvent); !ok {
+		t.Fatalf("expected a ChallengeRegisteredEvent")
+	}
+
+	// The challenge is only finalized once the first block mined after it expires has been confirmed
+	if err := sim.AdjustTime(time.Duration(challengeState.ChallengeDuration.Int64()) * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	expiredBlock := sim.Blockchain().CurrentBlock().NumberU64()
+	for i := 0; i < confirmations; i++ {
+		expectNoEvent()
+		sim.Commit()
+	}
+	finalized, ok := expectEvent().(ChallengeFinalizedEvent)
+	if !ok || finalized.ChannelID() != challengeState.ChannelId() || finalized.BlockNum != expiredBlock {
+		t.Fatalf("expected a ChallengeFinalizedEvent in block %d, got %+v", expiredBlock, finalized)
+	}
+}
+
+func TestCatchUpSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	channelID := types.Destination{'u'}
+	asset := common.HexToAddress("0x00")
+	err = NewSimulatedBackendChainService(sim, bindings, ethAccounts[0]).SendTransaction(protocols.NewDepositTransaction(channelID, types.Funds{asset: big.NewInt(1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	depositBlock := sim.Blockchain().CurrentBlock().NumberU64()
+
+	// A chain service started after the deposit only learns of it by catching up
+	cs := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	out := cs.SubscribeToEvents(ethAccounts[0].From)
+	cs.Monitor(ethAccounts[0].From, channelID)
+
+	cs.ReplayEvents(ethAccounts[0].From, depositBlock)
+	select {
+	case event := <-out:
+		if deposited, ok := event.(DepositedEvent); !ok || deposited.BlockNum != depositBlock || deposited.AmountDeposited.Cmp(big.NewInt(1)) != 0 {
+			t.Fatalf("expected the deposit in block %d to be replayed, got %+v", depositBlock, event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a DepositedEvent")
+	}
+
+	err = cs.RequestHoldings(channelID, []common.Address{asset})
+	if err != nil {
+		t.Fatal(err)
+	}
+	held, ok := (<-out).(DepositedEvent)
+	if !ok || held.Asset != asset || held.NowHeld.Cmp(big.NewInt(1)) != 0 || held.AmountDeposited.Sign() != 0 {
+		t.Fatalf("expected the current holdings to be reported, got %+v", held)
+	}
+}
+
+func TestERC20DepositSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	token := bindings.Token.Address
+	channelID := types.Destination{'e'}
+
+	holdings := func() *big.Int {
+		held, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, token, channelID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return held
+	}
+
+	// The token is minted to the first account, which shares it with the second, and allows the adjudicator to transfer it.
+	// These transactions are sent before the chain services are constructed, so that they do not disturb the services' nonces.
+	if _, err := bindings.Token.Contract.Transfer(ethAccounts[0], ethAccounts[1].From, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bindings.Token.Contract.Approve(ethAccounts[0], bindings.Adjudicator.Address, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	cs0 := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	cs1 := NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	cs2 := NewSimulatedBackendChainService(sim, bindings, ethAccounts[2])
+
+	// A deposit within the existing allowance is made without a further approval
+	nonce, err := sim.PendingNonceAt(context.Background(), ethAccounts[0].From)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs0.SendTransaction(protocols.NewDepositTransactionWithExpectedHeld(channelID, types.Funds{token: big.NewInt(1)}, types.Funds{token: big.NewInt(0)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := sim.PendingNonceAt(context.Background(), ethAccounts[0].From); after != nonce+1 {
+		t.Fatalf("expected a single transaction to be sent, but the nonce went from %d to %d", nonce, after)
+	}
+	if holdings().Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("expected holdings of 1, got %d", holdings())
+	}
+
+	// Without an allowance, the token is approved before the deposit is made
+	err = cs1.SendTransaction(protocols.NewDepositTransactionWithExpectedHeld(channelID, types.Funds{token: big.NewInt(2)}, types.Funds{token: big.NewInt(1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holdings().Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("expected holdings of 3, got %d", holdings())
+	}
+
+	// A deposit computed from stale holdings only tops the holdings up to its target
+	err = cs0.SendTransaction(protocols.NewDepositTransactionWithExpectedHeld(channelID, types.Funds{token: big.NewInt(2)}, types.Funds{token: big.NewInt(1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holdings().Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("expected holdings to remain at 3, got %d", holdings())
+	}
+	err = cs0.SendTransaction(protocols.NewDepositTransactionWithExpectedHeld(channelID, types.Funds{token: big.NewInt(3)}, types.Funds{token: big.NewInt(1)}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holdings().Cmp(big.NewInt(4)) != 0 {
+		t.Fatalf("expected holdings of 4, got %d", holdings())
+	}
+
+	// A deposit of tokens which the depositor does not have fails with a TokenTransferError
+	err = cs2.SendTransaction(protocols.NewDepositTransactionWithExpectedHeld(channelID, types.Funds{token: big.NewInt(1)}, types.Funds{token: big.NewInt(4)}))
+	var tte *TokenTransferError
+	if !errors.As(err, &tte) || tte.Token != token {
+		t.Fatalf("expected a TokenTransferError for token %s, got %v", token, err)
+	}
+}
+
+func TestPendingERC20DepositsSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	token := bindings.Token.Address
+	if _, err := bindings.Token.Contract.Approve(ethAccounts[0], bindings.Adjudicator.Address, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	// Blocks are only mined when the test commits them, so that a deposit is still pending when the next one is submitted
+	cs := NewEthChainService(sim, bindings.Adjudicator.Contract, bindings.Adjudicator.Address, bindings.ConsensusApp.Address, ethAccounts[0])
+	first, second := types.Destination{'f'}, types.Destination{'s'}
+	if _, err := cs.SendTransaction(protocols.NewDepositTransactionWithExpectedHeld(first, types.Funds{token: big.NewInt(6)}, types.Funds{token: big.NewInt(0)})); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first deposit has not yet used its share of the allowance, so the second deposit must be approved on top of it.
+	// The approval is waited for, so blocks are mined until the second deposit has been submitted
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				sim.Commit()
+			}
+		}
+	}()
+	_, err = cs.SendTransaction(protocols.NewDepositTransactionWithExpectedHeld(second, types.Funds{token: big.NewInt(6)}, types.Funds{token: big.NewInt(0)}))
+	close(done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	for _, channelId := range []types.Destination{first, second} {
+		held, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, token, channelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if held.Cmp(big.NewInt(6)) != 0 {
+			t.Fatalf("expected channel %s to hold 6, got %d", channelId, held)
+		}
+	}
+}
+
+func TestTransferAndClaimSimulatedBackendChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+	cs := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	out := cs.SubscribeToEvents(ethAccounts[0].From)
+	alice, bob := types.AddressToDestination(Alice.Address()), types.AddressToDestination(Bob.Address())
+
+	// A (virtual) target channel, guaranteed by two (ledger) source channels
+	target := state.State{
+		ChainId:           big.NewInt(1337),
+		Participants:      []types.Address{Alice.Address(), Bob.Address()},
+		ChannelNonce:      big.NewInt(37140676582),
+		AppDefinition:     bindings.ConsensusApp.Address,
+		ChallengeDuration: &big.Int{},
+		AppData:           []byte{},
+		Outcome: outcome.Exit{{
+			Allocations: outcome.Allocations{
+				{Destination: alice, Amount: big.NewInt(1)},
+				{Destination: bob, Amount: big.NewInt(1)},
+			},
+		}},
+		TurnNum: uint64(1),
+	}
+	guaranteeMetadata, err := outcome.GuaranteeMetadata{Left: alice, Right: bob}.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	guarantee := outcome.Allocation{Destination: target.ChannelId(), Amount: big.NewInt(2), AllocationType: outcome.GuaranteeAllocationType, Metadata: guaranteeMetadata}
+
+	// The first source is laid out like a ledger channel, with the guarantee after the ledger's participants
+	transferSource := target.Clone()
+	transferSource.ChannelNonce = big.NewInt(37140676583)
+	transferSource.Outcome = outcome.Exit{{
+		Allocations: outcome.Allocations{
+			{Destination: alice, Amount: big.NewInt(2)},
+			{Destination: bob, Amount: big.NewInt(2)},
+			guarantee,
+		},
+	}}
+	// The deployed adjudicator looks for the guarantee at targetAssetIndex rather than indexOfTargetInSource,
+	// so the second source places its guarantee first
+	claimSource := target.Clone()
+	claimSource.ChannelNonce = big.NewInt(37140676584)
+	claimSource.Outcome = outcome.Exit{{
+		Allocations: outcome.Allocations{
+			guarantee,
+			{Destination: alice, Amount: big.NewInt(2)},
+		},
+	}}
+
+	for _, s := range []state.State{transferSource, claimSource} {
+		cs.Monitor(ethAccounts[0].From, s.ChannelId())
+		err = cs.SendTransaction(protocols.NewDepositTransaction(s.ChannelId(), types.Funds{types.Address{}: s.Outcome.TotalAllocated()[types.Address{}]}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		<-out
+	}
+
+	// Every channel is finalized by a challenge which expires as soon as the next block is mined
+	for _, s := range []state.State{transferSource, claimSource, target} {
+		ss := state.NewSignedState(s)
+		for _, pk := range [][]byte{Alice.PrivateKey, Bob.PrivateKey} {
+			sig, _ := s.Sign(pk)
+			if err := ss.AddSignature(sig); err != nil {
+				t.Fatal(err)
+			}
+		}
+		challengerSig, err := NitroAdjudicator.SignChallengeMessage(s, Alice.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cs.SendTransaction(protocols.NewChallengeTransaction(s.ChannelId(), ss, challengerSig)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, ok := (<-out).(ChallengeRegisteredEvent); !ok {
+			t.Fatalf("expected a ChallengeRegisteredEvent")
+		}
+		if _, ok := (<-out).(ChallengeFinalizedEvent); !ok {
+			t.Fatalf("expected a ChallengeFinalizedEvent")
+		}
+	}
+
+	expectHoldings := func(channelId types.Destination, expected int64) {
+		t.Helper()
+		updated, ok := (<-out).(AllocationUpdatedEvent)
+		if !ok || updated.ChannelID() != channelId || updated.AssetAddress != (types.Address{}) || updated.AssetAmount.Cmp(big.NewInt(expected)) != 0 {
+			t.Fatalf("expected the holdings of %s to be updated to %d, got %+v", channelId, expected, updated)
+		}
+	}
+
+	// Transfer pays out Alice's allocation in the first source, and then Bob's from the outcome updated by the first transfer
+	err = cs.SendTransaction(protocols.NewTransferTransaction(transferSource.ChannelId(), transferSource, transferSource.Outcome, 0, []uint{0}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectHoldings(transferSource.ChannelId(), 4)
+
+	newAllocations, _ := outcome.ComputeTransferEffectsAndInteractions(*big.NewInt(6), transferSource.Outcome[0].Allocations, []uint{0})
+	updatedOutcome := transferSource.Outcome.Clone()
+	updatedOutcome[0].Allocations = newAllocations
+	err = cs.SendTransaction(protocols.NewTransferTransaction(transferSource.ChannelId(), transferSource, updatedOutcome, 0, []uint{1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectHoldings(transferSource.ChannelId(), 2)
+
+	// Claim pays out the target channel from its guarantee in the second source
+	err = cs.SendTransaction(protocols.NewClaimTransaction(claimSource, claimSource.Outcome, 0, 0, target, target.Outcome, 0, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectHoldings(claimSource.ChannelId(), 2)
+}
@@ -0,0 +1,93 @@
+package chainservice
+
+import "sync"
+
+// subscription delivers events to a subscriber in the order they were pushed.
+// Events are queued until the subscriber receives them, so a slow subscriber never causes events to be dropped.
+type subscription struct {
+	out   chan Event
+	ready chan struct{} // signals that the queue is not empty
+
+	mu    sync.Mutex
+	queue []Event
+}
+
+// newSubscription returns a subscription, and starts delivering events pushed to it.
+func newSubscription() *subscription {
+	sub := &subscription{out: make(chan Event), ready: make(chan struct{}, 1)}
+	go sub.deliver()
+	return sub
+}
+
+// push queues the event for delivery. It never blocks.
+func (sub *subscription) push(event Event) {
+	sub.mu.Lock()
+	sub.queue = append(sub.queue, event)
+	sub.mu.Unlock()
+
+	select {
+	case sub.ready <- struct{}{}:
+	default: // delivery is already pending
+	}
+}
+
+// deliver sends queued events to the subscriber, blocking until each one is received.
+func (sub *subscription) deliver() {
+	for range sub.ready {
+		for {
+			sub.mu.Lock()
+			if len(sub.queue) == 0 {
+				sub.mu.Unlock()
+				break
+			}
+			event := sub.queue[0]
+			sub.queue = sub.queue[1:]
+			sub.mu.Unlock()
+
+			sub.out <- event
+		}
+	}
+}
+
+// historyDepth is the number of blocks, counted back from the newest event's block, for which emitted events are kept for replay.
+const historyDepth = 1024
+
+// eventHistory records the events emitted by a chain service, in order.
+// Only the events of the most recent blocks are kept (see historyDepth), so that the history does not grow for the lifetime of the chain service.
+type eventHistory struct {
+	mu       sync.Mutex
+	events   []Event
+	depth    uint64 // the number of blocks for which events are kept
+	newest   uint64 // the highest block number of any recorded event
+	sequence uint64 // the sequence number of the last recorded event
+}
+
+func newEventHistory() *eventHistory {
+	return &eventHistory{depth: historyDepth}
+}
+
+// record assigns the event the next sequence number and appends it to the history, discarding the events which are now too old to be kept.
+// It returns the event with its sequence number. The caller must hold mu.
+func (h *eventHistory) record(event Event) Event {
+	h.sequence++
+	event = event.withSequence(h.sequence)
+	h.events = append(h.events, event)
+
+	if event.BlockNumber() <= h.newest {
+		return event
+	}
+	h.newest = event.BlockNumber()
+	if h.newest <= h.depth {
+		return event
+	}
+	oldest := h.newest - h.depth
+	stale := 0
+	for stale < len(h.events) && h.events[stale].BlockNumber() < oldest {
+		stale++
+	}
+	if stale > 0 {
+		// Copy the remaining events so that the stale ones can be garbage collected
+		h.events = append([]Event(nil), h.events[stale:]...)
+	}
+	return event
+}
@@ -0,0 +1,263 @@
+package chainservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var (
+	// ErrTransactionReverted is reported when a submitted transaction is mined, but reverts.
+	ErrTransactionReverted = errors.New("transaction reverted")
+	// ErrTransactionNotMined is reported when a submitted transaction is not mined, even after its fees have been raised.
+	ErrTransactionNotMined = errors.New("transaction not mined")
+)
+
+const (
+	// receiptPollInterval is how often the txManager checks whether a submitted transaction has been mined.
+	receiptPollInterval = 100 * time.Millisecond
+	// stuckAfter is how long the txManager waits for a submitted transaction to be mined before raising its fees.
+	stuckAfter = time.Minute
+	// maxFeeBumps is the number of times the txManager raises the fees of a transaction before giving up on it.
+	maxFeeBumps = 3
+)
+
+// txChain is the part of a chain node which the txManager uses.
+type txChain interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error)
+}
+
+// txManager submits the transactions of a single account, and sees them through until they are mined.
+//
+// It tracks the account's nonce locally (so that transactions can be submitted in quick succession), prices transactions
+// from the chain's current fees, and replaces transactions which are not mined promptly with copies paying higher fees.
+// Gas limits are estimated by the contract bindings.
+type txManager struct {
+	chain  txChain
+	signer *bind.TransactOpts
+
+	mu         sync.Mutex // serializes submissions, which consume nonces
+	nextNonce  uint64
+	nonceKnown bool // whether nextNonce is in sync with the chain
+
+	pollInterval time.Duration
+	stuckAfter   time.Duration
+	maxFeeBumps  int
+
+	// onFailure is called when a transaction submitted for a channel is reverted, or is never mined
+	onFailure func(channelId types.Destination, blockNum uint64, txHash common.Hash, err error)
+}
+
+// newTxManager returns a txManager which signs transactions with the supplied signer and submits them to the supplied chain.
+func newTxManager(chain txChain, signer *bind.TransactOpts, onFailure func(channelId types.Destination, blockNum uint64, txHash common.Hash, err error)) *txManager {
+	return &txManager{
+		chain:        chain,
+		signer:       signer,
+		pollInterval: receiptPollInterval,
+		stuckAfter:   stuckAfter,
+		maxFeeBumps:  maxFeeBumps,
+		onFailure:    onFailure,
+	}
+}
+
+// send submits a transaction for the channel, and watches it until it is mined.
+// The transaction is built by the supplied function, which is given transaction options carrying the next nonce and the current fees.
+// Those options prevent the function from sending the transaction itself.
+func (tm *txManager) send(channelId types.Destination, build func(*bind.TransactOpts) (*ethTypes.Transaction, error)) (*ethTypes.Transaction, error) {
+	return tm.sendThen(channelId, build, func() {})
+}
+
+// sendThen is like send, but calls done once the transaction has been mined (whether or not it reverts), or has been given up on.
+// done is not called if the transaction cannot be submitted.
+func (tm *txManager) sendThen(channelId types.Destination, build func(*bind.TransactOpts) (*ethTypes.Transaction, error), done func()) (*ethTypes.Transaction, error) {
+	ethTx, err := tm.submit(build)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		tm.watch(channelId, ethTx)
+		done()
+	}()
+	return ethTx, nil
+}
+
+// sendAndWait submits a transaction built by the supplied function (see send), and blocks until it is mined.
+// Failures are returned rather than reported with tm.onFailure.
+func (tm *txManager) sendAndWait(build func(*bind.TransactOpts) (*ethTypes.Transaction, error)) (*ethTypes.Receipt, error) {
+	ethTx, err := tm.submit(build)
+	if err != nil {
+		return nil, err
+	}
+	return tm.waitMined(ethTx)
+}
+
+// submit builds a transaction and sends it to the chain.
+func (tm *txManager) submit(build func(*bind.TransactOpts) (*ethTypes.Transaction, error)) (*ethTypes.Transaction, error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	opts, err := tm.txOpts()
+	if err != nil {
+		return nil, err
+	}
+	ethTx, err := build(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := tm.chain.SendTransaction(context.Background(), ethTx); err != nil {
+		// The nonce may have been used by another transaction, so read it from the chain before the next submission
+		tm.nonceKnown = false
+		return nil, err
+	}
+	tm.nextNonce++
+	return ethTx, nil
+}
+
+// txOpts returns transaction options carrying the next nonce and the current fees. The caller must hold tm.mu.
+func (tm *txManager) txOpts() (*bind.TransactOpts, error) {
+	if !tm.nonceKnown {
+		nonce, err := tm.chain.PendingNonceAt(context.Background(), tm.signer.From)
+		if err != nil {
+			return nil, fmt.Errorf("could not read nonce: %w", err)
+		}
+		tm.nextNonce = nonce
+		tm.nonceKnown = true
+	}
+	opts := &bind.TransactOpts{
+		From:   tm.signer.From,
+		Nonce:  new(big.Int).SetUint64(tm.nextNonce),
+		Signer: tm.signer.Signer,
+		NoSend: true,
+	}
+
+	head, err := tm.chain.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not read the latest block: %w", err)
+	}
+	if head.BaseFee == nil {
+		// The chain does not support EIP-1559 transactions
+		opts.GasPrice, err = tm.chain.SuggestGasPrice(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("could not suggest gas price: %w", err)
+		}
+		return opts, nil
+	}
+	opts.GasTipCap, err = tm.chain.SuggestGasTipCap(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("could not suggest gas tip cap: %w", err)
+	}
+	// Allow for the base fee doubling before the transaction is mined
+	opts.GasFeeCap = new(big.Int).Add(opts.GasTipCap, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	return opts, nil
+}
+
+// watch waits for the transaction to be mined. If it reverts, or is never mined, the failure is reported with tm.onFailure.
+func (tm *txManager) watch(channelId types.Destination, ethTx *ethTypes.Transaction) {
+	receipt, err := tm.waitMined(ethTx)
+	switch {
+	case err == nil:
+	case receipt != nil:
+		tm.onFailure(channelId, receipt.BlockNumber.Uint64(), receipt.TxHash, err)
+	default:
+		tm.onFailure(channelId, 0, ethTx.Hash(), err)
+	}
+}
+
+// waitMined polls for the receipt of the transaction, replacing it with a copy paying higher fees each time it has gone unmined for tm.stuckAfter.
+// It returns the receipt of whichever copy is mined. ErrTransactionReverted is returned (along with the receipt) if that copy reverts,
+// and ErrTransactionNotMined is returned if no copy is mined after tm.maxFeeBumps replacements, in which case the nonce is read from the chain before the next submission.
+func (tm *txManager) waitMined(ethTx *ethTypes.Transaction) (*ethTypes.Receipt, error) {
+	submitted := []*ethTypes.Transaction{ethTx} // the transaction, followed by its replacements. At most one of them can be mined
+	stuckAt := time.Now().Add(tm.stuckAfter)
+
+	ticker := time.NewTicker(tm.pollInterval)
+	defer ticker.Stop()
+	for {
+		for _, candidate := range submitted {
+			receipt, err := tm.chain.TransactionReceipt(context.Background(), candidate.Hash())
+			if err != nil || receipt == nil {
+				continue
+			}
+			if receipt.Status == ethTypes.ReceiptStatusFailed {
+				return receipt, ErrTransactionReverted
+			}
+			return receipt, nil
+		}
+
+		if time.Now().After(stuckAt) {
+			if len(submitted) > tm.maxFeeBumps {
+				// The abandoned nonce may never be used, which would hold up every later transaction, so read the nonce from the chain before the next submission
+				tm.mu.Lock()
+				tm.nonceKnown = false
+				tm.mu.Unlock()
+				return nil, ErrTransactionNotMined
+			}
+			replacement, err := tm.replace(submitted[len(submitted)-1])
+			if err == nil {
+				submitted = append(submitted, replacement)
+			}
+			// If the replacement was refused (for example because an earlier copy has just been mined), carry on waiting for the earlier copies
+			stuckAt = time.Now().Add(tm.stuckAfter)
+		}
+		<-ticker.C
+	}
+}
+
+// replace submits a copy of the transaction with the same nonce, paying fees which are high enough for nodes to accept it as a replacement.
+func (tm *txManager) replace(ethTx *ethTypes.Transaction) (*ethTypes.Transaction, error) {
+	var data ethTypes.TxData
+	switch ethTx.Type() {
+	case ethTypes.DynamicFeeTxType:
+		data = &ethTypes.DynamicFeeTx{
+			ChainID:    ethTx.ChainId(),
+			Nonce:      ethTx.Nonce(),
+			GasTipCap:  bumpFee(ethTx.GasTipCap()),
+			GasFeeCap:  bumpFee(ethTx.GasFeeCap()),
+			Gas:        ethTx.Gas(),
+			To:         ethTx.To(),
+			Value:      ethTx.Value(),
+			Data:       ethTx.Data(),
+			AccessList: ethTx.AccessList(),
+		}
+	default:
+		data = &ethTypes.LegacyTx{
+			Nonce:    ethTx.Nonce(),
+			GasPrice: bumpFee(ethTx.GasPrice()),
+			Gas:      ethTx.Gas(),
+			To:       ethTx.To(),
+			Value:    ethTx.Value(),
+			Data:     ethTx.Data(),
+		}
+	}
+	replacement, err := tm.signer.Signer(tm.signer.From, ethTypes.NewTx(data))
+	if err != nil {
+		return nil, fmt.Errorf("could not sign replacement transaction: %w", err)
+	}
+	if err := tm.chain.SendTransaction(context.Background(), replacement); err != nil {
+		return nil, fmt.Errorf("could not send replacement transaction: %w", err)
+	}
+	return replacement, nil
+}
+
+// bumpFee raises the fee by 12.5%, which exceeds the 10% increase nodes require of a replacement transaction.
+func bumpFee(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, big.NewInt(9))
+	bumped.Div(bumped, big.NewInt(8))
+	if bumped.Cmp(fee) == 0 {
+		bumped.Add(bumped, common.Big1) // the fee is too small to be raised by a proportion
+	}
+	return bumped
+}
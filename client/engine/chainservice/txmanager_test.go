@@ -0,0 +1,263 @@
+package chainservice
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// fakeTxChain is a txChain which records the transactions sent to it, and only mines those it is told to.
+type fakeTxChain struct {
+	mu       sync.Mutex
+	nonce    uint64
+	baseFee  *big.Int
+	tip      *big.Int
+	sendErr  error
+	sent     []*ethTypes.Transaction
+	receipts map[common.Hash]*ethTypes.Receipt
+}
+
+func newFakeTxChain() *fakeTxChain {
+	return &fakeTxChain{baseFee: big.NewInt(100), tip: big.NewInt(2), receipts: make(map[common.Hash]*ethTypes.Receipt)}
+}
+
+func (f *fakeTxChain) HeaderByNumber(ctx context.Context, number *big.Int) (*ethTypes.Header, error) {
+	return &ethTypes.Header{Number: big.NewInt(1), BaseFee: f.baseFee}, nil
+}
+
+func (f *fakeTxChain) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.nonce, nil
+}
+
+func (f *fakeTxChain) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return new(big.Int).Add(f.baseFee, f.tip), nil
+}
+
+func (f *fakeTxChain) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return f.tip, nil
+}
+
+func (f *fakeTxChain) SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.sendErr != nil {
+		return f.sendErr
+	}
+	f.sent = append(f.sent, tx)
+	return nil
+}
+
+func (f *fakeTxChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*ethTypes.Receipt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.receipts[txHash], nil
+}
+
+// mine records a receipt with the supplied status for the transaction.
+func (f *fakeTxChain) mine(tx *ethTypes.Transaction, status uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.receipts[tx.Hash()] = &ethTypes.Receipt{Status: status, BlockNumber: big.NewInt(2), TxHash: tx.Hash()}
+}
+
+// waitForSent waits until the chain has been sent n transactions, and returns them.
+func (f *fakeTxChain) waitForSent(t *testing.T, n int) []*ethTypes.Transaction {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		f.mu.Lock()
+		sent := append([]*ethTypes.Transaction{}, f.sent...)
+		f.mu.Unlock()
+		if len(sent) >= n {
+			return sent
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d transactions to be sent", n)
+	return nil
+}
+
+type txFailure struct {
+	txHash common.Hash
+	err    error
+}
+
+// newTestTxManager returns a txManager which submits to the supplied chain, and a chan receiving the failures it reports.
+func newTestTxManager(t *testing.T, chain txChain) (*txManager, <-chan txFailure) {
+	key, err := crypto.ToECDSA(Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := bind.NewKeyedTransactorWithChainID(key, big.NewInt(1337))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failures := make(chan txFailure, 10)
+	tm := newTxManager(chain, signer, func(channelId types.Destination, blockNum uint64, txHash common.Hash, err error) {
+		failures <- txFailure{txHash, err}
+	})
+	tm.pollInterval = time.Millisecond
+	return tm, failures
+}
+
+// buildTransfer builds a transaction with the supplied options, as a contract binding would.
+func buildTransfer(opts *bind.TransactOpts) (*ethTypes.Transaction, error) {
+	return opts.Signer(opts.From, ethTypes.NewTx(&ethTypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1337),
+		Nonce:     opts.Nonce.Uint64(),
+		GasTipCap: opts.GasTipCap,
+		GasFeeCap: opts.GasFeeCap,
+		Gas:       21000,
+		To:        &common.Address{},
+	}))
+}
+
+func TestTxManagerNoncesAndFees(t *testing.T) {
+	chain := newFakeTxChain()
+	chain.nonce = 5
+	tm, _ := newTestTxManager(t, chain)
+
+	// Transactions sent in quick succession use consecutive nonces
+	for _, expectedNonce := range []uint64{5, 6} {
+		ethTx, err := tm.send(types.Destination{}, buildTransfer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ethTx.Nonce() != expectedNonce {
+			t.Fatalf("expected nonce %d, got %d", expectedNonce, ethTx.Nonce())
+		}
+		if ethTx.GasTipCap().Cmp(chain.tip) != 0 || ethTx.GasFeeCap().Cmp(big.NewInt(202)) != 0 {
+			t.Fatalf("expected a tip cap of %d and a fee cap of 202, got %d and %d", chain.tip, ethTx.GasTipCap(), ethTx.GasFeeCap())
+		}
+	}
+
+	// After a failed submission, the nonce is read from the chain again
+	chain.sendErr = errors.New("nonce too low")
+	if _, err := tm.send(types.Destination{}, buildTransfer); err == nil {
+		t.Fatal("expected an error sending the transaction")
+	}
+	chain.sendErr = nil
+	chain.nonce = 9
+	ethTx, err := tm.send(types.Destination{}, buildTransfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ethTx.Nonce() != 9 {
+		t.Fatalf("expected nonce 9, got %d", ethTx.Nonce())
+	}
+}
+
+func TestTxManagerReplacesStuckTransactions(t *testing.T) {
+	chain := newFakeTxChain()
+	tm, failures := newTestTxManager(t, chain)
+	tm.stuckAfter = 10 * time.Millisecond
+
+	if _, err := tm.send(types.Destination{}, buildTransfer); err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction is not mined, so it is replaced by a copy paying higher fees
+	sent := chain.waitForSent(t, 2)
+	original, replacement := sent[0], sent[1]
+	if replacement.Nonce() != original.Nonce() {
+		t.Fatalf("expected the replacement to have nonce %d, got %d", original.Nonce(), replacement.Nonce())
+	}
+	if replacement.GasFeeCap().Cmp(original.GasFeeCap()) <= 0 || replacement.GasTipCap().Cmp(original.GasTipCap()) <= 0 {
+		t.Fatalf("expected the replacement to pay higher fees than %+v, got %+v", original, replacement)
+	}
+
+	// Once the replacement is mined, no failure is reported
+	chain.mine(replacement, ethTypes.ReceiptStatusSuccessful)
+	select {
+	case failure := <-failures:
+		t.Fatalf("expected no failures, got %+v", failure)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestTxManagerReportsFailures(t *testing.T) {
+	chain := newFakeTxChain()
+	tm, failures := newTestTxManager(t, chain)
+	tm.stuckAfter = 10 * time.Millisecond
+	tm.maxFeeBumps = 1
+
+	// A transaction which reverts is reported
+	reverted, err := tm.send(types.Destination{}, buildTransfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.mine(reverted, ethTypes.ReceiptStatusFailed)
+	failure := <-failures
+	if failure.txHash != reverted.Hash() || !errors.Is(failure.err, ErrTransactionReverted) {
+		t.Fatalf("expected %s to be reported as reverted, got %+v", reverted.Hash(), failure)
+	}
+
+	// As is a transaction which is never mined
+	if _, err := tm.send(types.Destination{}, buildTransfer); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case failure := <-failures:
+		if !errors.Is(failure.err, ErrTransactionNotMined) {
+			t.Fatalf("expected the transaction to be reported as not mined, got %+v", failure)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a failure to be reported")
+	}
+
+	// The abandoned transaction's nonce was never used, so it is read from the chain and reused
+	chain.mu.Lock()
+	chain.nonce = 1
+	chain.mu.Unlock()
+	ethTx, err := tm.send(types.Destination{}, buildTransfer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ethTx.Nonce() != 1 {
+		t.Fatalf("expected nonce 1, got %d", ethTx.Nonce())
+	}
+}
+
+func TestTxManagerWaitsForTransactions(t *testing.T) {
+	chain := newFakeTxChain()
+	tm, failures := newTestTxManager(t, chain)
+
+	type result struct {
+		receipt *ethTypes.Receipt
+		err     error
+	}
+	results := make(chan result)
+	go func() {
+		receipt, err := tm.sendAndWait(buildTransfer)
+		results <- result{receipt, err}
+	}()
+
+	sent := chain.waitForSent(t, 1)
+	select {
+	case r := <-results:
+		t.Fatalf("expected sendAndWait to block until the transaction is mined, got %+v", r)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// A reverted transaction is returned as an error, rather than reported
+	chain.mine(sent[0], ethTypes.ReceiptStatusFailed)
+	r := <-results
+	if r.receipt == nil || r.receipt.TxHash != sent[0].Hash() || !errors.Is(r.err, ErrTransactionReverted) {
+		t.Fatalf("expected %s to be returned as reverted, got %+v", sent[0].Hash(), r)
+	}
+	select {
+	case failure := <-failures:
+		t.Fatalf("expected no failures to be reported, got %+v", failure)
+	default:
+	}
+}
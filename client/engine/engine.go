@@ -6,13 +6,20 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math/big"
+	"strings"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/client/engine/chainservice"
 	"github.com/statechannels/go-nitro/client/engine/messageservice"
 	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
@@ -31,6 +38,76 @@ func (uce *ErrUnhandledChainEvent) Error() string {
 	return fmt.Sprintf("chain event %#v could not be handled by objective %#v due to: %s", uce.event, uce.objective, uce.reason)
 }
 
+// ObjectiveError is an engine error raised while handling an event for a particular objective.
+// The engine marks the objective as failed when it encounters such an error.
+type ObjectiveError struct {
+	ObjectiveId protocols.ObjectiveId
+	Err         error
+}
+
+func (oe *ObjectiveError) Error() string {
+	return fmt.Sprintf("objective %s failed: %v", oe.ObjectiveId, oe.Err)
+}
+
+func (oe *ObjectiveError) Unwrap() error {
+	return oe.Err
+}
+
+// newObjectiveError wraps err in an *ObjectiveError for the objective with the supplied id, unless err already identifies an objective.
+func newObjectiveError(id protocols.ObjectiveId, err error) error {
+	var oe *ObjectiveError
+	if errors.As(err, &oe) {
+		return err
+	}
+	return &ObjectiveError{ObjectiveId: id, Err: err}
+}
+
+// ErrObjectiveTimedOut is the reason given when the engine fails an objective which did not complete within its configured timeout.
+var ErrObjectiveTimedOut = errors.New("objective timed out")
+
+// ErrWrongChain is returned when an objective is requested, or proposed by a peer, for a channel on a chain other than the engine's.
+var ErrWrongChain = errors.New("channel is on a different chain")
+
+// ErrUnsignedMessage is returned when a message is received without a valid signature from its sender, so that the sender cannot be authenticated.
+var ErrUnsignedMessage = errors.New("message is not signed by its sender")
+
+// ErrNotParticipant is returned when a message carries a payload for an objective in which the message's sender is not a participant.
+// The payload is ignored, but the objective is not failed, since anyone can send such a payload.
+var ErrNotParticipant = errors.New("sender is not a participant")
+
+// ErrUnrelatedChannel is returned when a message carries a payload for an objective, but for a channel the objective does not run on.
+// As with ErrNotParticipant, the payload is ignored but the objective is not failed.
+var ErrUnrelatedChannel = errors.New("payload is for a channel unrelated to the objective")
+
+// ObjectiveTimeouts specifies how long objectives of each type may remain incomplete before the engine fails them.
+// Objective types are identified by their objective id prefix (for example directfund.ObjectivePrefix).
+// Objectives of a type without an entry never time out.
+type ObjectiveTimeouts map[string]time.Duration
+
+// timeoutFor returns the timeout applying to the objective with the supplied id, if there is one.
+func (ot ObjectiveTimeouts) timeoutFor(id protocols.ObjectiveId) (time.Duration, bool) {
+	for prefix, timeout := range ot {
+		if strings.HasPrefix(string(id), prefix) {
+			return timeout, true
+		}
+	}
+	return 0, false
+}
+
+// objectiveTimeoutCheckInterval is how often the engine checks for objectives which have exceeded their timeout.
+const objectiveTimeoutCheckInterval = 100 * time.Millisecond
+
+// objectiveProgress records when the engine began working on an objective, and what the objective was last waiting for.
+type objectiveProgress struct {
+	startedAt  time.Time
+	waitingFor protocols.WaitingFor
+}
+
+// errorReporter is implemented by services which report errors encountered asynchronously (for example while listening to a network), rather than crashing.
+type errorReporter interface {
+	Errors() <-chan error
+}
+
 // Engine is the imperative part of the core business logic of a go-nitro Client
 type Engine struct {
 	// inbound go channels
@@ -39,22 +116,46 @@ type Engine struct {
 	fromMsg    <-chan protocols.Message
 	fromLedger chan consensus_channel.Proposal
 
+	// errors reported asynchronously by the message and chain services (nil if the service does not report errors)
+	fromMsgErrors   <-chan error
+	fromChainErrors <-chan error
+
 	toApi chan ObjectiveChangeEvent
 
 	msg   messageservice.MessageService
 	chain chainservice.ChainService
 
-	store       store.Store // A Store for persisting and restoring important data
-	policymaker PolicyMaker // A PolicyMaker decides whether to approve or reject objectives
+	store       store.Store              // A Store for persisting and restoring important data
+	policymaker PolicyMaker              // A PolicyMaker decides whether to approve or reject objectives
+	vm          *payments.VoucherManager // A VoucherManager makes and receives payments on virtual channels
 
 	logger *log.Logger
 
 	metrics *MetricsRecorder
+
+	timeouts   ObjectiveTimeouts                           // how long objectives may remain incomplete before they are failed
+	inProgress map[protocols.ObjectiveId]objectiveProgress // the approved objectives the engine is working on
+
+	chainId *big.Int // the id of the chain the chain service submits transactions to
 }
 
 // APIEvent is an internal representation of an API call
 type APIEvent struct {
 	ObjectiveToSpawn protocols.ObjectiveRequest
+	PaymentToMake    *PaymentRequest
+	ChannelToGuard   *GuardRequest
+}
+
+// PaymentRequest is a request to pay a further amount to the payee of a virtual channel.
+type PaymentRequest struct {
+	ChannelId types.Destination
+	Amount    *big.Int
+}
+
+// GuardRequest is a request to hand the latest supported state of a channel to a watchtower, which guards the channel against stale challenges.
+type GuardRequest struct {
+	ChannelId  types.Destination
+	Watchtower types.Address
 }
 
 // ObjectiveChangeEvent is a struct that contains a list of changes caused by handling a message/chain event/api event
@@ -63,6 +164,18 @@ type ObjectiveChangeEvent struct {
 	CompletedObjectives []protocols.Objective
 	// These are objectives that have failed
 	FailedObjectives []protocols.ObjectiveId
+	// These are errors encountered while handling the event
+	Errors []error
+	// These are payment vouchers that have been received
+	ReceivedVouchers []payments.Voucher
+}
+
+// Merge accumulates the changes in other into the receiver.
+func (oce *ObjectiveChangeEvent) Merge(other ObjectiveChangeEvent) {
+	oce.CompletedObjectives = append(oce.CompletedObjectives, other.CompletedObjectives...)
+	oce.FailedObjectives = append(oce.FailedObjectives, other.FailedObjectives...)
+	oce.Errors = append(oce.Errors, other.Errors...)
+	oce.ReceivedVouchers = append(oce.ReceivedVouchers, other.ReceivedVouchers...)
 }
 
 type CompletedObjectiveEvent struct {
@@ -72,10 +185,17 @@ type CompletedObjectiveEvent struct {
 // Response is the return type that asynchronous API calls "resolve to". Such a call returns a go channel of type Response.
 type Response struct{}
 
-// NewEngine is the constructor for an Engine
-func New(msg messageservice.MessageService, chain chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker PolicyMaker, metricsApi MetricsApi) Engine {
+// NewEngine is the constructor for an Engine. If timeouts is nil, objectives never time out.
+// An error is returned if the chain id cannot be read from the chain service.
+func New(msg messageservice.MessageService, chain chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker PolicyMaker, metricsApi MetricsApi, timeouts ObjectiveTimeouts) (Engine, error) {
 	e := Engine{}
 
+	chainId, err := chain.ChainID()
+	if err != nil {
+		return Engine{}, fmt.Errorf("could not read the chain id from the chain service: %w", err)
+	}
+	e.chainId = chainId
+
 	e.store = store
 
 	// bind to inbound chans
@@ -86,6 +206,13 @@ func New(msg messageservice.MessageService, chain chainservice.ChainService, sto
 	e.chain = chain
 	e.msg = msg
 
+	if reporter, ok := msg.(errorReporter); ok {
+		e.fromMsgErrors = reporter.Errors()
+	}
+	if reporter, ok := chain.(errorReporter); ok {
+		e.fromChainErrors = reporter.Errors()
+	}
+
 	e.toApi = make(chan ObjectiveChangeEvent, 100)
 
 	// initialize a Logger
@@ -94,21 +221,40 @@ func New(msg messageservice.MessageService, chain chainservice.ChainService, sto
 
 	e.policymaker = policymaker
 
+	e.vm = payments.NewVoucherManager(*e.store.GetAddress(), e.store)
+
 	e.logger.Println("Constructed Engine")
 
 	if metricsApi == nil {
 		metricsApi = &NoOpMetrics{}
 	}
 	e.metrics = NewMetricsRecorder(*e.store.GetAddress(), metricsApi)
-	return e
+
+	e.timeouts = timeouts
+	e.inProgress = make(map[protocols.ObjectiveId]objectiveProgress)
+	return e, nil
 }
 
 func (e *Engine) ToApi() <-chan ObjectiveChangeEvent {
 	return e.toApi
 }
 
-// Run kicks of an infinite loop that waits for communications on the supplied channels, and handles them accordingly
+// Run kicks of an infinite loop that waits for communications on the supplied channels, and handles them accordingly.
+// Before entering the loop, any objectives left in progress by a previous run are resumed.
 func (e *Engine) Run() {
+	e.metrics.RecordDuration("resume_objectives", func() {
+		res, err := e.resumeObjectives()
+		e.handleResult(res, err)
+	})
+
+	// Only check for timed out objectives if some objectives can time out
+	var timeoutTicks <-chan time.Time
+	if len(e.timeouts) > 0 {
+		ticker := time.NewTicker(objectiveTimeoutCheckInterval)
+		defer ticker.Stop()
+		timeoutTicks = ticker.C
+	}
+
 	for {
 		var res ObjectiveChangeEvent
 		var err error
@@ -117,18 +263,15 @@ func (e *Engine) Run() {
 			e.metrics.RecordDuration("handle_api_event", func() {
 				e.metrics.RecordQueueLength("incoming_api_events", len(e.fromMsg))
 				res, err = e.handleAPIEvent(apiEvent)
-
-				if errors.Is(err, directdefund.ErrNotEmpty) {
-					// communicate failure to client & swallow error
-					e.toApi <- res
-					err = nil
-				}
 			})
 		case chainEvent := <-e.fromChain:
 			e.metrics.RecordQueueLength("incoming_chain_events", len(e.fromMsg))
 			e.metrics.RecordDuration("handle_chain_event", func() {
 				res, err = e.handleChainEvent(chainEvent)
 			})
+			if recordErr := e.recordBlockNum(chainEvent); recordErr != nil {
+				res.Errors = append(res.Errors, recordErr)
+			}
 		case message := <-e.fromMsg:
 			e.metrics.RecordQueueLength("incoming_messages", len(e.fromMsg))
 			e.metrics.RecordDuration("handle_message", func() {
@@ -138,25 +281,208 @@ func (e *Engine) Run() {
 			e.metrics.RecordDuration("handle_proposal", func() {
 				res, err = e.handleProposal(proposal)
 			})
+		case now := <-timeoutTicks:
+			res = e.failTimedOutObjectives(now)
+		case err = <-e.fromMsgErrors:
+		case err = <-e.fromChainErrors:
+		}
+
+		e.handleResult(res, err)
+	}
+}
+
+// handleResult handles the outcome of processing an event in the run loop.
+func (e *Engine) handleResult(res ObjectiveChangeEvent, err error) {
+	// Handle errors
+	if err != nil {
+		res.Merge(e.handleError(err))
+	}
+
+	for _, err := range res.Errors {
+		e.logger.Printf("Error in run loop: %v", err)
+	}
+
+	// Only send out an event if there are changes
+	if len(res.CompletedObjectives) > 0 || len(res.FailedObjectives) > 0 || len(res.Errors) > 0 || len(res.ReceivedVouchers) > 0 {
+		for _, obj := range res.CompletedObjectives {
+			e.logger.Printf("Objective %s is complete & returned to API", obj.Id())
+			e.metrics.RecordObjectiveCompleted(obj.Id())
+		}
+		for _, id := range res.FailedObjectives {
+			e.logger.Printf("Objective %s has failed & returned to API", id)
+			e.metrics.RecordObjectiveFailed(id)
+		}
+		e.toApi <- res
+	}
+}
+
+// handleError records the supplied error in an ObjectiveChangeEvent. If the error concerns a particular objective, that objective is failed.
+func (e *Engine) handleError(err error) ObjectiveChangeEvent {
+	res := ObjectiveChangeEvent{Errors: []error{err}}
+
+	var oe *ObjectiveError
+	if errors.As(err, &oe) {
+		res.FailedObjectives = append(res.FailedObjectives, oe.ObjectiveId)
+		if failErr := e.failObjective(oe.ObjectiveId); failErr != nil {
+			res.Errors = append(res.Errors, failErr)
+		}
+	}
+
+	return res
+}
+
+// failObjective marks the objective with the supplied id as failed in the store, and releases the channel it owns.
+// Objectives which were never stored, or which have already finished, are left untouched.
+func (e *Engine) failObjective(id protocols.ObjectiveId) error {
+	objective, err := e.store.GetObjectiveById(id)
+	if errors.Is(err, store.ErrNoSuchObjective) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not fail objective %s: %w", id, err)
+	}
+
+	if status := objective.GetStatus(); status != protocols.Unapproved && status != protocols.Approved {
+		return nil
+	}
+
+	failed := objective.Fail()
+	err = e.store.SetObjective(failed)
+	if err != nil {
+		return fmt.Errorf("could not fail objective %s: %w", id, err)
+	}
+	delete(e.inProgress, id)
+
+	if owner, ok := e.store.GetObjectiveByChannelId(failed.OwnsChannel()); ok && owner.Id() == id {
+		e.store.ReleaseChannelFromOwnership(failed.OwnsChannel())
+	}
+	return nil
+}
+
+// failTimedOutObjectives fails every objective which has been in progress for longer than the timeout for its type.
+func (e *Engine) failTimedOutObjectives(now time.Time) ObjectiveChangeEvent {
+	res := ObjectiveChangeEvent{}
+
+	for id, progress := range e.inProgress {
+		timeout, ok := e.timeouts.timeoutFor(id)
+		if !ok || now.Sub(progress.startedAt) < timeout {
+			continue
+		}
+
+		reason := fmt.Errorf("%w: not complete after %s, %s", ErrObjectiveTimedOut, timeout, progress.waitingFor)
+		res.Merge(e.handleError(&ObjectiveError{ObjectiveId: id, Err: reason}))
+		delete(e.inProgress, id)
+	}
+
+	return res
+}
+
+// isTerminal returns true if the objective can no longer make progress.
+func isTerminal(objective protocols.Objective) bool {
+	switch objective.GetStatus() {
+	case protocols.Completed, protocols.Rejected, protocols.Failed:
+		return true
+	default:
+		return false
+	}
+}
+
+// resumeObjectives picks up every approved objective which was not completed when the engine last stopped.
+// It:
+//  - reads the ledger channels and approved objectives from the store,
+//  - monitors the chain for events concerning those channels,
+//  - resends any messages the objective previously sent (counterparties may not have received them),
+//  - attempts progress on the objective,
+//  - catches up with the chain events which occurred while the engine was stopped.
+func (e *Engine) resumeObjectives() (ObjectiveChangeEvent, error) {
+	ledgers, err := e.store.GetAllConsensusChannels()
+	if err != nil {
+		return ObjectiveChangeEvent{}, fmt.Errorf("could not read ledger channels to monitor: %w", err)
+	}
+
+	objectives, err := e.store.GetApprovedObjectives()
+	if err != nil {
+		return ObjectiveChangeEvent{}, fmt.Errorf("could not read objectives to resume: %w", err)
+	}
+
+	// The channels are monitored in a single call, since each call may resubscribe to the chain
+	channelIds := make([]types.Destination, 0, len(ledgers)+len(objectives))
+	for _, ledger := range ledgers {
+		channelIds = append(channelIds, ledger.Id)
+	}
+	for _, objective := range objectives {
+		channelIds = append(channelIds, objective.OwnsChannel())
+	}
+	e.chain.Monitor(*e.store.GetAddress(), channelIds...)
+
+	allCompleted := ObjectiveChangeEvent{}
+
+	for _, objective := range objectives {
+		e.logger.Printf("Resuming objective %s", objective.Id())
+
+		if resumable, ok := objective.(protocols.Resumable); ok {
+			allCompleted.Errors = append(allCompleted.Errors, e.executeSideEffects(resumable.Resume())...)
 		}
 
-		// Handle errors
+		progressEvent, err := e.attemptProgress(objective)
 		if err != nil {
-			e.logger.Panic(fmt.Errorf("%s, error in run loop: %w", e.store.GetAddress(), err))
-			// TODO do not panic if in production.
-			// TODO report errors back to the consuming application
+			allCompleted.Merge(e.handleError(newObjectiveError(objective.Id(), err)))
+			continue
 		}
+		allCompleted.Merge(progressEvent)
+	}
 
-		// Only send out an event if there are changes
-		if len(res.CompletedObjectives) > 0 {
-			for _, obj := range res.CompletedObjectives {
-				e.logger.Printf("Objective %s is complete & returned to API", obj.Id())
-				e.metrics.RecordObjectiveCompleted(obj.Id())
-			}
-			e.toApi <- res
+	allCompleted.Errors = append(allCompleted.Errors, e.catchUpWithChain(objectives)...)
+
+	return allCompleted, nil
+}
+
+// catchUpWithChain asks the chain service to replay the events which occurred in or after the last block the engine processed,
+// and to report the current holdings of the channels which the supplied objectives are funding.
+// The last block is replayed because the engine may have stopped before processing all of its events. Objectives handle an event they have already processed without effect.
+// The replayed events are handled by the run loop like any others.
+func (e *Engine) catchUpWithChain(objectives []protocols.Objective) []error {
+	lastBlockNumSeen, err := e.store.GetLastBlockNumSeen()
+	if err != nil {
+		return []error{fmt.Errorf("could not read the last block number seen: %w", err)}
+	}
+	e.chain.ReplayEvents(*e.store.GetAddress(), lastBlockNumSeen)
+
+	errs := []error{}
+	for _, objective := range objectives {
+		dfo, ok := objective.(*directfund.Objective)
+		if !ok {
+			continue
+		}
+		assets := []common.Address{}
+		for asset := range dfo.C.PreFundState().Outcome.TotalAllocated() {
+			assets = append(assets, asset)
 		}
+		if err := e.chain.RequestHoldings(dfo.C.Id, assets); err != nil {
+			errs = append(errs, fmt.Errorf("could not request holdings for channel %s: %w", dfo.C.Id, err))
+		}
+	}
+	return errs
+}
 
+// recordBlockNum persists the block number of the chain event, so that events which occur while the engine is stopped can be replayed when it restarts.
+// If the event was reverted by a reorg, the blocks from the reverted one onwards are to be replayed.
+func (e *Engine) recordBlockNum(event chainservice.Event) error {
+	lastBlockNumSeen, err := e.store.GetLastBlockNumSeen()
+	if err != nil {
+		return fmt.Errorf("could not read the last block number seen: %w", err)
+	}
+	blockNum := event.BlockNumber()
+	if _, reverted := event.(chainservice.EventRevertedEvent); reverted {
+		if blockNum > 0 && blockNum <= lastBlockNumSeen {
+			return e.store.SetLastBlockNumSeen(blockNum - 1)
+		}
+		return nil
+	}
+	if blockNum > lastBlockNumSeen {
+		return e.store.SetLastBlockNumSeen(blockNum)
 	}
+	return nil
 }
 
 // handleProposal handles a Proposal returned to the engine from
@@ -166,9 +492,18 @@ func (e *Engine) handleProposal(proposal consensus_channel.Proposal) (ObjectiveC
 	id := getProposalObjectiveId(proposal)
 	obj, err := e.store.GetObjectiveById(id)
 	if err != nil {
-		return ObjectiveChangeEvent{}, err
+		return ObjectiveChangeEvent{}, newObjectiveError(id, err)
+	}
+	if isTerminal(obj) {
+		e.logger.Printf("Ignoring proposal for finished objective %s", obj.Id())
+		return ObjectiveChangeEvent{}, nil
+	}
+
+	res, err := e.attemptProgress(obj)
+	if err != nil {
+		return res, newObjectiveError(id, err)
 	}
-	return e.attemptProgress(obj)
+	return res, nil
 }
 
 // handleMessage handles a Message from a peer go-nitro Wallet.
@@ -177,200 +512,423 @@ func (e *Engine) handleProposal(proposal consensus_channel.Proposal) (ObjectiveC
 //  - generates an updated objective,
 //  - attempts progress on the target Objective,
 //  - attempts progress on related objectives which may have become unblocked.
+//
+// An error encountered while handling one of the message's payloads fails the objective the payload is addressed to,
+// but does not prevent the remaining payloads from being handled.
+//
+// Messages which are not signed by their sender are rejected. So are payloads for objectives in which the sender is not a participant,
+// and payloads for channels the objective does not run on.
 func (e *Engine) handleMessage(message protocols.Message) (ObjectiveChangeEvent, error) {
 
 	e.logger.Printf("Handling inbound message %+v", protocols.SummarizeMessage(message))
+	signer, err := message.Signer()
+	if err != nil {
+		return ObjectiveChangeEvent{}, fmt.Errorf("%w: message claims to be from %s: %v", ErrUnsignedMessage, message.From, err)
+	}
+	if signer != message.From {
+		return ObjectiveChangeEvent{}, fmt.Errorf("%w: message claims to be from %s, but is signed by %s", ErrUnsignedMessage, message.From, signer)
+	}
 	allCompleted := ObjectiveChangeEvent{}
 
 	for _, entry := range message.SignedStates() {
-
-		objective, err := e.getOrCreateObjective(entry.ObjectiveId, entry.Payload)
-		if err != nil {
-			return ObjectiveChangeEvent{}, err
-		}
-
-		if objective.GetStatus() == protocols.Unapproved {
-			e.logger.Printf("Policymaker is %+v", e.policymaker)
-			if e.policymaker.ShouldApprove(objective) {
-				objective = objective.Approve()
-
-				ddfo, ok := objective.(*directdefund.Objective)
-				if ok {
-					// If we just approved a direct defund objective, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
-					e.store.DestroyConsensusChannel(ddfo.C.Id)
-				}
-			} else {
-				objective = objective.Reject()
-				err = e.store.SetObjective(objective)
-				if err != nil {
-					return ObjectiveChangeEvent{}, err
-				}
-
-				allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
-				// TODO: send rejection notice
-				return allCompleted, nil
-			}
-		}
-
-		if objective.GetStatus() == protocols.Completed {
-			e.logger.Printf("Ignoring payload for complected objective  %s", objective.Id())
+		progressEvent, err := e.handleSignedState(message.From, entry)
+		if isRejectedPayload(err) {
+			allCompleted.Errors = append(allCompleted.Errors, err)
 			continue
 		}
-
-		event := protocols.ObjectiveEvent{
-			ObjectiveId:    entry.ObjectiveId,
-			SignedProposal: consensus_channel.SignedProposal{},
-			SignedState:    entry.Payload,
-		}
-		updatedObjective, err := objective.Update(event)
 		if err != nil {
-			return ObjectiveChangeEvent{}, err
+			allCompleted.Merge(e.handleError(newObjectiveError(entry.ObjectiveId, err)))
+			continue
 		}
+		allCompleted.Merge(progressEvent)
+	}
 
-		progressEvent, err := e.attemptProgress(updatedObjective)
-		if err != nil {
-			return ObjectiveChangeEvent{}, err
+	for _, entry := range message.SignedProposals() {
+		progressEvent, err := e.handleSignedProposal(message.From, entry)
+		if isRejectedPayload(err) {
+			allCompleted.Errors = append(allCompleted.Errors, err)
+			continue
 		}
-		allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, progressEvent.CompletedObjectives...)
-
 		if err != nil {
-			return ObjectiveChangeEvent{}, err
+			allCompleted.Merge(e.handleError(newObjectiveError(entry.ObjectiveId, err)))
+			continue
 		}
-
+		allCompleted.Merge(progressEvent)
 	}
 
-	for _, entry := range message.SignedProposals() {
-		e.logger.Printf("handling proposal %+v", protocols.SummarizeProposal(entry.ObjectiveId, entry.Payload))
-		objective, err := e.store.GetObjectiveById(entry.ObjectiveId)
+	for _, voucher := range message.Payments() {
+		total, err := e.vm.Receive(voucher)
 		if err != nil {
-			return ObjectiveChangeEvent{}, err
-		}
-		if objective.GetStatus() == protocols.Completed {
-			e.logger.Printf("Ignoring payload for complected objective  %s", objective.Id())
+			allCompleted.Errors = append(allCompleted.Errors, fmt.Errorf("could not receive voucher for channel %s: %w", voucher.ChannelId, err))
 			continue
 		}
+		e.logger.Printf("Received payment on channel %s, total received is now %s", voucher.ChannelId, total)
+		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, voucher)
+	}
 
-		event := protocols.ObjectiveEvent{
-			ObjectiveId:    entry.ObjectiveId,
-			SignedProposal: entry.Payload,
-			SignedState:    state.SignedState{},
-		}
-		updatedObjective, err := objective.Update(event)
-		if err != nil {
-			return ObjectiveChangeEvent{}, err
-		}
+	return allCompleted, nil
 
-		progressEvent, err := e.attemptProgress(updatedObjective)
-		if err != nil {
-			return ObjectiveChangeEvent{}, err
+}
+
+// handleSignedState handles a signed state addressed to an objective, creating the objective if it does not yet exist.
+// The sender must be a participant in the state's channel, and an existing objective must own that channel
+// (so that the sender is also a participant in the objective's channel).
+func (e *Engine) handleSignedState(from types.Address, entry protocols.ObjectivePayload[state.SignedState]) (ObjectiveChangeEvent, error) {
+	if !isParticipant(from, entry.Payload.State().Participants) {
+		return ObjectiveChangeEvent{}, fmt.Errorf("rejected state for objective %s from %s: %w", entry.ObjectiveId, from, ErrNotParticipant)
+	}
+	if existing, err := e.store.GetObjectiveById(entry.ObjectiveId); err == nil {
+		if channelId := entry.Payload.State().ChannelId(); channelId != existing.OwnsChannel() {
+			return ObjectiveChangeEvent{}, fmt.Errorf("rejected state for channel %s from %s: objective %s owns channel %s: %w", channelId, from, entry.ObjectiveId, existing.OwnsChannel(), ErrUnrelatedChannel)
 		}
+	}
+	// The state is hashed and inspected by the objective, so a malformed outcome must be caught first
+	if err := entry.Payload.State().Outcome.Validate(); err != nil {
+		return ObjectiveChangeEvent{}, fmt.Errorf("rejected state for objective %s from %s: %w", entry.ObjectiveId, from, err)
+	}
+	objective, err := e.getOrCreateObjective(entry.ObjectiveId, entry.Payload)
+	if err != nil {
+		return ObjectiveChangeEvent{}, err
+	}
 
-		allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, progressEvent.CompletedObjectives...)
+	if objective.GetStatus() == protocols.Unapproved {
+		e.logger.Printf("Policymaker is %+v", e.policymaker)
+		if e.policymaker.ShouldApprove(objective) {
+			objective = objective.Approve()
 
-		if err != nil {
-			return ObjectiveChangeEvent{}, err
+			ddfo, ok := objective.(*directdefund.Objective)
+			if ok {
+				// If we just approved a direct defund objective, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
+				e.store.DestroyConsensusChannel(ddfo.C.Id)
+			}
+		} else {
+			objective = objective.Reject()
+			err = e.store.SetObjective(objective)
+			if err != nil {
+				return ObjectiveChangeEvent{}, err
+			}
+
+			// TODO: send rejection notice
+			return ObjectiveChangeEvent{CompletedObjectives: []protocols.Objective{objective}}, nil
 		}
+	}
 
+	if isTerminal(objective) {
+		e.logger.Printf("Ignoring payload for finished objective %s", objective.Id())
+		return ObjectiveChangeEvent{}, nil
 	}
-	return allCompleted, nil
 
+	event := protocols.ObjectiveEvent{
+		ObjectiveId:    entry.ObjectiveId,
+		SignedProposal: consensus_channel.SignedProposal{},
+		SignedState:    entry.Payload,
+	}
+	updatedObjective, err := objective.Update(event)
+	if err != nil {
+		return ObjectiveChangeEvent{}, err
+	}
+
+	return e.attemptProgress(updatedObjective)
+}
+
+// handleSignedProposal handles a signed ledger proposal addressed to an existing objective.
+// The sender must be a participant in one of the objective's channels, and the proposal must be for one of the objective's ledger channels.
+func (e *Engine) handleSignedProposal(from types.Address, entry protocols.ObjectivePayload[consensus_channel.SignedProposal]) (ObjectiveChangeEvent, error) {
+	e.logger.Printf("handling proposal %+v", protocols.SummarizeProposal(entry.ObjectiveId, entry.Payload))
+	objective, err := e.store.GetObjectiveById(entry.ObjectiveId)
+	if err != nil {
+		return ObjectiveChangeEvent{}, err
+	}
+	if !isParticipant(from, participantsOf(objective)) {
+		return ObjectiveChangeEvent{}, fmt.Errorf("rejected proposal for objective %s from %s: %w", entry.ObjectiveId, from, ErrNotParticipant)
+	}
+	if ledgerId := entry.Payload.Proposal.LedgerID; !hasLedger(objective, ledgerId) {
+		return ObjectiveChangeEvent{}, fmt.Errorf("rejected proposal for ledger %s from %s: objective %s does not use it: %w", ledgerId, from, entry.ObjectiveId, ErrUnrelatedChannel)
+	}
+	if isTerminal(objective) {
+		e.logger.Printf("Ignoring payload for finished objective %s", objective.Id())
+		return ObjectiveChangeEvent{}, nil
+	}
+
+	event := protocols.ObjectiveEvent{
+		ObjectiveId:    entry.ObjectiveId,
+		SignedProposal: entry.Payload,
+		SignedState:    state.SignedState{},
+	}
+	updatedObjective, err := objective.Update(event)
+	if err != nil {
+		return ObjectiveChangeEvent{}, err
+	}
+
+	return e.attemptProgress(updatedObjective)
 }
 
 // handleChainEvent handles a Chain Event from the blockchain.
 // It:
+//  - responds to challenges registered with stale states,
 //  - reads an objective from the store,
 //  - generates an updated objective, and
 //  - attempts progress.
 func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (ObjectiveChangeEvent, error) {
 	e.logger.Printf("handling chain event %v", chainEvent)
+	if challenged, ok := chainEvent.(chainservice.ChallengeRegisteredEvent); ok {
+		responded, res, err := e.respondToChallenge(challenged)
+		if responded || err != nil {
+			return res, err
+		}
+	}
+
 	objective, ok := e.store.GetObjectiveByChannelId(chainEvent.ChannelID())
+	if failed, isFailure := chainEvent.(chainservice.TransactionFailedEvent); isFailure {
+		// The objective which submitted the transaction cannot make progress without it
+		err := fmt.Errorf("transaction %s for channel %s failed: %w", failed.TxHash, failed.ChannelID(), failed.Err)
+		if !ok {
+			return ObjectiveChangeEvent{}, err
+		}
+		return ObjectiveChangeEvent{}, newObjectiveError(objective.Id(), err)
+	}
 	if !ok {
-		// TODO: Right now the chain service returns chain events for ALL channels even those we aren't involved in
-		// for now we can ignore channels we aren't involved in
-		// in the future the chain service should allow us to register for specific channels
+		// The channel is monitored, but no objective is running on it (for example a ledger channel which is not being defunded)
 		return ObjectiveChangeEvent{}, nil
 	}
 
 	eventHandler, ok := objective.(chainservice.ChainEventHandler)
 	if !ok {
-		return ObjectiveChangeEvent{}, &ErrUnhandledChainEvent{event: chainEvent, objective: objective, reason: "objective does not handle chain events"}
+		return ObjectiveChangeEvent{}, newObjectiveError(objective.Id(), &ErrUnhandledChainEvent{event: chainEvent, objective: objective, reason: "objective does not handle chain events"})
 	}
 	updatedEventHandler, err := eventHandler.UpdateWithChainEvent(chainEvent)
 	if err != nil {
-		return ObjectiveChangeEvent{}, err
+		return ObjectiveChangeEvent{}, newObjectiveError(objective.Id(), err)
+	}
+	res, err := e.attemptProgress(updatedEventHandler)
+	if err != nil {
+		return res, newObjectiveError(objective.Id(), err)
+	}
+	return res, nil
+}
+
+// respondToChallenge checks whether a challenge was registered with a state older than the latest supported state we hold for the channel.
+// If so, it clears the challenge with a checkpoint or counters it with a challenge of its own, as decided by the policymaker.
+// It returns true if the engine responded, in which case the event should not be passed on to the objective owning the channel.
+func (e *Engine) respondToChallenge(event chainservice.ChallengeRegisteredEvent) (bool, ObjectiveChangeEvent, error) {
+	channelId := event.ChannelID()
+	if owner, ok := e.store.GetObjectiveByChannelId(channelId); ok {
+		if handler, ok := owner.(chainservice.ChallengeHandler); ok && handler.HandlesChallenges() {
+			return false, ObjectiveChangeEvent{}, nil
+		}
+	}
+
+	latest, ok := e.latestSupportedSignedState(channelId)
+	if !ok || latest.State().TurnNum <= event.TurnNumRecord {
+		return false, ObjectiveChangeEvent{}, nil
+	}
+
+	response := Checkpoint
+	if policy, ok := e.policymaker.(ChallengeResponsePolicy); ok {
+		response = policy.RespondToChallenge(event, latest)
+	}
+
+	switch response {
+	case Checkpoint:
+		e.logger.Printf("Checkpointing channel %s with turn number %d, to clear a challenge with turn number %d", channelId, latest.State().TurnNum, event.TurnNumRecord)
+		sideEffects := protocols.SideEffects{TransactionsToSubmit: []protocols.ChainTransaction{protocols.NewCheckpointTransaction(channelId, latest)}}
+		return true, ObjectiveChangeEvent{Errors: e.executeSideEffects(sideEffects)}, nil
+	case CounterChallenge:
+		e.logger.Printf("Challenging channel %s with turn number %d, to counter a challenge with turn number %d", channelId, latest.State().TurnNum, event.TurnNumRecord)
+		request := challenge.ObjectiveRequest{ChannelId: channelId}
+		res, err := e.spawnObjective(request)
+		if err != nil {
+			return true, res, newObjectiveError(request.Id(*e.store.GetAddress()), err)
+		}
+		return true, res, nil
+	default:
+		return false, ObjectiveChangeEvent{}, nil
 	}
-	return e.attemptProgress(updatedEventHandler)
+}
+
+// latestSupportedSignedState returns the latest supported state held for the channel, whether it is a ledger channel or not.
+func (e *Engine) latestSupportedSignedState(channelId types.Destination) (state.SignedState, bool) {
+	if cc, err := e.store.GetConsensusChannelById(channelId); err == nil {
+		return cc.SupportedSignedState(), true
+	}
+	if c, ok := e.store.GetChannelById(channelId); ok {
+		if ss, err := c.LatestSupportedSignedState(); err == nil {
+			return ss, true
+		}
+	}
+	return state.SignedState{}, false
 }
 
 // handleAPIEvent handles an API Event (triggered by a client API call).
 // It will attempt to perform all of the following:
 //  - Spawn a new, approved objective (if not null)
+//  - Make a payment on a virtual channel (if not null)
+//  - Hand a channel to a watchtower (if not null)
 //  - Reject an existing objective (if not null)
 //  - Approve an existing objective (if not null)
 func (e *Engine) handleAPIEvent(apiEvent APIEvent) (ObjectiveChangeEvent, error) {
+	res := ObjectiveChangeEvent{}
+
 	if apiEvent.ObjectiveToSpawn != nil {
+		id := apiEvent.ObjectiveToSpawn.Id(*e.store.GetAddress())
+		spawned, err := e.spawnObjective(apiEvent.ObjectiveToSpawn)
+		res.Merge(spawned)
+		if err != nil {
+			return res, newObjectiveError(id, err)
+		}
+	}
 
-		switch request := (apiEvent.ObjectiveToSpawn).(type) {
+	if apiEvent.PaymentToMake != nil {
+		paid, err := e.makePayment(*apiEvent.PaymentToMake)
+		res.Merge(paid)
+		if err != nil {
+			return res, err
+		}
+	}
 
-		case virtualfund.ObjectiveRequest:
-			e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
-			vfo, err := virtualfund.NewObjective(request, true, *e.store.GetAddress(), e.store.GetConsensusChannel)
-			if err != nil {
-				return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
-			}
-			return e.attemptProgress(&vfo)
+	if apiEvent.ChannelToGuard != nil {
+		guarded, err := e.guardChannel(*apiEvent.ChannelToGuard)
+		res.Merge(guarded)
+		if err != nil {
+			return res, err
+		}
+	}
 
-		case virtualdefund.ObjectiveRequest:
-			e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
-			vdfo, err := virtualdefund.NewObjective(request, true, *e.store.GetAddress(), e.store.GetChannelById, e.store.GetConsensusChannel)
-			if err != nil {
-				return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
-			}
-			return e.attemptProgress(&vdfo)
+	return res, nil
+}
 
-		case directfund.ObjectiveRequest:
-			e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
-			dfo, err := directfund.NewObjective(request, true, *e.store.GetAddress(), e.store.GetChannelsByParticipant, e.store.GetConsensusChannel)
-			if err != nil {
-				return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
-			}
-			return e.attemptProgress(&dfo)
+// makePayment signs a voucher for the requested payment and sends it to the payee of the virtual channel.
+func (e *Engine) makePayment(request PaymentRequest) (ObjectiveChangeEvent, error) {
+	if owner, ok := e.store.GetObjectiveByChannelId(request.ChannelId); ok && virtualdefund.IsVirtualDefundObjective(owner.Id()) {
+		return ObjectiveChangeEvent{}, fmt.Errorf("could not pay on channel %s: channel is being defunded", request.ChannelId)
+	}
 
-		case directdefund.ObjectiveRequest:
-			e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
-			ddfo, err := directdefund.NewObjective(request, true, e.store.GetConsensusChannelById)
-			if err != nil {
-				return ObjectiveChangeEvent{FailedObjectives: []protocols.ObjectiveId{request.Id(*e.store.GetAddress())}}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
-			}
-			// If ddfo creation was successful, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
-			e.store.DestroyConsensusChannel(request.ChannelId)
-			return e.attemptProgress(&ddfo)
+	voucher, err := e.vm.Pay(request.ChannelId, request.Amount, *e.store.GetChannelSecretKey())
+	if err != nil {
+		return ObjectiveChangeEvent{}, fmt.Errorf("could not pay on channel %s: %w", request.ChannelId, err)
+	}
+	payee, err := e.vm.Payee(request.ChannelId)
+	if err != nil {
+		return ObjectiveChangeEvent{}, fmt.Errorf("could not pay on channel %s: %w", request.ChannelId, err)
+	}
 
-		default:
-			return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Unknown objective type %T", request)
-		}
+	e.logger.Printf("Paying %s on channel %s, total paid is now %s", request.Amount, request.ChannelId, voucher.Amount)
+	sideEffects := protocols.SideEffects{MessagesToSend: []protocols.Message{protocols.CreateVoucherMessage(payee, voucher)}}
+	return ObjectiveChangeEvent{Errors: e.executeSideEffects(sideEffects)}, nil
+}
 
+// guardChannel sends the latest supported state of the requested channel to the watchtower.
+func (e *Engine) guardChannel(request GuardRequest) (ObjectiveChangeEvent, error) {
+	latest, ok := e.latestSupportedSignedState(request.ChannelId)
+	if !ok {
+		return ObjectiveChangeEvent{}, fmt.Errorf("could not guard channel %s: no supported state", request.ChannelId)
 	}
 
-	return ObjectiveChangeEvent{}, nil
+	e.logger.Printf("Handing channel %s with turn number %d to watchtower %s", request.ChannelId, latest.State().TurnNum, request.Watchtower)
+	sideEffects := protocols.SideEffects{MessagesToSend: []protocols.Message{protocols.CreateWatchMessage(request.Watchtower, latest)}}
+	return ObjectiveChangeEvent{Errors: e.executeSideEffects(sideEffects)}, nil
+}
+
+// spawnObjective creates a new, approved objective from the supplied request and attempts progress on it.
+func (e *Engine) spawnObjective(objectiveRequest protocols.ObjectiveRequest) (ObjectiveChangeEvent, error) {
+	switch request := (objectiveRequest).(type) {
+
+	case virtualfund.ObjectiveRequest:
+		if err := e.checkChainId(request.ChainId); err != nil {
+			return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
+		}
+		e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
+		vfo, err := virtualfund.NewObjective(request, true, *e.store.GetAddress(), e.store.GetConsensusChannel)
+		if err != nil {
+			return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
+		}
+		return e.attemptProgress(&vfo)
+
+	case virtualdefund.ObjectiveRequest:
+		e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
+		vdfo, err := virtualdefund.NewObjective(request, true, *e.store.GetAddress(), e.paidOnChannel(request.ChannelId), e.store.GetChannelById, e.store.GetConsensusChannel)
+		if err != nil {
+			return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
+		}
+		return e.attemptProgress(&vdfo)
+
+	case directfund.ObjectiveRequest:
+		if err := e.checkChainId(request.ChainId); err != nil {
+			return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
+		}
+		e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
+		dfo, err := directfund.NewObjective(request, true, *e.store.GetAddress(), e.store.GetChannelsByParticipant, e.store.GetConsensusChannel, e.chain.GetHoldings)
+		if err != nil {
+			return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
+		}
+		return e.attemptProgress(&dfo)
 
+	case directdefund.ObjectiveRequest:
+		e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
+		ddfo, err := directdefund.NewObjective(request, true, e.store.GetConsensusChannelById)
+		if err != nil {
+			return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
+		}
+		// If ddfo creation was successful, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
+		e.store.DestroyConsensusChannel(request.ChannelId)
+		return e.attemptProgress(&ddfo)
+
+	case challenge.ObjectiveRequest:
+		e.metrics.RecordObjectiveStarted(request.Id(*e.store.GetAddress()))
+		co, err := challenge.NewObjective(request, true, e.store.GetChannelById, e.store.GetConsensusChannelById)
+		if err != nil {
+			return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Could not create objective for %+v: %w", request, err)
+		}
+		res := ObjectiveChangeEvent{}
+		// A challenge supersedes any objective which is stalled on the channel (for example a directdefund.Objective awaiting a counterparty's signature)
+		if owner, ok := e.store.GetObjectiveByChannelId(request.ChannelId); ok {
+			err = e.failObjective(owner.Id())
+			if err != nil {
+				return res, err
+			}
+			res.FailedObjectives = append(res.FailedObjectives, owner.Id())
+		}
+		// The Channel now takes over governance from the consensus channel, if there is one
+		e.store.DestroyConsensusChannel(request.ChannelId)
+		progress, err := e.attemptProgress(&co)
+		res.Merge(progress)
+		return res, err
+
+	default:
+		return ObjectiveChangeEvent{}, fmt.Errorf("handleAPIEvent: Unknown objective type %T", request)
+	}
 }
 
-// executeSideEffects executes the SideEffects declared by cranking an Objective
-func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) {
+// executeSideEffects executes the SideEffects declared by cranking an Objective.
+// Any errors encountered while sending messages or transactions are returned, but do not prevent the remaining side effects from being executed.
+func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) []error {
+	errs := []error{}
 	for _, message := range sideEffects.MessagesToSend {
 
 		e.logger.Printf("Sending message %+v", protocols.SummarizeMessage(message))
-		e.msg.Send(message)
+		signed, err := message.Sign(*e.store.GetChannelSecretKey())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not sign message to %s: %w", message.To, err))
+			continue
+		}
+		err = e.msg.Send(signed)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
 		e.metrics.RecordOutgoingMessage(message)
 	}
 	for _, tx := range sideEffects.TransactionsToSubmit {
 		e.logger.Printf("Sending chain transaction for channel %s", tx.ChannelId())
-		e.chain.SendTransaction(tx)
+		err := e.chain.SendTransaction(tx)
+		if err != nil {
+			errs = append(errs, err)
+		}
 	}
 	for _, proposal := range sideEffects.ProposalsToProcess {
 		e.fromLedger <- proposal
 	}
+	return errs
 }
 
 // attemptProgress takes a "live" objective in memory and performs the following actions:
@@ -402,6 +960,10 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Object
 	}
 
 	e.logger.Printf("Objective %s is %s", objective.Id(), waitingFor)
+	e.recordProgress(crankedObjective, waitingFor)
+
+	// Monitor the channel before any transactions are submitted, so that the resulting chain events are received
+	e.chain.Monitor(*e.store.GetAddress(), crankedObjective.OwnsChannel())
 
 	// If our protocol is waiting for nothing then we know the objective is complete
 	// TODO: If attemptProgress is called on a completed objective CompletedObjectives would include that objective id
@@ -409,15 +971,44 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Object
 	if waitingFor == "WaitingForNothing" {
 		outgoing.CompletedObjectives = append(outgoing.CompletedObjectives, crankedObjective)
 		e.store.ReleaseChannelFromOwnership(crankedObjective.OwnsChannel())
+		e.unmonitorIfClosed(crankedObjective)
 		err = e.spawnConsensusChannelIfDirectFundObjective(crankedObjective) // Here we assume that every directfund.Objective is for a ledger channel.
 		if err != nil {
 			return
 		}
+		err = e.updatePaymentChannels(crankedObjective)
+		if err != nil {
+			return
+		}
 	}
-	e.executeSideEffects(sideEffects)
+	outgoing.Errors = append(outgoing.Errors, e.executeSideEffects(sideEffects)...)
 	return
 }
 
+// unmonitorIfClosed stops monitoring the channel owned by the completed objective, if the objective closed the channel.
+func (e *Engine) unmonitorIfClosed(completed protocols.Objective) {
+	id := completed.Id()
+	if directdefund.IsDirectDefundObjective(id) || virtualdefund.IsVirtualDefundObjective(id) || challenge.IsChallengeObjective(id) {
+		e.chain.Unmonitor(*e.store.GetAddress(), completed.OwnsChannel())
+	}
+}
+
+// recordProgress keeps track of when the engine began working on the supplied objective, so that it can be failed if it does not complete in time.
+func (e *Engine) recordProgress(objective protocols.Objective, waitingFor protocols.WaitingFor) {
+	id := objective.Id()
+	if isTerminal(objective) {
+		delete(e.inProgress, id)
+		return
+	}
+
+	progress, ok := e.inProgress[id]
+	if !ok {
+		progress.startedAt = time.Now()
+	}
+	progress.waitingFor = waitingFor
+	e.inProgress[id] = progress
+}
+
 // spawnConsensusChannelIfDirectFundObjective will attempt to create and store a ConsensusChannel derived from the supplied Objective if it is a directfund.Objective.
 //
 // The associated Channel will remain in the store.
@@ -437,6 +1028,53 @@ func (e Engine) spawnConsensusChannelIfDirectFundObjective(crankedObjective prot
 	return nil
 }
 
+// updatePaymentChannels keeps the VoucherManager's record of virtual channels up to date with the supplied completed Objective:
+//  - once a virtual channel is funded, the payer and payee start tracking payments on it,
+//  - once a virtual channel is defunded, payments on it are no longer tracked.
+func (e *Engine) updatePaymentChannels(crankedObjective protocols.Objective) error {
+	switch o := crankedObjective.(type) {
+	case *virtualfund.Objective:
+		payer := o.V.Participants[0]
+		payee := o.V.Participants[len(o.V.Participants)-1]
+		me := *e.store.GetAddress()
+		if (me != payer && me != payee) || e.vm.ChannelRegistered(o.V.Id) {
+			return nil
+		}
+		// Vouchers do not name an asset, so payments are only supported on channels holding a single asset
+		if len(o.V.PostFundState().Outcome) != 1 {
+			e.logger.Printf("Not tracking payments on channel %s, which holds more than one asset", o.V.Id)
+			return nil
+		}
+		startingBalance := o.V.PostFundState().Outcome[0].Allocations[0].Amount
+		err := e.vm.Register(o.V.Id, payer, payee, startingBalance)
+		if err != nil {
+			return fmt.Errorf("could not register channel %s for payments: %w", o.V.Id, err)
+		}
+	case *virtualdefund.Objective:
+		if !e.vm.ChannelRegistered(o.VId()) {
+			return nil
+		}
+		err := e.vm.Remove(o.VId())
+		if err != nil {
+			return fmt.Errorf("could not stop tracking payments on channel %s: %w", o.VId(), err)
+		}
+	}
+	return nil
+}
+
+// paidOnChannel returns the total paid on the virtual channel, according to the vouchers made or received by the calling client.
+// The payer and payee of a channel on which payments are not tracked have paid nothing. An intermediary does not know what has been paid, in which case nil is returned.
+func (e *Engine) paidOnChannel(channelId types.Destination) *big.Int {
+	if paid, err := e.vm.Paid(channelId); err == nil {
+		return paid
+	}
+	c, ok := e.store.GetChannelById(channelId)
+	if !ok || (c.MyIndex != 0 && c.MyIndex != uint(len(c.Participants)-1)) {
+		return nil
+	}
+	return big.NewInt(0)
+}
+
 // getOrCreateObjective retrieves the objective from the store. if the objective does not exist, it creates the objective using the supplied signed state, and stores it in the store
 func (e *Engine) getOrCreateObjective(id protocols.ObjectiveId, ss state.SignedState) (protocols.Objective, error) {
 
@@ -464,11 +1102,15 @@ func (e *Engine) getOrCreateObjective(id protocols.ObjectiveId, ss state.SignedS
 }
 
 // constructObjectiveFromMessage Constructs a new objective (of the appropriate concrete type) from the supplied message.
+// States for a chain other than the engine's are rejected, since they could not be enforced on chain.
 func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, ss state.SignedState) (protocols.Objective, error) {
+	if err := e.checkChainId(ss.State().ChainId); err != nil {
+		return nil, fmt.Errorf("could not create objective %s from message: %w", id, err)
+	}
 
 	switch {
 	case directfund.IsDirectFundObjective(id):
-		dfo, err := directfund.ConstructFromState(false, ss.State(), *e.store.GetAddress())
+		dfo, err := directfund.ConstructFromState(false, ss.State(), *e.store.GetAddress(), e.chain.GetHoldings)
 
 		return &dfo, err
 	case virtualfund.IsVirtualFundObjective(id):
@@ -478,7 +1120,7 @@ func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, ss stat
 		}
 		return &vfo, nil
 	case virtualdefund.IsVirtualDefundObjective(id):
-		vdfo, err := virtualdefund.ConstructObjectiveFromState(ss.State(), false, *e.store.GetAddress(), e.store.GetChannelById, e.store.GetConsensusChannel)
+		vdfo, err := virtualdefund.ConstructObjectiveFromState(ss.State(), false, *e.store.GetAddress(), e.store.GetChannelById, e.store.GetConsensusChannel, e.paidOnChannel(ss.State().ChannelId()))
 		if err != nil {
 			return &virtualfund.Objective{}, fmt.Errorf("could not create virtual fund objective from message: %w", err)
 		}
@@ -524,3 +1166,55 @@ func getProposalObjectiveId(p consensus_channel.Proposal) protocols.ObjectiveId
 func (e *Engine) GetConsensusAppAddress() types.Address {
 	return e.chain.GetConsensusAppAddress()
 }
+
+// GetChainId returns the id of the chain the engine's chain service submits transactions to.
+func (e *Engine) GetChainId() *big.Int {
+	return new(big.Int).Set(e.chainId)
+}
+
+// checkChainId returns an error wrapping ErrWrongChain unless the supplied chain id is the engine's.
+func (e *Engine) checkChainId(chainId *big.Int) error {
+	if chainId == nil || chainId.Cmp(e.chainId) != 0 {
+		return fmt.Errorf("%w: expected chain id %s, got %v", ErrWrongChain, e.chainId, chainId)
+	}
+	return nil
+}
+
+// participantsOf returns the participants of the channels the objective relates to.
+func participantsOf(objective protocols.Objective) []types.Address {
+	participants := []types.Address{}
+	for _, related := range objective.Related() {
+		switch c := related.(type) {
+		case *channel.Channel:
+			participants = append(participants, c.Participants...)
+		case *consensus_channel.ConsensusChannel:
+			participants = append(participants, c.Participants()...)
+		}
+	}
+	return participants
+}
+
+// hasLedger returns true if the consensus channel with the given id is among the channels the objective relates to.
+func hasLedger(objective protocols.Objective, ledgerId types.Destination) bool {
+	for _, related := range objective.Related() {
+		if c, ok := related.(*consensus_channel.ConsensusChannel); ok && c.Id == ledgerId {
+			return true
+		}
+	}
+	return false
+}
+
+// isRejectedPayload returns true if the error rejects a payload without implicating the objective it is addressed to.
+func isRejectedPayload(err error) bool {
+	return errors.Is(err, ErrNotParticipant) || errors.Is(err, ErrUnrelatedChannel)
+}
+
+// isParticipant returns true if the address is among the participants.
+func isParticipant(address types.Address, participants []types.Address) bool {
+	for _, p := range participants {
+		if p == address {
+			return true
+		}
+	}
+	return false
+}
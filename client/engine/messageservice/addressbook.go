@@ -0,0 +1,199 @@
+package messageservice
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// peerPrefix namespaces the peer records held in an AddressBook's database.
+const peerPrefix = "peer/"
+
+var (
+	// ErrNoHello is returned when a message without a hello is registered with an AddressBook.
+	ErrNoHello = errors.New("message carries no hello")
+	// ErrUnsignedHello is returned when a hello is not signed by its sender, so that the url it announces cannot be trusted.
+	ErrUnsignedHello = errors.New("hello is not signed by its sender")
+	// ErrMisaddressedHello is returned when a hello is addressed to someone other than the owner of an AddressBook,
+	// for example because a peer the hello was sent to has replayed it.
+	ErrMisaddressedHello = errors.New("hello is addressed to someone else")
+	// ErrStaleHello is returned when a hello is superseded by a hello already registered for the same peer.
+	ErrStaleHello = errors.New("hello is older than the registered hello")
+)
+
+// PeerLookup returns the url of a peer unknown to an AddressBook (for example by querying a directory), and false if it cannot be found.
+type PeerLookup func(types.Address) (string, bool)
+
+// peerRecord is the url a peer listens on, along with the time of the hello which announced it (zero if the peer was registered directly).
+type peerRecord struct {
+	Url      string
+	IssuedAt uint64
+}
+
+// AddressBook records the urls on which peers' message services listen, for message services to consult when sending messages.
+//
+// Peers are registered directly, or dynamically from the signed hellos they send to the AddressBook's owner (see SendHello).
+// If a PeerLookup is set, it is consulted for peers the AddressBook does not know, and the urls it finds are remembered.
+// An AddressBook constructed with NewDurableAddressBook persists the peers it learns of, so that they are known after a restart.
+type AddressBook struct {
+	me     types.Address // the owner of the AddressBook, to whom the hellos it registers must be addressed
+	mu     sync.Mutex
+	peers  map[types.Address]peerRecord
+	lookup PeerLookup
+
+	db *leveldb.DB // the database peers are persisted to, or nil if they are held in memory only
+}
+
+// NewAddressBook returns an AddressBook owned by me which knows the supplied peers, and holds the peers registered with it in memory.
+func NewAddressBook(me types.Address, peers map[types.Address]string) *AddressBook {
+	ab := &AddressBook{me: me, peers: make(map[types.Address]peerRecord)}
+	for address, url := range peers {
+		ab.peers[address] = peerRecord{Url: url}
+	}
+	return ab
+}
+
+// NewDurableAddressBook opens (or creates) an AddressBook owned by me, which persists the peers registered with it to a database in the supplied folder.
+func NewDurableAddressBook(me types.Address, folder string) (*AddressBook, error) {
+	db, err := leveldb.OpenFile(folder, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database in %s: %w", folder, err)
+	}
+
+	ab := &AddressBook{me: me, peers: make(map[types.Address]peerRecord), db: db}
+	iter := db.NewIterator(util.BytesPrefix([]byte(peerPrefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		var record peerRecord
+		if err := json.Unmarshal(iter.Value(), &record); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("could not read peer %s: %w", iter.Key(), err)
+		}
+		ab.peers[common.HexToAddress(string(iter.Key()[len(peerPrefix):]))] = record
+	}
+	if err := iter.Error(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return ab, nil
+}
+
+// Close closes the underlying database, if there is one. The AddressBook should not be used after Close is called.
+func (ab *AddressBook) Close() error {
+	if ab.db == nil {
+		return nil
+	}
+	return ab.db.Close()
+}
+
+// SetLookup sets the PeerLookup consulted for peers the AddressBook does not know.
+func (ab *AddressBook) SetLookup(lookup PeerLookup) {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	ab.lookup = lookup
+}
+
+// Lookup returns the url of the peer, and false if it is unknown to the AddressBook and its PeerLookup.
+func (ab *AddressBook) Lookup(address types.Address) (string, bool) {
+	ab.mu.Lock()
+	record, ok := ab.peers[address]
+	lookup := ab.lookup
+	ab.mu.Unlock()
+	if ok {
+		return record.Url, true
+	}
+	if lookup == nil {
+		return "", false
+	}
+
+	url, ok := lookup(address)
+	if !ok {
+		return "", false
+	}
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if record, ok := ab.peers[address]; ok {
+		return record.Url, true // the peer was registered while the lookup ran
+	}
+	record = peerRecord{Url: url}
+	if err := ab.set(address, record); err != nil {
+		ab.peers[address] = record // the url can still be used, but will be looked up again after a restart
+	}
+	return url, true
+}
+
+// Peers returns the url of each peer known to the AddressBook.
+func (ab *AddressBook) Peers() map[types.Address]string {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	peers := make(map[types.Address]string, len(ab.peers))
+	for address, record := range ab.peers {
+		peers[address] = record.Url
+	}
+	return peers
+}
+
+// Register records the url of the peer, replacing any url already known for it.
+func (ab *AddressBook) Register(address types.Address, url string) error {
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	return ab.set(address, peerRecord{Url: url})
+}
+
+// RegisterHello records the url announced by the hello the message carries. The message must be signed by its sender and addressed to
+// the AddressBook's owner, and the hello must be newer than any hello already registered for the sender.
+func (ab *AddressBook) RegisterHello(msg protocols.Message) error {
+	hello, ok := msg.Hello()
+	if !ok {
+		return ErrNoHello
+	}
+	if signer, err := msg.Signer(); err != nil || signer != msg.From {
+		return fmt.Errorf("%w: hello claims to be from %s", ErrUnsignedHello, msg.From)
+	}
+	if msg.To != ab.me {
+		return fmt.Errorf("%w: hello from %s is addressed to %s", ErrMisaddressedHello, msg.From, msg.To)
+	}
+
+	ab.mu.Lock()
+	defer ab.mu.Unlock()
+	if known, ok := ab.peers[msg.From]; ok && known.IssuedAt >= hello.IssuedAt {
+		if known.Url == hello.Url {
+			return nil
+		}
+		return fmt.Errorf("%w: hello from %s was issued at %d, but a hello issued at %d is registered", ErrStaleHello, msg.From, hello.IssuedAt, known.IssuedAt)
+	}
+	return ab.set(msg.From, peerRecord{Url: hello.Url, IssuedAt: hello.IssuedAt})
+}
+
+// set records the peer, persisting it if the AddressBook has a database. The caller must hold ab.mu.
+func (ab *AddressBook) set(address types.Address, record peerRecord) error {
+	if ab.db != nil {
+		recordJSON, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		if err := ab.db.Put([]byte(peerPrefix+address.String()), recordJSON, nil); err != nil {
+			return fmt.Errorf("could not persist peer %s: %w", address, err)
+		}
+	}
+	ab.peers[address] = record
+	return nil
+}
+
+// SendHello sends the peer a hello signed with the secret key, announcing that our message service listens on myUrl.
+// Once the peer registers the hello with its AddressBook, it can send us messages without being configured with our url.
+func SendHello(ms MessageService, to types.Address, myUrl string, secretKey []byte) error {
+	hello, err := protocols.CreateHelloMessage(to, myUrl, uint64(time.Now().UnixMilli())).Sign(secretKey)
+	if err != nil {
+		return err
+	}
+	return ms.Send(hello)
+}
@@ -0,0 +1,138 @@
+package messageservice
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// expectUrl fails the test unless the address book returns the given url for the peer
+func expectUrl(t *testing.T, ab *AddressBook, peer types.Address, want string) {
+	t.Helper()
+	got, ok := ab.Lookup(peer)
+	if !ok || got != want {
+		t.Fatalf("expected url %q for %s, got %q (found: %t)", want, peer, got, ok)
+	}
+}
+
+// signedHello returns a hello for bob, signed by the sender
+func signedHello(t *testing.T, from testactors.Actor, url string, issuedAt uint64) protocols.Message {
+	t.Helper()
+	hello, err := protocols.CreateHelloMessage(testactors.Bob.Address(), url, issuedAt).Sign(from.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hello
+}
+
+func TestAddressBookRegistersHellos(t *testing.T) {
+	alice := testactors.Alice
+	ab := NewAddressBook(testactors.Bob.Address(), map[types.Address]string{alice.Address(): "localhost:1000"})
+	expectUrl(t, ab, alice.Address(), "localhost:1000")
+
+	// A hello supersedes a direct registration, and is superseded by later hellos
+	if err := ab.RegisterHello(signedHello(t, alice, "localhost:2000", 2)); err != nil {
+		t.Fatal(err)
+	}
+	expectUrl(t, ab, alice.Address(), "localhost:2000")
+	if err := ab.RegisterHello(signedHello(t, alice, "localhost:3000", 3)); err != nil {
+		t.Fatal(err)
+	}
+	expectUrl(t, ab, alice.Address(), "localhost:3000")
+
+	// A replayed hello is ignored
+	if err := ab.RegisterHello(signedHello(t, alice, "localhost:2000", 2)); !errors.Is(err, ErrStaleHello) {
+		t.Fatalf("expected %v, got %v", ErrStaleHello, err)
+	}
+	if err := ab.RegisterHello(signedHello(t, alice, "localhost:3000", 3)); err != nil {
+		t.Fatalf("expected a repeated hello to be accepted, got %v", err)
+	}
+	expectUrl(t, ab, alice.Address(), "localhost:3000")
+
+	// An unsigned hello cannot be trusted
+	unsigned := protocols.CreateHelloMessage(testactors.Bob.Address(), "localhost:6666", 4)
+	unsigned.From = alice.Address()
+	if err := ab.RegisterHello(unsigned); !errors.Is(err, ErrUnsignedHello) {
+		t.Fatalf("expected %v, got %v", ErrUnsignedHello, err)
+	}
+	expectUrl(t, ab, alice.Address(), "localhost:3000")
+
+	// A hello alice sent to someone else cannot be replayed to bob
+	misaddressed, err := protocols.CreateHelloMessage(testactors.Irene.Address(), "localhost:6666", 5).Sign(alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ab.RegisterHello(misaddressed); !errors.Is(err, ErrMisaddressedHello) {
+		t.Fatalf("expected %v, got %v", ErrMisaddressedHello, err)
+	}
+	expectUrl(t, ab, alice.Address(), "localhost:3000")
+
+	if err := ab.RegisterHello(protocols.CreateVoucherMessage(testactors.Bob.Address())); !errors.Is(err, ErrNoHello) {
+		t.Fatalf("expected %v, got %v", ErrNoHello, err)
+	}
+}
+
+func TestAddressBookLookup(t *testing.T) {
+	alice, irene := testactors.Alice.Address(), testactors.Irene.Address()
+	ab := NewAddressBook(testactors.Bob.Address(), nil)
+	if _, ok := ab.Lookup(alice); ok {
+		t.Fatalf("expected alice to be unknown")
+	}
+
+	lookups := 0
+	ab.SetLookup(func(a types.Address) (string, bool) {
+		lookups++
+		if a == alice {
+			return "localhost:1000", true
+		}
+		return "", false
+	})
+	expectUrl(t, ab, alice, "localhost:1000")
+	expectUrl(t, ab, alice, "localhost:1000")
+	if lookups != 1 {
+		t.Errorf("expected the url found by the lookup to be remembered, but the lookup ran %d times", lookups)
+	}
+	if _, ok := ab.Lookup(irene); ok {
+		t.Errorf("expected irene to be unknown")
+	}
+}
+
+func TestDurableAddressBook(t *testing.T) {
+	alice, irene, brian := testactors.Alice, testactors.Irene, testactors.Brian
+	folder := t.TempDir()
+
+	ab, err := NewDurableAddressBook(testactors.Bob.Address(), folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab.SetLookup(func(a types.Address) (string, bool) {
+		return "localhost:3000", a == brian.Address()
+	})
+	expectUrl(t, ab, brian.Address(), "localhost:3000")
+	if err := ab.Register(irene.Address(), "localhost:1000"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ab.RegisterHello(signedHello(t, alice, "localhost:2000", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ab.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewDurableAddressBook(testactors.Bob.Address(), folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	expectUrl(t, reopened, irene.Address(), "localhost:1000")
+	expectUrl(t, reopened, alice.Address(), "localhost:2000")
+	expectUrl(t, reopened, brian.Address(), "localhost:3000") // found by the lookup, which the reopened AddressBook does not have
+
+	// The time of the persisted hello is remembered, so an older hello is still ignored
+	if err := reopened.RegisterHello(signedHello(t, alice, "localhost:6666", 1)); !errors.Is(err, ErrStaleHello) {
+		t.Fatalf("expected %v, got %v", ErrStaleHello, err)
+	}
+}
@@ -1,11 +1,34 @@
 // Package messageservice is a messaging service responsible for routing messages to peers and relaying messages received from peers.
 package messageservice // import "github.com/statechannels/go-nitro/client/messageservice"
 
-import "github.com/statechannels/go-nitro/protocols"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrUnknownPeer is returned when a message is addressed to a peer the message service cannot reach.
+var ErrUnknownPeer = errors.New("no connection to peer")
+
+// SendError is returned when a message service fails to send a message to a peer.
+type SendError struct {
+	To  types.Address
+	Err error
+}
+
+func (se *SendError) Error() string {
+	return fmt.Sprintf("could not send message to %s: %v", se.To, se.Err)
+}
+
+func (se *SendError) Unwrap() error {
+	return se.Err
+}
 
 type MessageService interface {
 	// Out returns a chan for receiving messages from the message service
 	Out() <-chan protocols.Message
-	// Send is for sending messages with the message service
-	Send(protocols.Message)
+	// Send is for sending messages with the message service. A *SendError is returned if the message could not be sent.
+	Send(protocols.Message) error
 }
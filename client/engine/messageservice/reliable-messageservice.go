@@ -0,0 +1,377 @@
+package messageservice
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// minRetryInterval is how long a message waits for an acknowledgement before it is first resent
+	minRetryInterval = 250 * time.Millisecond
+	// maxRetryInterval caps the exponential backoff between resends of a message
+	maxRetryInterval = 10 * time.Second
+	// flushInterval is how often the outbox is checked for messages which are due to be resent
+	flushInterval = 50 * time.Millisecond
+	// maxSeenPerPeer is the number of message ids remembered for each peer in order to discard duplicates
+	maxSeenPerPeer = 10000
+	// maxUnacknowledgedPerPeer is the number of messages kept for each peer until they are acknowledged. Once reached, the oldest message is dropped.
+	maxUnacknowledgedPerPeer = 1000
+	// maxUnacknowledgedAge is how long a message is resent for before it is dropped
+	maxUnacknowledgedAge = 10 * time.Minute
+)
+
+var (
+	// ErrOutboxFull is reported when a message is dropped to make room for newer messages to the same peer.
+	ErrOutboxFull = errors.New("too many unacknowledged messages to peer")
+	// ErrMessageExpired is reported when a message is dropped because it was not acknowledged in time.
+	ErrMessageExpired = errors.New("message was not acknowledged in time")
+)
+
+// ReliableMessageService wraps a MessageService so that messages survive being lost in transit.
+//
+// Each message sent is given an id, signed, and kept in an outbox until the recipient acknowledges it, being resent with
+// exponential backoff in the meantime. Each message received is acknowledged, and passed on to the engine unless
+// a message with the same id has already been received from the same peer. Acknowledgements are batched, and
+// piggyback on messages to the peer where possible. The outbox holds a bounded number of messages for each peer,
+// for a bounded time: messages dropped from it are reported on the Errors chan as *SendErrors.
+//
+// Both peers must wrap their message service: messages received without delivery information are passed on unchanged,
+// but are neither acknowledged nor deduplicated. Delivery information is only trusted if the message is signed by its sender,
+// so messages which are not are passed on unchanged too. Ids are only remembered in memory, so a message may be delivered again
+// after the recipient restarts.
+type ReliableMessageService struct {
+	me        types.Address
+	secretKey []byte // signs the messages sent, so that recipients can trust their delivery information
+	inner     MessageService
+
+	out      chan protocols.Message // for sending message to engine
+	errorOut chan error             // for reporting errors encountered while sending messages
+
+	session string // distinguishes the ids of messages sent by this instance from those sent before a restart
+
+	mu          sync.Mutex
+	nextSeq     uint64
+	outbox      map[string]*outboxEntry        // the messages sent but not yet acknowledged, keyed by id
+	queued      map[types.Address][]string     // the ids of the messages in the outbox for each peer, oldest first. Acknowledged ids are removed lazily.
+	pendingAcks map[types.Address][]string     // the ids of messages received from each peer which are yet to be acknowledged
+	seen        map[types.Address]*recentIdSet // the ids of messages recently received from each peer
+
+	maxPerPeer int           // the number of messages kept in the outbox for each peer
+	maxAge     time.Duration // how long a message is kept in the outbox
+
+	wake chan struct{}  // wake prompts the sender to flush pending acknowledgements
+	quit chan struct{}  // quit is used to signal the goroutines to stop
+	wg   sync.WaitGroup // wg tracks the running goroutines, so that Close can wait for them to stop
+}
+
+// outboxEntry is a message awaiting acknowledgement
+type outboxEntry struct {
+	msg         protocols.Message
+	expiresAt   time.Time
+	nextAttempt time.Time
+	backoff     time.Duration
+}
+
+// recentIdSet is a set of ids of bounded size. Once full, the oldest id is evicted to make room for a new one.
+type recentIdSet struct {
+	ids   map[string]struct{}
+	order []string
+}
+
+// add inserts the id into the set, and returns false if it was already present.
+func (s *recentIdSet) add(id string) bool {
+	if _, ok := s.ids[id]; ok {
+		return false
+	}
+	if len(s.order) == maxSeenPerPeer {
+		delete(s.ids, s.order[0])
+		s.order = s.order[1:]
+	}
+	s.ids[id] = struct{}{}
+	s.order = append(s.order, id)
+	return true
+}
+
+// NewReliableMessageService returns a running ReliableMessageService which sends and receives messages using inner,
+// for the address of the supplied secret key. Messages are signed with the secret key before they are sent.
+// The ReliableMessageService takes over receiving from inner's Out chan; inner should not be used directly afterwards.
+func NewReliableMessageService(secretKey []byte, inner MessageService) *ReliableMessageService {
+	session := make([]byte, 8)
+	if _, err := rand.Read(session); err != nil {
+		panic(err)
+	}
+	s := &ReliableMessageService{
+		me:        crypto.GetAddressFromSecretKeyBytes(secretKey),
+		secretKey: secretKey,
+		inner:     inner,
+		out:       make(chan protocols.Message, 5),
+		errorOut:  make(chan error, 5),
+		session:   hex.EncodeToString(session),
+
+		outbox:      make(map[string]*outboxEntry),
+		queued:      make(map[types.Address][]string),
+		pendingAcks: make(map[types.Address][]string),
+		seen:        make(map[types.Address]*recentIdSet),
+
+		maxPerPeer: maxUnacknowledgedPerPeer,
+		maxAge:     maxUnacknowledgedAge,
+
+		wake: make(chan struct{}, 1),
+		quit: make(chan struct{}),
+	}
+
+	s.wg.Add(2)
+	go s.receive()
+	go s.resend()
+	if reporter, ok := inner.(interface{ Errors() <-chan error }); ok {
+		s.wg.Add(1)
+		go s.forwardErrors(reporter.Errors())
+	}
+
+	return s
+}
+
+// Send gives the message an id, signs it, and sends it using the inner message service. The message is resent until the recipient acknowledges it,
+// or until it is dropped from the outbox. A *SendError is returned if the message cannot be signed, or if the recipient is unknown to the inner
+// message service. Other errors from the inner message service are reported on the Errors chan, since the message will be resent.
+func (s *ReliableMessageService) Send(msg protocols.Message) error {
+	s.mu.Lock()
+	id := fmt.Sprintf("%s-%d", s.session, s.nextSeq)
+	s.nextSeq++
+	acks := s.pendingAcks[msg.To]
+	signed, err := msg.WithDelivery(protocols.DeliveryInfo{Id: id, Acks: acks}).Sign(s.secretKey)
+	if err != nil {
+		s.mu.Unlock()
+		return &SendError{To: msg.To, Err: fmt.Errorf("could not sign message: %w", err)}
+	}
+	msg = signed
+	delete(s.pendingAcks, msg.To)
+	now := time.Now()
+	s.outbox[id] = &outboxEntry{msg: msg, expiresAt: now.Add(s.maxAge), nextAttempt: now.Add(minRetryInterval), backoff: minRetryInterval}
+	dropped := s.enqueue(msg.To, id)
+	s.mu.Unlock()
+
+	for _, d := range dropped {
+		s.reportErrorIfRunning(&SendError{To: d.To, Err: ErrOutboxFull})
+	}
+
+	err = s.inner.Send(msg)
+	if errors.Is(err, ErrUnknownPeer) {
+		s.mu.Lock()
+		delete(s.outbox, id)
+		s.mu.Unlock()
+		return err
+	}
+	if err != nil {
+		s.reportErrorIfRunning(err)
+	}
+	return nil
+}
+
+// enqueue records that the message with the given id is in the outbox for the peer. If the peer then has more than maxPerPeer messages
+// in the outbox, the oldest are removed from it and returned. The caller must hold mu.
+func (s *ReliableMessageService) enqueue(peer types.Address, id string) []protocols.Message {
+	queue := make([]string, 0, len(s.queued[peer])+1)
+	for _, queuedId := range append(s.queued[peer], id) {
+		if _, ok := s.outbox[queuedId]; ok {
+			queue = append(queue, queuedId)
+		}
+	}
+
+	dropped := []protocols.Message{}
+	for len(queue) > s.maxPerPeer {
+		dropped = append(dropped, s.outbox[queue[0]].msg)
+		delete(s.outbox, queue[0])
+		queue = queue[1:]
+	}
+	s.queued[peer] = queue
+	return dropped
+}
+
+// receive handles the messages received by the inner message service: it records acknowledgements, acknowledges
+// messages, and passes new messages on to the engine
+func (s *ReliableMessageService) receive() {
+	defer s.wg.Done()
+	for {
+		var msg protocols.Message
+		select {
+		case <-s.quit:
+			return
+		case msg = <-s.inner.Out():
+		}
+
+		if s.isDuplicate(msg) {
+			continue
+		}
+		select {
+		case s.out <- msg:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// isDuplicate processes the delivery information attached to the message, if the message is signed by its sender.
+// It returns true if the message should not be passed on to the engine, either because it has already been received or because it only carries acknowledgements.
+func (s *ReliableMessageService) isDuplicate(msg protocols.Message) bool {
+	d, ok := msg.Delivery()
+	if !ok {
+		return false
+	}
+	if signer, err := msg.Signer(); err != nil || signer != msg.From {
+		// The delivery information may have been forged
+		return false
+	}
+	from := msg.From
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, ack := range d.Acks {
+		// Only the recipient of a message can acknowledge it
+		if entry, ok := s.outbox[ack]; ok && entry.msg.To == from {
+			delete(s.outbox, ack)
+		}
+	}
+	if d.Id == "" {
+		return msg.IsEmpty()
+	}
+
+	// Duplicates are acknowledged too, since the acknowledgement of the original may have been lost
+	s.pendingAcks[from] = append(s.pendingAcks[from], d.Id)
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	seen, ok := s.seen[from]
+	if !ok {
+		seen = &recentIdSet{ids: make(map[string]struct{})}
+		s.seen[from] = seen
+	}
+	return !seen.add(d.Id)
+}
+
+// resend periodically sends pending acknowledgements, and resends the messages which are due to be resent
+func (s *ReliableMessageService) resend() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+
+		due, expired := s.due(time.Now())
+		for _, msg := range expired {
+			s.reportErrorIfRunning(&SendError{To: msg.To, Err: ErrMessageExpired})
+		}
+		for _, msg := range due {
+			if err := s.inner.Send(msg); err != nil {
+				s.reportErrorIfRunning(err)
+			}
+		}
+	}
+}
+
+// due returns signed messages carrying the pending acknowledgements, along with the messages which are due to be resent at the given time.
+// The acknowledgements are removed from the pending list, and the messages are scheduled to be resent again after their backoff.
+// Messages which have expired are removed from the outbox and returned separately.
+func (s *ReliableMessageService) due(now time.Time) ([]protocols.Message, []protocols.Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := make([]protocols.Message, 0)
+	expired := make([]protocols.Message, 0)
+	for peer, acks := range s.pendingAcks {
+		ack, err := protocols.Message{To: peer}.WithDelivery(protocols.DeliveryInfo{Acks: acks}).Sign(s.secretKey)
+		if err != nil {
+			continue // the acknowledgements are sent with the next message to the peer instead
+		}
+		msgs = append(msgs, ack)
+		delete(s.pendingAcks, peer)
+	}
+	for id, entry := range s.outbox {
+		if !now.Before(entry.expiresAt) {
+			expired = append(expired, entry.msg)
+			delete(s.outbox, id)
+			continue
+		}
+		if now.Before(entry.nextAttempt) {
+			continue
+		}
+		entry.backoff *= 2
+		if entry.backoff > maxRetryInterval {
+			entry.backoff = maxRetryInterval
+		}
+		entry.nextAttempt = now.Add(entry.backoff)
+		msgs = append(msgs, entry.msg)
+	}
+	return msgs, expired
+}
+
+// forwardErrors passes on the errors reported by the inner message service
+func (s *ReliableMessageService) forwardErrors(errs <-chan error) {
+	defer s.wg.Done()
+	for {
+		select {
+		case <-s.quit:
+			return
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			s.reportErrorIfRunning(err)
+		}
+	}
+}
+
+// reportErrorIfRunning reports the error on the Errors chan if the ReliableMessageService is running, otherwise it just returns.
+// Errors are dropped if the chan is full.
+func (s *ReliableMessageService) reportErrorIfRunning(err error) {
+	select {
+	case <-s.quit: // If we are quitting we can ignore the error
+		return
+	default:
+	}
+
+	select {
+	case s.errorOut <- err:
+	default:
+	}
+}
+
+// Unacknowledged returns the number of messages sent which are yet to be acknowledged by their recipients.
+func (s *ReliableMessageService) Unacknowledged() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.outbox)
+}
+
+func (s *ReliableMessageService) Out() <-chan protocols.Message {
+	return s.out
+}
+
+// Errors returns a chan for receiving errors encountered while sending messages, including those reported by the inner message service
+func (s *ReliableMessageService) Errors() <-chan error {
+	return s.errorOut
+}
+
+// Close stops the ReliableMessageService, and waits for its goroutines to stop. Unacknowledged messages are discarded.
+// The inner message service is not closed.
+func (s *ReliableMessageService) Close() {
+	close(s.quit)
+	s.wg.Wait()
+}
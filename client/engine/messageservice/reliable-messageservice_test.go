@@ -0,0 +1,263 @@
+package messageservice
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// lossyMessageService wraps a MessageService, silently dropping the messages for which drop returns true
+type lossyMessageService struct {
+	MessageService
+
+	mu   sync.Mutex
+	drop func(protocols.Message) bool
+}
+
+func (l *lossyMessageService) Send(msg protocols.Message) error {
+	l.mu.Lock()
+	drop := l.drop != nil && l.drop(msg)
+	l.mu.Unlock()
+	if drop {
+		return nil
+	}
+	return l.MessageService.Send(msg)
+}
+
+func (l *lossyMessageService) setDrop(drop func(protocols.Message) bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.drop = drop
+}
+
+// isAck returns true if the message only carries acknowledgements
+func isAck(msg protocols.Message) bool {
+	d, ok := msg.Delivery()
+	return ok && d.Id == ""
+}
+
+// newReliablePair returns reliable message services for alice and bob, each wrapping a lossy message service
+func newReliablePair() (alice, bob types.Address, aliceLossy, bobLossy *lossyMessageService, aliceMS, bobMS *ReliableMessageService) {
+	return newReliablePairWithBroker(NewBroker())
+}
+
+// newReliablePairWithBroker returns reliable message services for alice and bob, connected by the supplied broker
+func newReliablePairWithBroker(broker Broker) (alice, bob types.Address, aliceLossy, bobLossy *lossyMessageService, aliceMS, bobMS *ReliableMessageService) {
+	alice, bob = testactors.Alice.Address(), testactors.Bob.Address()
+	aliceLossy = &lossyMessageService{MessageService: NewTestMessageService(alice, broker, 0)}
+	bobLossy = &lossyMessageService{MessageService: NewTestMessageService(bob, broker, 0)}
+	return alice, bob, aliceLossy, bobLossy, NewReliableMessageService(testactors.Alice.PrivateKey, aliceLossy), NewReliableMessageService(testactors.Bob.PrivateKey, bobLossy)
+}
+
+// expectSendError fails the test unless the message service reports a *SendError wrapping target
+func expectSendError(t *testing.T, ms *ReliableMessageService, target error) {
+	t.Helper()
+	select {
+	case err := <-ms.Errors():
+		var se *SendError
+		if !errors.As(err, &se) || !errors.Is(err, target) {
+			t.Fatalf("expected a *SendError wrapping %v, got %v", target, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected an error wrapping %v", target)
+	}
+}
+
+func proposalWithTurnNum(to types.Address, turnNum uint64) protocols.Message {
+	return protocols.CreateSignedProposalMessage(to, consensus_channel.SignedProposal{
+		Proposal: consensus_channel.Proposal{LedgerID: types.Destination{1}},
+		TurnNum:  turnNum,
+	})
+}
+
+// expectTurnNum fails the test unless the next message received by the message service has the given turn number
+func expectTurnNum(t *testing.T, ms *ReliableMessageService, turnNum uint64) {
+	t.Helper()
+	select {
+	case m := <-ms.Out():
+		if got := m.SignedProposals()[0].Payload.TurnNum; got != turnNum {
+			t.Fatalf("expected message %d, got %d", turnNum, got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected to receive message %d", turnNum)
+	}
+}
+
+// expectNothing fails the test if the message service receives a message within the given duration
+func expectNothing(t *testing.T, ms *ReliableMessageService, d time.Duration) {
+	t.Helper()
+	select {
+	case m := <-ms.Out():
+		t.Fatalf("expected no more messages, got %+v", m)
+	case <-time.After(d):
+	}
+}
+
+// waitForAcknowledgements fails the test unless every message sent by the message service is acknowledged in time
+func waitForAcknowledgements(t *testing.T, ms *ReliableMessageService) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for ms.Unacknowledged() > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected every message to be acknowledged, but %d are not", ms.Unacknowledged())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReliableMessageServiceRetriesLostMessages(t *testing.T) {
+	_, bob, aliceLossy, _, aliceMS, bobMS := newReliablePair()
+	defer aliceMS.Close()
+	defer bobMS.Close()
+
+	// Alice's first three attempts to send are lost
+	dropped := 0
+	aliceLossy.setDrop(func(protocols.Message) bool {
+		dropped++
+		return dropped <= 3
+	})
+
+	if err := aliceMS.Send(proposalWithTurnNum(bob, 1)); err != nil {
+		t.Fatal(err)
+	}
+	expectTurnNum(t, bobMS, 1)
+	waitForAcknowledgements(t, aliceMS)
+	expectNothing(t, bobMS, 2*minRetryInterval)
+}
+
+func TestReliableMessageServiceDiscardsDuplicates(t *testing.T) {
+	_, bob, _, bobLossy, aliceMS, bobMS := newReliablePair()
+	defer aliceMS.Close()
+	defer bobMS.Close()
+
+	// Bob's acknowledgements are lost, so alice resends her messages
+	bobLossy.setDrop(isAck)
+
+	for i := uint64(1); i <= 3; i++ {
+		if err := aliceMS.Send(proposalWithTurnNum(bob, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := uint64(1); i <= 3; i++ {
+		expectTurnNum(t, bobMS, i)
+	}
+	expectNothing(t, bobMS, 4*minRetryInterval)
+	if got := aliceMS.Unacknowledged(); got != 3 {
+		t.Fatalf("expected 3 unacknowledged messages, got %d", got)
+	}
+
+	// Once bob's acknowledgements get through, alice stops resending
+	bobLossy.setDrop(nil)
+	waitForAcknowledgements(t, aliceMS)
+}
+
+func TestReliableMessageServiceUnknownPeer(t *testing.T) {
+	_, _, _, _, aliceMS, bobMS := newReliablePair()
+	defer aliceMS.Close()
+	defer bobMS.Close()
+
+	err := aliceMS.Send(proposalWithTurnNum(testactors.Irene.Address(), 1))
+	if !errors.Is(err, ErrUnknownPeer) {
+		t.Fatalf("expected %v, got %v", ErrUnknownPeer, err)
+	}
+	if got := aliceMS.Unacknowledged(); got != 0 {
+		t.Fatalf("expected a message to an unknown peer not to be resent, but %d messages are unacknowledged", got)
+	}
+}
+
+func TestReliableMessageServiceIgnoresForgedAcknowledgements(t *testing.T) {
+	broker := NewBroker()
+	alice, bob, aliceLossy, _, aliceMS, bobMS := newReliablePairWithBroker(broker)
+	defer aliceMS.Close()
+	defer bobMS.Close()
+
+	// Bob never receives alice's message
+	aliceLossy.setDrop(func(protocols.Message) bool { return true })
+	if err := aliceMS.Send(proposalWithTurnNum(bob, 1)); err != nil {
+		t.Fatal(err)
+	}
+	aliceMS.mu.Lock()
+	ids := []string{}
+	for id := range aliceMS.outbox {
+		ids = append(ids, id)
+	}
+	aliceMS.mu.Unlock()
+
+	// Mallory acknowledges the message on bob's behalf, with and without signing
+	mallory := NewTestMessageService(testactors.Brian.Address(), broker, 0)
+	unsigned := protocols.Message{To: alice, From: bob}.WithDelivery(protocols.DeliveryInfo{Acks: ids})
+	signed, err := protocols.Message{To: alice}.WithDelivery(protocols.DeliveryInfo{Acks: ids}).Sign(testactors.Brian.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, forged := range []protocols.Message{unsigned, signed} {
+		if err := mallory.Send(forged); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(2 * flushInterval)
+	if got := aliceMS.Unacknowledged(); got != 1 {
+		t.Fatalf("expected alice's message to remain unacknowledged, but %d messages are", got)
+	}
+}
+
+func TestReliableMessageServiceDropsOldestMessagesToUnresponsivePeer(t *testing.T) {
+	_, bob, aliceLossy, _, aliceMS, bobMS := newReliablePair()
+	defer aliceMS.Close()
+	defer bobMS.Close()
+	aliceMS.maxPerPeer = 2
+
+	// Bob never receives alice's messages
+	aliceLossy.setDrop(func(protocols.Message) bool { return true })
+	for i := uint64(1); i <= 3; i++ {
+		if err := aliceMS.Send(proposalWithTurnNum(bob, i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expectSendError(t, aliceMS, ErrOutboxFull)
+	if got := aliceMS.Unacknowledged(); got != 2 {
+		t.Fatalf("expected 2 unacknowledged messages, got %d", got)
+	}
+
+	// The newest messages are delivered once bob is reachable
+	aliceLossy.setDrop(nil)
+	expectTurnNum(t, bobMS, 2)
+	expectTurnNum(t, bobMS, 3)
+	waitForAcknowledgements(t, aliceMS)
+}
+
+func TestReliableMessageServiceExpiresMessages(t *testing.T) {
+	_, bob, aliceLossy, _, aliceMS, bobMS := newReliablePair()
+	defer aliceMS.Close()
+	defer bobMS.Close()
+	aliceMS.maxAge = 2 * minRetryInterval
+
+	// Bob never receives alice's message
+	aliceLossy.setDrop(func(protocols.Message) bool { return true })
+	if err := aliceMS.Send(proposalWithTurnNum(bob, 1)); err != nil {
+		t.Fatal(err)
+	}
+	expectSendError(t, aliceMS, ErrMessageExpired)
+	if got := aliceMS.Unacknowledged(); got != 0 {
+		t.Fatalf("expected the expired message to be dropped, but %d messages are unacknowledged", got)
+	}
+}
+
+func TestReliableMessageServicePassesOnMessagesWithoutDeliveryInfo(t *testing.T) {
+	alice, bob := testactors.Alice.Address(), testactors.Bob.Address()
+	broker := NewBroker()
+	aliceMS := NewTestMessageService(alice, broker, 0)
+	bobMS := NewReliableMessageService(testactors.Bob.PrivateKey, NewTestMessageService(bob, broker, 0))
+	defer bobMS.Close()
+
+	if err := aliceMS.Send(proposalWithTurnNum(bob, 1)); err != nil {
+		t.Fatal(err)
+	}
+	expectTurnNum(t, bobMS, 1)
+}
@@ -2,9 +2,11 @@ package simpletcp
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"net"
 
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -14,11 +16,13 @@ const (
 	DELIMETER = '\n'
 )
 
-// SimpleTCPMessageService is a rudimentary message service that uses TCP to send and receive messages
+// SimpleTCPMessageService is a rudimentary message service that uses TCP to send and receive messages.
+// It consults an AddressBook for the urls of peers, and registers the hellos it receives from peers with the AddressBook.
 type SimpleTCPMessageService struct {
-	out chan protocols.Message // for sending message to engine
+	out      chan protocols.Message // for sending message to engine
+	errorOut chan error             // for reporting errors encountered while receiving messages
 
-	peers map[types.Address]string
+	book *messageservice.AddressBook
 
 	listener net.Listener // The listener for incoming connections on our port
 
@@ -26,16 +30,22 @@ type SimpleTCPMessageService struct {
 
 }
 
-// NewTestMessageService returns a running SimpleTcpMessageService listening on the given url
-func NewSimpleTCPMessageService(myUrl string, peers map[types.Address]string) *SimpleTCPMessageService {
+// NewTestMessageService returns a running SimpleTcpMessageService for the given address, listening on the given url
+func NewSimpleTCPMessageService(me types.Address, myUrl string, peers map[types.Address]string) *SimpleTCPMessageService {
+	return NewSimpleTCPMessageServiceWithAddressBook(myUrl, messageservice.NewAddressBook(me, peers))
+}
+
+// NewSimpleTCPMessageServiceWithAddressBook returns a running SimpleTcpMessageService listening on the given url, which sends messages to the peers in the AddressBook
+func NewSimpleTCPMessageServiceWithAddressBook(myUrl string, book *messageservice.AddressBook) *SimpleTCPMessageService {
 
 	l, err := net.Listen(CONN_TYPE, myUrl)
 	if err != nil {
 		panic(err)
 	}
 	h := &SimpleTCPMessageService{
-		out:   make(chan protocols.Message, 5),
-		peers: peers,
+		out:      make(chan protocols.Message, 5),
+		errorOut: make(chan error, 5),
+		book:     book,
 
 		listener: l,
 		quit:     make(chan struct{}),
@@ -48,35 +58,33 @@ func NewSimpleTCPMessageService(myUrl string, peers map[types.Address]string) *S
 }
 
 // Send dispatches messages
-func (s *SimpleTCPMessageService) Send(msg protocols.Message) {
-	peer, ok := s.peers[msg.To]
+func (s *SimpleTCPMessageService) Send(msg protocols.Message) error {
+	peer, ok := s.book.Lookup(msg.To)
 
 	if !ok {
-		panic(fmt.Errorf("no peer port for %s", msg.To))
+		return &messageservice.SendError{To: msg.To, Err: messageservice.ErrUnknownPeer}
 	}
 
 	raw, err := msg.Serialize()
+	if err != nil {
+		return &messageservice.SendError{To: msg.To, Err: fmt.Errorf("could not serialize message: %w", err)}
+	}
 
 	// Append the delimiter to the message to indicate the end of the message
 	raw = fmt.Sprintf("%s%c", raw, DELIMETER)
 
-	if err != nil {
-		s.panicIfRunning(err)
-		return
-	}
-
 	conn, err := net.Dial(CONN_TYPE, peer)
 	if err != nil {
-		s.panicIfRunning(err)
-		return
+		return &messageservice.SendError{To: msg.To, Err: err}
 	}
+	defer conn.Close()
+
 	_, err = conn.Write([]byte(raw))
 	if err != nil {
-		s.panicIfRunning(err)
-		return
+		return &messageservice.SendError{To: msg.To, Err: err}
 	}
-	conn.Close()
 
+	return nil
 }
 
 // listenForIncoming listens for any incoming messages from other peers
@@ -85,21 +93,29 @@ func (s *SimpleTCPMessageService) listenForIncoming() {
 		conn, err := s.listener.Accept()
 
 		if err != nil {
-			s.panicIfRunning(err)
-			return
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.reportErrorIfRunning(err)
+			continue
 		}
 
 		raw, err := bufio.NewReader(conn).ReadString(DELIMETER)
+		conn.Close()
 
 		if err != nil {
-			s.panicIfRunning(err)
-			return
-
+			s.reportErrorIfRunning(fmt.Errorf("could not read message from %s: %w", conn.RemoteAddr(), err))
+			continue
 		}
 		m, err := protocols.DeserializeMessage(raw)
 		if err != nil {
-			s.panicIfRunning(err)
-			return
+			s.reportErrorIfRunning(fmt.Errorf("could not deserialize message from %s: %w", conn.RemoteAddr(), err))
+			continue
+		}
+		if _, ok := m.Hello(); ok {
+			if err := s.book.RegisterHello(m); err != nil {
+				s.reportErrorIfRunning(fmt.Errorf("could not register hello from %s: %w", conn.RemoteAddr(), err))
+			}
 		}
 		s.out <- m
 
@@ -107,20 +123,35 @@ func (s *SimpleTCPMessageService) listenForIncoming() {
 
 }
 
-// panicIfRunning panics if the SimpleTCPMessageService is running, otherwise it just returns
-func (s *SimpleTCPMessageService) panicIfRunning(err error) {
+// reportErrorIfRunning reports the error on the Errors chan if the SimpleTCPMessageService is running, otherwise it just returns.
+// Errors are dropped if the chan is full.
+func (s *SimpleTCPMessageService) reportErrorIfRunning(err error) {
 	select {
 	case <-s.quit: // If we are quitting we can ignore the error
 		return
 	default:
-		panic(err)
 	}
+
+	select {
+	case s.errorOut <- err:
+	default:
+	}
+}
+
+// AddressBook returns the AddressBook the SimpleTCPMessageService consults for the urls of peers
+func (s *SimpleTCPMessageService) AddressBook() *messageservice.AddressBook {
+	return s.book
 }
 
 func (s *SimpleTCPMessageService) Out() <-chan protocols.Message {
 	return s.out
 }
 
+// Errors returns a chan for receiving errors encountered while receiving messages from peers
+func (s *SimpleTCPMessageService) Errors() <-chan error {
+	return s.errorOut
+}
+
 // Close closes the SimpleTCPMessageService
 func (s *SimpleTCPMessageService) Close() {
 	close(s.quit)
@@ -0,0 +1,247 @@
+package tcp
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// handshakeTimeout bounds the time a handshake may take, so that a silent peer cannot hold a connection open
+	handshakeTimeout = 5 * time.Second
+
+	addressLength   = 20
+	publicKeyLength = 65 // an uncompressed secp256k1 public key
+	helloLength     = addressLength + publicKeyLength
+	signatureLength = 65
+
+	// handshakeDomain is hashed into the handshake transcript, so that the signatures exchanged cannot be mistaken for signatures over anything else
+	handshakeDomain = "go-nitro tcp handshake v1"
+)
+
+var (
+	// ErrHandshakeFailed is returned when a peer does not complete a handshake, or cannot prove control of the address it claims.
+	ErrHandshakeFailed = errors.New("handshake failed")
+	// ErrUnexpectedPeer is returned when a dialled peer authenticates as a different address than the one being sent messages.
+	ErrUnexpectedPeer = errors.New("peer authenticated as an unexpected address")
+	// ErrSenderMismatch is reported when a message received over an authenticated connection claims to be from someone other than the authenticated peer.
+	ErrSenderMismatch = errors.New("message sender does not match the authenticated peer")
+)
+
+// Roles distinguish the signatures made by each side of a handshake, so that a signature cannot be reflected back to the peer which made it.
+const (
+	initiatorRole byte = 'i'
+	responderRole byte = 'r'
+)
+
+// session encrypts the frames sent over a connection once a handshake has authenticated the peer.
+// Connections only carry messages from the initiator to the responder, so a session only encrypts in that direction.
+type session struct {
+	peer  types.Address // the authenticated address of the peer
+	aead  cipher.AEAD
+	nonce uint64 // the number of frames sealed or opened so far, used as the nonce of the next frame
+}
+
+// seal encrypts the payload. Frames must be opened in the order they are sealed.
+func (s *session) seal(payload []byte) []byte {
+	return s.aead.Seal(nil, s.nextNonce(), payload, nil)
+}
+
+// open decrypts the frame. An error is returned if it was not sealed by the peer, or is out of order.
+func (s *session) open(frame []byte) ([]byte, error) {
+	return s.aead.Open(nil, s.nextNonce(), frame, nil)
+}
+
+func (s *session) nextNonce() []byte {
+	nonce := make([]byte, s.aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], s.nonce)
+	s.nonce++
+	return nonce
+}
+
+// initiateHandshake authenticates us to the peer we dialled and the peer to us, and agrees a key for encrypting the messages we send.
+//
+// The handshake runs as follows, where a hello is an address followed by a fresh ephemeral public key:
+//   - the initiator sends its hello
+//   - the responder sends its hello, and its signature over the transcript of both hellos
+//   - the initiator sends its signature over the transcript
+//
+// The signatures prove that each side controls the secret key of the address in its hello, and bind the ephemeral keys to those addresses.
+// The key used to encrypt messages is derived from the ephemeral keys by ECDH, so it is unknown to anyone observing the connection.
+func initiateHandshake(conn net.Conn, secretKey []byte, expected types.Address) (*session, error) {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	ephemeral, myHello, err := newHello(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFrame(conn, myHello); err != nil {
+		return nil, err
+	}
+
+	peerHello, err := readFixedFrame(conn, helloLength)
+	if err != nil {
+		return nil, err
+	}
+	peer, peerKey, err := parseHello(peerHello)
+	if err != nil {
+		return nil, err
+	}
+	if peer != expected {
+		return nil, fmt.Errorf("%w: expected %s, got %s", ErrUnexpectedPeer, expected, peer)
+	}
+
+	transcript := transcriptOf(myHello, peerHello)
+	peerSignature, err := readFixedFrame(conn, signatureLength)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyHandshakeSignature(transcript, responderRole, peerSignature, peer); err != nil {
+		return nil, err
+	}
+
+	signature, err := signHandshake(transcript, initiatorRole, secretKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFrame(conn, signature); err != nil {
+		return nil, err
+	}
+
+	return newSession(peer, ephemeral, peerKey, transcript)
+}
+
+// acceptHandshake is the responder's side of the handshake described by initiateHandshake. It returns a session for decrypting the messages the initiator sends.
+func acceptHandshake(conn net.Conn, r io.Reader, secretKey []byte) (*session, error) {
+	if err := conn.SetDeadline(time.Now().Add(handshakeTimeout)); err != nil {
+		return nil, err
+	}
+	defer conn.SetDeadline(time.Time{})
+
+	peerHello, err := readFixedFrame(r, helloLength)
+	if err != nil {
+		return nil, err
+	}
+	peer, peerKey, err := parseHello(peerHello)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeral, myHello, err := newHello(secretKey)
+	if err != nil {
+		return nil, err
+	}
+	transcript := transcriptOf(peerHello, myHello)
+	signature, err := signHandshake(transcript, responderRole, secretKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFrame(conn, myHello); err != nil {
+		return nil, err
+	}
+	if err := writeFrame(conn, signature); err != nil {
+		return nil, err
+	}
+
+	peerSignature, err := readFixedFrame(r, signatureLength)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyHandshakeSignature(transcript, initiatorRole, peerSignature, peer); err != nil {
+		return nil, err
+	}
+
+	return newSession(peer, ephemeral, peerKey, transcript)
+}
+
+// newHello generates an ephemeral key, and returns it along with a hello announcing it and the address of the secret key.
+func newHello(secretKey []byte) (*ecdsa.PrivateKey, []byte, error) {
+	ephemeral, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	me := nc.GetAddressFromSecretKeyBytes(secretKey)
+	return ephemeral, append(me.Bytes(), crypto.FromECDSAPub(&ephemeral.PublicKey)...), nil
+}
+
+// parseHello returns the address and ephemeral public key announced by a hello.
+func parseHello(hello []byte) (types.Address, *ecdsa.PublicKey, error) {
+	key, err := crypto.UnmarshalPubkey(hello[addressLength:])
+	if err != nil {
+		return types.Address{}, nil, fmt.Errorf("%w: invalid ephemeral key: %v", ErrHandshakeFailed, err)
+	}
+	return common.BytesToAddress(hello[:addressLength]), key, nil
+}
+
+// transcriptOf returns the hash of the hellos exchanged during a handshake, which each side signs.
+func transcriptOf(initiatorHello, responderHello []byte) []byte {
+	return crypto.Keccak256([]byte(handshakeDomain), initiatorHello, responderHello)
+}
+
+// handshakeMessage returns the message signed by the side of the handshake in the given role.
+func handshakeMessage(transcript []byte, role byte) []byte {
+	return append(append([]byte{}, transcript...), role)
+}
+
+// signHandshake signs the transcript in the given role, returning the signature in the [R||S||V] format.
+func signHandshake(transcript []byte, role byte, secretKey []byte) ([]byte, error) {
+	sig, err := nc.SignEthereumMessage(handshakeMessage(transcript, role), secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(append([]byte{}, sig.R...), sig.S...), sig.V), nil
+}
+
+// verifyHandshakeSignature returns an error unless the signature over the transcript in the given role was made by the peer.
+func verifyHandshakeSignature(transcript []byte, role byte, signature []byte, peer types.Address) error {
+	signer, err := nc.RecoverEthereumMessageSigner(handshakeMessage(transcript, role), nc.SplitSignature(signature))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrHandshakeFailed, err)
+	}
+	if signer != peer {
+		return fmt.Errorf("%w: signature is not from %s", ErrHandshakeFailed, peer)
+	}
+	return nil
+}
+
+// newSession derives the session key from the ECDH shared secret of the ephemeral keys and the handshake transcript.
+func newSession(peer types.Address, ephemeral *ecdsa.PrivateKey, peerKey *ecdsa.PublicKey, transcript []byte) (*session, error) {
+	x, _ := crypto.S256().ScalarMult(peerKey.X, peerKey.Y, ephemeral.D.Bytes())
+	shared := x.FillBytes(make([]byte, 32))
+
+	block, err := aes.NewCipher(crypto.Keccak256(shared, transcript))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &session{peer: peer, aead: aead}, nil
+}
+
+// readFixedFrame reads a frame, and returns an error unless its payload has the given length.
+func readFixedFrame(r io.Reader, length int) ([]byte, error) {
+	payload, err := readFrame(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) != length {
+		return nil, fmt.Errorf("%w: expected a %d byte frame, got %d bytes", ErrHandshakeFailed, length, len(payload))
+	}
+	return payload, nil
+}
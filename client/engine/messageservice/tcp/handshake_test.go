@@ -0,0 +1,260 @@
+package tcp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var (
+	aliceKey, aliceAddress     = nc.GeneratePrivateKeyAndAddress()
+	bobKey, bobAddress         = nc.GeneratePrivateKeyAndAddress()
+	malloryKey, malloryAddress = nc.GeneratePrivateKeyAndAddress()
+
+	securePeers = map[types.Address]string{
+		aliceAddress:   "localhost:3107",
+		bobAddress:     "localhost:3108",
+		malloryAddress: "localhost:3109",
+	}
+	// proxyUrl is the address of a proxy which relays connections to bob
+	proxyUrl = "localhost:3110"
+)
+
+// expectError fails the test unless an error matching target is reported by the message service in time
+func expectError(t *testing.T, ms *TCPMessageService, target error) {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-ms.Errors():
+			if errors.Is(err, target) {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("expected %v to be reported", target)
+		}
+	}
+}
+
+// expectNoMessage fails the test if the message service receives a message within a short time
+func expectNoMessage(t *testing.T, ms *TCPMessageService) {
+	t.Helper()
+	select {
+	case m := <-ms.Out():
+		t.Fatalf("expected no message, got %+v", m)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// relay copies everything written by the connections it accepts on url to a connection to target, recording what it copies.
+// It returns a func which stops the relay and returns the recorded bytes.
+func relay(t *testing.T, url, target string) func() []byte {
+	l, err := net.Listen(CONN_TYPE, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu       sync.Mutex
+		recorded bytes.Buffer
+		wg       sync.WaitGroup
+	)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			in, err := l.Accept()
+			if err != nil {
+				return
+			}
+			out, err := net.Dial(CONN_TYPE, target)
+			if err != nil {
+				in.Close()
+				continue
+			}
+			go func() { _, _ = io.Copy(in, out); in.Close() }()
+			go func() {
+				buf := make([]byte, 1024)
+				for {
+					n, err := in.Read(buf)
+					if n > 0 {
+						mu.Lock()
+						recorded.Write(buf[:n])
+						mu.Unlock()
+						_, _ = out.Write(buf[:n])
+					}
+					if err != nil {
+						out.Close()
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return func() []byte {
+		l.Close()
+		wg.Wait()
+		mu.Lock()
+		defer mu.Unlock()
+		return recorded.Bytes()
+	}
+}
+
+func TestSecureMessageServiceEncryptsMessages(t *testing.T) {
+	// Alice reaches bob through a relay, which records the bytes alice sends
+	peers := map[types.Address]string{aliceAddress: securePeers[aliceAddress], bobAddress: proxyUrl}
+	msA := NewSecureTCPMessageService(securePeers[aliceAddress], peers, aliceKey)
+	msB := NewSecureTCPMessageService(securePeers[bobAddress], securePeers, bobKey)
+	defer msA.Close()
+	defer msB.Close()
+	stopRelay := relay(t, proxyUrl, securePeers[bobAddress])
+
+	msg := protocols.CreateSignedProposalMessage(bobAddress).WithDelivery(protocols.DeliveryInfo{Id: "a-1"})
+	if err := msA.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-msB.Out():
+		if d, _ := got.Delivery(); d.Id != "a-1" {
+			t.Fatalf("expected message a-1, got %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected bob to receive alice's message")
+	}
+
+	recorded := stopRelay()
+	if len(recorded) == 0 {
+		t.Fatalf("expected the relay to record alice's message")
+	}
+	if bytes.Contains(recorded, []byte("a-1")) || bytes.Contains(recorded, []byte("Delivery")) {
+		t.Fatalf("expected alice's message to be encrypted")
+	}
+}
+
+func TestSecureMessageServiceRejectsUnexpectedPeer(t *testing.T) {
+	// Alice believes bob is listening at mallory's url
+	peers := map[types.Address]string{aliceAddress: securePeers[aliceAddress], bobAddress: securePeers[malloryAddress]}
+	msA := NewSecureTCPMessageService(securePeers[aliceAddress], peers, aliceKey)
+	msM := NewSecureTCPMessageService(securePeers[malloryAddress], securePeers, malloryKey)
+	defer msA.Close()
+	defer msM.Close()
+
+	if err := msA.Send(protocols.CreateSignedProposalMessage(bobAddress)); err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, msA, ErrUnexpectedPeer)
+	expectNoMessage(t, msM)
+}
+
+func TestSecureMessageServiceRejectsImpersonation(t *testing.T) {
+	msB := NewSecureTCPMessageService(securePeers[bobAddress], securePeers, bobKey)
+	defer msB.Close()
+
+	// Mallory claims to be alice, but can only sign with mallory's key
+	conn, err := net.Dial(CONN_TYPE, securePeers[bobAddress])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, hello, err := newHello(malloryKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(hello, aliceAddress.Bytes())
+	if err := writeFrame(conn, hello); err != nil {
+		t.Fatal(err)
+	}
+	bobHello, err := readFixedFrame(conn, helloLength)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readFixedFrame(conn, signatureLength); err != nil {
+		t.Fatal(err)
+	}
+	transcript := transcriptOf(hello, bobHello)
+	signature, err := signHandshake(transcript, initiatorRole, malloryKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFrame(conn, signature); err != nil {
+		t.Fatal(err)
+	}
+
+	expectError(t, msB, ErrHandshakeFailed)
+}
+
+func TestSecureMessageServiceRejectsPlaintextPeer(t *testing.T) {
+	msB := NewSecureTCPMessageService(securePeers[bobAddress], securePeers, bobKey)
+	msA := NewTCPMessageService(aliceAddress, securePeers[aliceAddress], securePeers)
+	defer msB.Close()
+	defer msA.Close()
+
+	if err := msA.Send(protocols.CreateSignedProposalMessage(bobAddress)); err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, msB, ErrHandshakeFailed)
+	expectNoMessage(t, msB)
+}
+
+func TestSecureMessageServiceDropsMessagesFromOtherSenders(t *testing.T) {
+	msB := NewSecureTCPMessageService(securePeers[bobAddress], securePeers, bobKey)
+	msM := NewSecureTCPMessageService(securePeers[malloryAddress], securePeers, malloryKey)
+	defer msB.Close()
+	defer msM.Close()
+
+	// Mallory authenticates as mallory, but claims the message is from alice
+	forged := protocols.CreateSignedProposalMessage(bobAddress)
+	forged.From = aliceAddress
+	if err := msM.Send(forged); err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, msB, ErrSenderMismatch)
+	expectNoMessage(t, msB)
+}
+
+func TestSecureMessageServiceRegistersHellos(t *testing.T) {
+	// Bob starts out knowing no peers
+	msA := NewSecureTCPMessageService(securePeers[aliceAddress], securePeers, aliceKey)
+	msB := NewSecureTCPMessageService(securePeers[bobAddress], map[types.Address]string{}, bobKey)
+	defer msA.Close()
+	defer msB.Close()
+
+	if err := msB.Send(protocols.CreateSignedProposalMessage(aliceAddress)); err != nil {
+		t.Fatal(err)
+	}
+	expectError(t, msB, messageservice.ErrUnknownPeer)
+
+	if err := messageservice.SendHello(msA, bobAddress, securePeers[aliceAddress], aliceKey); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-msB.Out():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected bob to receive alice's hello")
+	}
+	if url, ok := msB.AddressBook().Lookup(aliceAddress); !ok || url != securePeers[aliceAddress] {
+		t.Fatalf("expected bob to register alice at %s, got %q", securePeers[aliceAddress], url)
+	}
+
+	msg := protocols.CreateSignedProposalMessage(aliceAddress).WithDelivery(protocols.DeliveryInfo{Id: "b-1"})
+	if err := msB.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-msA.Out():
+		if d, _ := got.Delivery(); d.Id != "b-1" {
+			t.Fatalf("expected message b-1, got %+v", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected alice to receive bob's message")
+	}
+}
@@ -0,0 +1,454 @@
+// Package tcp implements a message service which keeps a long-lived TCP connection to each peer.
+package tcp // import "github.com/statechannels/go-nitro/client/engine/messageservice/tcp"
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	CONN_TYPE = "tcp"
+
+	// maxFrameSize is the size of the largest message the service reads. A larger frame is taken to mean the stream is corrupt, and the connection is dropped.
+	maxFrameSize = 1 << 24
+	// sendQueueSize is the number of messages which may be queued for a peer while a connection to it is (re)established.
+	sendQueueSize = 1024
+
+	minBackoff   = 50 * time.Millisecond
+	maxBackoff   = 5 * time.Second
+	dialTimeout  = 5 * time.Second
+	writeTimeout = 10 * time.Second
+)
+
+var (
+	// ErrSendQueueFull is returned when too many messages are queued for a peer which cannot be reached.
+	ErrSendQueueFull = errors.New("send queue full")
+	// ErrClosed is returned when a message is sent after the message service is closed.
+	ErrClosed = errors.New("message service closed")
+	// ErrFrameTooLarge is reported when a peer sends a frame larger than the largest message the service reads.
+	ErrFrameTooLarge = errors.New("frame too large")
+)
+
+// TCPMessageService is a message service which keeps a long-lived TCP connection to each peer it sends messages to.
+//
+// Messages are framed with a 4 byte big-endian length prefix, so that any number of them can be sent over a connection.
+// Each peer's messages are queued and written by a goroutine which dials the peer on demand, and redials it with
+// exponential backoff if the connection is lost (for example because the peer restarted). Each connection accepted
+// from a peer is read by its own goroutine.
+//
+// Messages are sent at most once: a message written to a connection which the peer has just closed is lost.
+//
+// Peers' urls are looked up in an AddressBook by their sender goroutine each time they are dialled, so that a slow PeerLookup
+// does not hold up Send. The hellos received from peers are registered with the AddressBook.
+//
+// A TCPMessageService constructed with NewSecureTCPMessageService authenticates each connection with a handshake (see initiateHandshake),
+// and encrypts the messages sent over it. Messages received over the connection which claim to be from anyone other than the
+// authenticated peer are dropped.
+type TCPMessageService struct {
+	out      chan protocols.Message // for sending message to engine
+	errorOut chan error             // for reporting errors encountered while sending or receiving messages
+
+	book *messageservice.AddressBook
+
+	secretKey []byte // the secret key used to authenticate connections. Connections are neither authenticated nor encrypted if it is nil.
+
+	listener net.Listener // The listener for incoming connections on our port
+
+	mu      sync.Mutex
+	senders map[types.Address]*peerSender // the senders of messages to each peer, created on demand
+	inbound map[net.Conn]struct{}         // the connections accepted from peers
+
+	quit chan struct{}  // quit is used to signal the goroutines to stop
+	wg   sync.WaitGroup // wg tracks the running goroutines, so that Close can wait for them to stop
+}
+
+// peerSender queues messages for a peer, so that they can be written to the peer's connection in order.
+type peerSender struct {
+	to    types.Address
+	queue chan []byte
+}
+
+// NewTCPMessageService returns a running TCPMessageService for the given address, listening on the given url. Messages are sent in plaintext.
+func NewTCPMessageService(me types.Address, myUrl string, peers map[types.Address]string) *TCPMessageService {
+	return NewTCPMessageServiceWithAddressBook(myUrl, messageservice.NewAddressBook(me, peers), nil)
+}
+
+// NewSecureTCPMessageService returns a running TCPMessageService listening on the given url, which authenticates its peers
+// and proves control of the address of the supplied secret key to them, and encrypts the messages it sends.
+func NewSecureTCPMessageService(myUrl string, peers map[types.Address]string, secretKey []byte) *TCPMessageService {
+	me := nc.GetAddressFromSecretKeyBytes(secretKey)
+	return NewTCPMessageServiceWithAddressBook(myUrl, messageservice.NewAddressBook(me, peers), secretKey)
+}
+
+// NewTCPMessageServiceWithAddressBook returns a running TCPMessageService listening on the given url, which sends messages to the peers in the AddressBook.
+// If secretKey is nil messages are sent in plaintext, otherwise connections are authenticated and encrypted as for NewSecureTCPMessageService.
+func NewTCPMessageServiceWithAddressBook(myUrl string, book *messageservice.AddressBook, secretKey []byte) *TCPMessageService {
+	l, err := net.Listen(CONN_TYPE, myUrl)
+	if err != nil {
+		panic(err)
+	}
+	s := &TCPMessageService{
+		out:      make(chan protocols.Message, 5),
+		errorOut: make(chan error, 5),
+		book:     book,
+
+		secretKey: secretKey,
+
+		listener: l,
+		senders:  make(map[types.Address]*peerSender),
+		inbound:  make(map[net.Conn]struct{}),
+		quit:     make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.listenForIncoming()
+
+	return s
+}
+
+// Send queues the message to be sent to its recipient, and returns without waiting for it to be written.
+// Errors encountered while looking up or connecting to the recipient are reported on the Errors chan.
+func (s *TCPMessageService) Send(msg protocols.Message) error {
+	raw, err := msg.Serialize()
+	if err != nil {
+		return &messageservice.SendError{To: msg.To, Err: fmt.Errorf("could not serialize message: %w", err)}
+	}
+
+	sender, ok := s.senderFor(msg.To)
+	if !ok {
+		return &messageservice.SendError{To: msg.To, Err: ErrClosed}
+	}
+	select {
+	case sender.queue <- []byte(raw):
+		return nil
+	default:
+		return &messageservice.SendError{To: msg.To, Err: ErrSendQueueFull}
+	}
+}
+
+// senderFor returns the sender of messages to the peer, starting one if there is none. It returns false if the service is closed.
+func (s *TCPMessageService) senderFor(to types.Address) (*peerSender, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.quit:
+		return nil, false
+	default:
+	}
+
+	sender, ok := s.senders[to]
+	if !ok {
+		sender = &peerSender{to: to, queue: make(chan []byte, sendQueueSize)}
+		s.senders[to] = sender
+		s.wg.Add(1)
+		go s.runSender(sender)
+	}
+	return sender, true
+}
+
+// runSender writes the messages queued for the peer to a connection to the peer, redialling the peer whenever the connection is lost.
+func (s *TCPMessageService) runSender(ps *peerSender) {
+	defer s.wg.Done()
+
+	var (
+		conn   net.Conn
+		sess   *session        // encrypts the frames written to conn, if the service is secure
+		closed <-chan struct{} // closed once the peer closes conn
+	)
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	for {
+		var frame []byte
+		select {
+		case <-s.quit:
+			return
+		case frame = <-ps.queue:
+		}
+
+		if conn == nil {
+			if _, ok := s.book.Lookup(ps.to); !ok {
+				// The message is dropped, but later messages are sent if the peer becomes known (for example by sending us a hello)
+				s.reportErrorIfRunning(&messageservice.SendError{To: ps.to, Err: messageservice.ErrUnknownPeer})
+				continue
+			}
+		}
+
+		for written := false; !written; {
+			if conn == nil {
+				conn, sess, closed = s.dial(ps)
+				if conn == nil {
+					return // the service is closed
+				}
+			}
+			select {
+			case <-closed:
+				conn.Close()
+				conn = nil
+				continue
+			default:
+			}
+
+			payload := frame
+			if sess != nil {
+				payload = sess.seal(frame)
+			}
+			if err := writeFrame(conn, payload); err != nil {
+				s.reportErrorIfRunning(&messageservice.SendError{To: ps.to, Err: err})
+				conn.Close()
+				conn = nil
+				continue
+			}
+			written = true
+		}
+	}
+}
+
+// dial connects to the peer, retrying with exponential backoff until it succeeds. It returns a nil connection if the service is closed first.
+// If the service is secure, the connection is authenticated and the returned session encrypts the frames written to it.
+// The returned chan is closed once the peer closes the connection.
+func (s *TCPMessageService) dial(ps *peerSender) (net.Conn, *session, <-chan struct{}) {
+	backoff := minBackoff
+	for {
+		conn, sess, err := s.connect(ps)
+		if err == nil {
+			closed := make(chan struct{})
+			s.wg.Add(1)
+			go s.watchForClose(conn, closed)
+			return conn, sess, closed
+		}
+		s.reportErrorIfRunning(&messageservice.SendError{To: ps.to, Err: err})
+
+		select {
+		case <-s.quit:
+			return nil, nil, nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// connect makes a single attempt to connect to the peer at the url in the address book, performing a handshake with it if the service is secure.
+func (s *TCPMessageService) connect(ps *peerSender) (net.Conn, *session, error) {
+	url, ok := s.book.Lookup(ps.to)
+	if !ok {
+		return nil, nil, messageservice.ErrUnknownPeer
+	}
+	conn, err := net.DialTimeout(CONN_TYPE, url, dialTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.secretKey == nil {
+		return conn, nil, nil
+	}
+	sess, err := initiateHandshake(conn, s.secretKey, ps.to)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	return conn, sess, nil
+}
+
+// watchForClose closes the supplied chan once the connection is closed. Peers never write to the connections we dial,
+// so a read only returns when the peer (or we) close the connection.
+func (s *TCPMessageService) watchForClose(conn net.Conn, closed chan<- struct{}) {
+	defer s.wg.Done()
+	_, _ = io.Copy(io.Discard, conn)
+	close(closed)
+}
+
+// listenForIncoming accepts connections from peers, and reads each of them on its own goroutine
+func (s *TCPMessageService) listenForIncoming() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			s.reportErrorIfRunning(err)
+			continue
+		}
+
+		if !s.trackInbound(conn) {
+			conn.Close()
+			return
+		}
+		go s.handleConnection(conn)
+	}
+}
+
+// trackInbound records the accepted connection, so that it is closed when the service is. It returns false if the service is closed.
+func (s *TCPMessageService) trackInbound(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	select {
+	case <-s.quit:
+		return false
+	default:
+	}
+	s.inbound[conn] = struct{}{}
+	s.wg.Add(1)
+	return true
+}
+
+// handleConnection reads messages from the connection until it is closed, and passes them on to the engine
+func (s *TCPMessageService) handleConnection(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.inbound, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	var sess *session // decrypts the frames read from conn, if the service is secure
+	if s.secretKey != nil {
+		var err error
+		sess, err = acceptHandshake(conn, r, s.secretKey)
+		if err != nil {
+			s.reportErrorIfRunning(fmt.Errorf("could not authenticate %s: %w", conn.RemoteAddr(), err))
+			return
+		}
+	}
+
+	for {
+		raw, err := readFrame(r)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.reportErrorIfRunning(fmt.Errorf("could not read message from %s: %w", conn.RemoteAddr(), err))
+			}
+			return
+		}
+		if sess != nil {
+			raw, err = sess.open(raw)
+			if err != nil {
+				// The stream has been tampered with, so no later frame can be trusted either
+				s.reportErrorIfRunning(fmt.Errorf("could not decrypt message from %s: %w", sess.peer, err))
+				return
+			}
+		}
+
+		m, err := protocols.DeserializeMessage(string(raw))
+		if err != nil {
+			s.reportErrorIfRunning(fmt.Errorf("could not deserialize message from %s: %w", conn.RemoteAddr(), err))
+			continue
+		}
+		if sess != nil && !isFrom(m, sess.peer) {
+			s.reportErrorIfRunning(fmt.Errorf("%w: message from %s claims to be from someone else", ErrSenderMismatch, sess.peer))
+			continue
+		}
+		if _, ok := m.Hello(); ok {
+			if err := s.book.RegisterHello(m); err != nil {
+				s.reportErrorIfRunning(fmt.Errorf("could not register hello from %s: %w", conn.RemoteAddr(), err))
+			}
+		}
+		select {
+		case s.out <- m:
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// isFrom returns false if the message claims to be from someone other than the peer.
+func isFrom(m protocols.Message, peer types.Address) bool {
+	return m.From == (types.Address{}) || m.From == peer
+}
+
+// writeFrame writes the payload to the connection, prefixed with its length
+func writeFrame(conn net.Conn, payload []byte) error {
+	frame := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	copy(frame[4:], payload)
+
+	if err := conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	_, err := conn.Write(frame)
+	return err
+}
+
+// readFrame reads a length-prefixed payload. It returns io.EOF if the stream ends cleanly before the frame.
+func readFrame(r io.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := binary.BigEndian.Uint32(header[:])
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFrameTooLarge, size)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return payload, nil
+}
+
+// reportErrorIfRunning reports the error on the Errors chan if the TCPMessageService is running, otherwise it just returns.
+// Errors are dropped if the chan is full.
+func (s *TCPMessageService) reportErrorIfRunning(err error) {
+	select {
+	case <-s.quit: // If we are quitting we can ignore the error
+		return
+	default:
+	}
+
+	select {
+	case s.errorOut <- err:
+	default:
+	}
+}
+
+// AddressBook returns the AddressBook the TCPMessageService consults for the urls of peers
+func (s *TCPMessageService) AddressBook() *messageservice.AddressBook {
+	return s.book
+}
+
+func (s *TCPMessageService) Out() <-chan protocols.Message {
+	return s.out
+}
+
+// Errors returns a chan for receiving errors encountered while sending messages to or receiving messages from peers
+func (s *TCPMessageService) Errors() <-chan error {
+	return s.errorOut
+}
+
+// Close closes the TCPMessageService's listener and connections, and waits for its goroutines to stop.
+// Messages which are still queued are discarded.
+func (s *TCPMessageService) Close() {
+	s.mu.Lock()
+	close(s.quit)
+	for conn := range s.inbound {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.listener.Close()
+	s.wg.Wait()
+}
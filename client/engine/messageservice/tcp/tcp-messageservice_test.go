@@ -0,0 +1,160 @@
+package tcp
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var (
+	alice = types.Address{'a'}
+	bob   = types.Address{'b'}
+	peers = map[types.Address]string{
+		alice: "localhost:3105",
+		bob:   "localhost:3106",
+	}
+)
+
+// messageWithTurnNum returns a message for bob, whose contents are identified by the turn number
+func messageWithTurnNum(turnNum uint64) protocols.Message {
+	return protocols.CreateSignedProposalMessage(bob, consensus_channel.SignedProposal{
+		Proposal: consensus_channel.Proposal{LedgerID: types.Destination{1}},
+		TurnNum:  turnNum,
+	})
+}
+
+// receive returns the turn number of the next message received by the message service, failing the test if none arrives in time
+func receive(t *testing.T, ms *TCPMessageService) uint64 {
+	t.Helper()
+	select {
+	case m := <-ms.Out():
+		return m.SignedProposals()[0].Payload.TurnNum
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected to receive a message")
+		return 0
+	}
+}
+
+func TestManyMessagesOverOneConnection(t *testing.T) {
+	msA := NewTCPMessageService(alice, peers[alice], peers)
+	msB := NewTCPMessageService(bob, peers[bob], peers)
+	defer msA.Close()
+	defer msB.Close()
+
+	const numMessages = 100
+	for i := uint64(1); i <= numMessages; i++ {
+		if err := msA.Send(messageWithTurnNum(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := uint64(1); i <= numMessages; i++ {
+		if got := receive(t, msB); got != i {
+			t.Fatalf("expected message %d, got %d", i, got)
+		}
+	}
+
+	msB.mu.Lock()
+	numConnections := len(msB.inbound)
+	msB.mu.Unlock()
+	if numConnections != 1 {
+		t.Fatalf("expected all the messages to arrive over a single connection, but bob has %d", numConnections)
+	}
+}
+
+func TestReconnectAfterPeerRestarts(t *testing.T) {
+	msA := NewTCPMessageService(alice, peers[alice], peers)
+	defer msA.Close()
+
+	msB := NewTCPMessageService(bob, peers[bob], peers)
+	if err := msA.Send(messageWithTurnNum(1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, msB); got != 1 {
+		t.Fatalf("expected message 1, got %d", got)
+	}
+
+	// Bob restarts. Messages sent while he is down are delivered once he is back.
+	msB.Close()
+	time.Sleep(100 * time.Millisecond) // give alice time to notice the connection has closed
+	if err := msA.Send(messageWithTurnNum(2)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	msB = NewTCPMessageService(bob, peers[bob], peers)
+	defer msB.Close()
+
+	if got := receive(t, msB); got != 2 {
+		t.Fatalf("expected message 2, got %d", got)
+	}
+	if err := msA.Send(messageWithTurnNum(3)); err != nil {
+		t.Fatal(err)
+	}
+	if got := receive(t, msB); got != 3 {
+		t.Fatalf("expected message 3, got %d", got)
+	}
+
+	// Alice's failed attempts to reach bob were reported
+	select {
+	case err := <-msA.Errors():
+		var se *messageservice.SendError
+		if !errors.As(err, &se) || se.To != bob {
+			t.Fatalf("expected a *messageservice.SendError for bob, got %v", err)
+		}
+	default:
+		t.Fatalf("expected the failure to reach bob to be reported")
+	}
+}
+
+func TestUnknownPeer(t *testing.T) {
+	msA := NewTCPMessageService(alice, peers[alice], peers)
+	defer msA.Close()
+
+	// The peer is looked up after the message is queued, so the failure is reported on the Errors chan
+	if err := msA.Send(protocols.CreateSignedProposalMessage(types.Address{'c'})); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-msA.Errors():
+		if !errors.Is(err, messageservice.ErrUnknownPeer) {
+			t.Fatalf("expected %v, got %v", messageservice.ErrUnknownPeer, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the unknown peer to be reported")
+	}
+}
+
+func TestOversizedFrameIsRejected(t *testing.T) {
+	msB := NewTCPMessageService(bob, peers[bob], peers)
+	defer msB.Close()
+
+	conn, err := net.Dial(CONN_TYPE, peers[bob])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := writeFrame(conn, bytes.Repeat([]byte{'x'}, 8)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte{0xff, 0xff, 0xff, 0xff}); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-msB.Errors():
+			if errors.Is(err, ErrFrameTooLarge) {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("expected an oversized frame to be reported")
+		}
+	}
+}
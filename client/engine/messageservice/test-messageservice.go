@@ -69,7 +69,7 @@ func (t TestMessageService) Out() <-chan protocols.Message {
 
 // dispatchMessage is responsible for dispatching a message to the appropriate peer message service.
 // If there is a mean delay it will wait a random amount of time(based on meanDelay) before sending the message.
-func (t TestMessageService) dispatchMessage(message protocols.Message) {
+func (t TestMessageService) dispatchMessage(message protocols.Message) error {
 	if t.maxDelay > 0 {
 		randomDelay := time.Duration(rand.Int63n(t.maxDelay.Nanoseconds()))
 		time.Sleep(randomDelay)
@@ -82,12 +82,12 @@ func (t TestMessageService) dispatchMessage(message protocols.Message) {
 
 		serializedMsg, err := message.Serialize()
 		if err != nil {
-			panic(`could not serialize message`)
+			return &SendError{To: message.To, Err: fmt.Errorf("could not serialize message: %w", err)}
 		}
 		peer.fromPeers <- []byte(serializedMsg)
+		return nil
 	} else {
-		panic(fmt.Sprintf("client %v has no connection to client %v",
-			t.address, message.To))
+		return &SendError{To: message.To, Err: fmt.Errorf("%w: client %v has no connection to client %v", ErrUnknownPeer, t.address, message.To)}
 	}
 }
 
@@ -97,8 +97,8 @@ func (tms TestMessageService) connect(b Broker) {
 }
 
 // Send dispatches messages
-func (tms TestMessageService) Send(msg protocols.Message) {
-	tms.dispatchMessage(msg)
+func (tms TestMessageService) Send(msg protocols.Message) error {
+	return tms.dispatchMessage(msg)
 }
 
 // routeFromPeers listens for messages from peers, deserializes them and feeds them to the engine
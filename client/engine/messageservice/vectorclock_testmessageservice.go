@@ -49,7 +49,7 @@ func NewVectorClockTestMessageService(
 // dispatchMessage is responsible for dispatching a message to the appropriate peer message service.
 // If there is a mean delay it will wait a random amount of time(based on meanDelay) before sending the message.
 // Messages sends are intercepted by the vector clock logger, which runs the vector clock algorithm and wraps the message accordingly.
-func (t VectorClockTestMessageService) dispatchMessage(message protocols.Message) {
+func (t VectorClockTestMessageService) dispatchMessage(message protocols.Message) error {
 	if t.maxDelay > 0 {
 		randomDelay := time.Duration(rand.Int63n(t.maxDelay.Nanoseconds()))
 		time.Sleep(randomDelay)
@@ -62,13 +62,13 @@ func (t VectorClockTestMessageService) dispatchMessage(message protocols.Message
 
 		serializedMsg, err := message.Serialize()
 		if err != nil {
-			panic(`could not serialize message`)
+			return &SendError{To: message.To, Err: fmt.Errorf("could not serialize message: %w", err)}
 		}
 		vectorClockMessage := t.goveclogger.PrepareSend(summarizeMessageSend(message), serializedMsg, govec.GetDefaultLogOptions())
 		peer.fromPeers <- vectorClockMessage
+		return nil
 	} else {
-		panic(fmt.Sprintf("client %v has no connection to client %v",
-			t.address, message.To))
+		return &SendError{To: message.To, Err: fmt.Errorf("%w: client %v has no connection to client %v", ErrUnknownPeer, t.address, message.To)}
 	}
 }
 
@@ -81,11 +81,11 @@ func summarizeMessageSend(msg protocols.Message) string {
 	for _, entry := range msg.SignedProposals() {
 		summary += `propose `
 		summary += fmt.Sprint(entry.Payload.Proposal.LedgerID)
-		if (entry.Payload.Proposal.ToAdd != consensus_channel.Add{}) {
+		switch entry.Payload.Proposal.Type() {
+		case consensus_channel.AddProposal:
 			summary += ` funds `
 			summary += fmt.Sprint(entry.Payload.Proposal.ToAdd.Target())
-		}
-		if (entry.Payload.Proposal.ToRemove != consensus_channel.Remove{}) {
+		case consensus_channel.RemoveProposal:
 			summary += ` defunds `
 			summary += fmt.Sprint(entry.Payload.Proposal.ToRemove.Target)
 		}
@@ -98,12 +98,18 @@ func summarizeMessageSend(msg protocols.Message) string {
 		summary += fmt.Sprint(turnNum)
 
 	}
+	for _, voucher := range msg.Payments() {
+		summary += `pay `
+		summary += fmt.Sprint(voucher.ChannelId)
+		summary += ` total `
+		summary += fmt.Sprint(voucher.Amount)
+	}
 	return summary
 }
 
 // Send dispatches messages
-func (vctms VectorClockTestMessageService) Send(msg protocols.Message) {
-	vctms.dispatchMessage(msg)
+func (vctms VectorClockTestMessageService) Send(msg protocols.Message) error {
+	return vctms.dispatchMessage(msg)
 }
 
 // routeFromPeers listens for messages from peers, deserializes them and feeds them to the engine.
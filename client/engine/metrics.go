@@ -127,12 +127,25 @@ func (o *MetricsRecorder) RecordObjectiveCompleted(id protocols.ObjectiveId) {
 
 }
 
+// RecordObjectiveFailed records metrics about the failure of an objective
+// This should be called when an objective is failed
+func (o *MetricsRecorder) RecordObjectiveFailed(id protocols.ObjectiveId) {
+	o.metrics.Counter(o.addMyAddress("objective_failed_count")).Inc(1)
+	if _, started := o.startTimes[id]; started {
+		o.metrics.Counter(o.addMyAddress("active_objective_count")).Dec(1)
+	}
+
+	delete(o.startTimes, id)
+}
+
 // RecordOutgoingMessage records metrics about the the outgoing message
 func (o *MetricsRecorder) RecordOutgoingMessage(msg protocols.Message) {
 	proposalCount := len(msg.SignedProposals())
 	o.metrics.RecordPoint(o.addMyAddress("message_proposals")+fmt.Sprintf(",to=%s", msg.To), float64(proposalCount))
 	stateCount := len(msg.SignedProposals())
 	o.metrics.RecordPoint(o.addMyAddress("message_states")+fmt.Sprintf(",to=%s", msg.To), float64(stateCount))
+	paymentCount := len(msg.Payments())
+	o.metrics.RecordPoint(o.addMyAddress("message_payments")+fmt.Sprintf(",to=%s", msg.To), float64(paymentCount))
 }
 
 // RecordQueueLength records metrics about the length of some queue
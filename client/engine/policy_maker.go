@@ -1,6 +1,10 @@
 package engine
 
-import "github.com/statechannels/go-nitro/protocols"
+import (
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+)
 
 // PolicyMaker is used to decide whether to approve or reject an objective
 type PolicyMaker interface {
@@ -14,3 +18,21 @@ type PermissivePolicy struct{}
 func (pp *PermissivePolicy) ShouldApprove(o protocols.Objective) bool {
 	return o.GetStatus() == protocols.Unapproved
 }
+
+// ChallengeResponse is the action the engine takes when a challenge is registered against one of its channels with a stale state.
+type ChallengeResponse int
+
+const (
+	// Checkpoint clears the challenge by recording the latest supported state on chain.
+	Checkpoint ChallengeResponse = iota
+	// CounterChallenge replaces the challenge with one registered with the latest supported state, and spawns a challenge.Objective to recover the channel's funds.
+	CounterChallenge
+	// Ignore leaves the challenge in place.
+	Ignore
+)
+
+// ChallengeResponsePolicy may be implemented by a PolicyMaker to decide how the engine responds to a challenge registered with a stale state.
+// If the PolicyMaker does not implement it, the engine checkpoints the latest supported state.
+type ChallengeResponsePolicy interface {
+	RespondToChallenge(challenge chainservice.ChallengeRegisteredEvent, latestSupported state.SignedState) ChallengeResponse
+}
@@ -0,0 +1,400 @@
+package store
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+// Key prefixes used to namespace the different record types held in the underlying database.
+const (
+	objectivePrefix          = "objective/"
+	channelPrefix            = "channel/"
+	consensusChannelPrefix   = "consensus_channel/"
+	channelToObjectivePrefix = "channel_to_objective/"
+	voucherInfoPrefix        = "voucher_info/"
+)
+
+// lastBlockNumSeenKey is the key under which the number of the latest block from which a chain event was processed is held.
+const lastBlockNumSeenKey = "last_block_num_seen"
+
+// DurableStore is a Store which persists its data to disk, allowing a client to be restarted without losing
+// knowledge of its objectives, channels and consensus channels.
+//
+// Records are serialized with the same MarshalJSON / UnmarshalJSON methods used by the MemStore, and written to
+// an embedded LevelDB database in the supplied data directory.
+type DurableStore struct {
+	db *leveldb.DB
+
+	ownershipLock sync.Mutex // guards the check-and-set of channel ownership in SetObjective
+
+	key     string // the signing key of the store's engine
+	address string // the (Ethereum) address associated to the signing key
+}
+
+// NewDurableStore opens (or creates) a DurableStore backed by a database in the supplied folder.
+func NewDurableStore(key []byte, folder string) (*DurableStore, error) {
+	db, err := leveldb.OpenFile(folder, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not open database in %s: %w", folder, err)
+	}
+
+	ds := DurableStore{db: db}
+	ds.key = common.Bytes2Hex(key)
+	ds.address = crypto.GetAddressFromSecretKeyBytes(key).String()
+
+	return &ds, nil
+}
+
+// Close closes the underlying database. The store should not be used after Close is called.
+func (ds *DurableStore) Close() error {
+	return ds.db.Close()
+}
+
+func (ds *DurableStore) GetAddress() *types.Address {
+	address := common.HexToAddress(ds.address)
+	return &address
+}
+
+func (ds *DurableStore) GetChannelSecretKey() *[]byte {
+	val := common.Hex2Bytes(ds.key)
+	return &val
+}
+
+func (ds *DurableStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Objective, error) {
+	objJSON, err := ds.db.Get([]byte(objectivePrefix+string(id)), nil)
+
+	// return immediately if no such objective exists
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchObjective, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading objective %s: %w", id, err)
+	}
+
+	obj, err := decodeObjective(id, objJSON)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding objective %s: %w", id, err)
+	}
+
+	err = populateChannelData(obj, ds)
+	if err != nil {
+		// return existing objective data along with error
+		return obj, fmt.Errorf("error populating channel data for objective %s: %w", id, err)
+	}
+
+	return obj, nil
+}
+
+// SetObjective writes the objective, its related channels and (if the objective is approved) its ownership of its channel.
+//
+// Ownership is checked before anything is written, and everything is written in a single synced batch,
+// so that neither an ownership conflict nor a crash leaves the store holding only part of the objective's data.
+func (ds *DurableStore) SetObjective(obj protocols.Objective) error {
+	objJSON, err := obj.MarshalJSON()
+
+	if err != nil {
+		return fmt.Errorf("error setting objective %s: %w", obj.Id(), err)
+	}
+
+	// Objective ownership can only be transferred if the channel is not owned by another objective
+	ds.ownershipLock.Lock()
+	defer ds.ownershipLock.Unlock()
+
+	ownerKey := []byte(channelToObjectivePrefix + obj.OwnsChannel().String())
+	prevOwner, err := ds.db.Get(ownerKey, nil)
+	isOwned := err == nil
+	if err != nil && !errors.Is(err, leveldb.ErrNotFound) {
+		return fmt.Errorf("error reading owner of channel %s: %w", obj.OwnsChannel(), err)
+	}
+	isApproved := obj.GetStatus() == protocols.Approved
+	if isApproved && isOwned && protocols.ObjectiveId(prevOwner) != obj.Id() {
+		return fmt.Errorf("cannot transfer ownership of channel to from objective %s to %s", prevOwner, obj.Id())
+	}
+
+	batch := new(leveldb.Batch)
+	batch.Put([]byte(objectivePrefix+string(obj.Id())), objJSON)
+
+	for _, rel := range obj.Related() {
+		switch ch := rel.(type) {
+		case *channel.Channel:
+			chJSON, err := ch.MarshalJSON()
+			if err != nil {
+				return fmt.Errorf("error setting channel %s from objective %s: %w", ch.Id, obj.Id(), err)
+			}
+			batch.Put([]byte(channelPrefix+ch.Id.String()), chJSON)
+		case *consensus_channel.ConsensusChannel:
+			chJSON, err := ch.MarshalJSON()
+			if err != nil {
+				return fmt.Errorf("error setting consensus channel %s from objective %s: %w", ch.Id, obj.Id(), err)
+			}
+			batch.Put([]byte(consensusChannelPrefix+ch.Id.String()), chJSON)
+		default:
+			return fmt.Errorf("unexpected type: %T", rel)
+		}
+	}
+
+	if isApproved && !isOwned {
+		batch.Put(ownerKey, []byte(obj.Id()))
+	}
+
+	err = ds.db.Write(batch, &opt.WriteOptions{Sync: true})
+	if err != nil {
+		return fmt.Errorf("error setting objective %s: %w", obj.Id(), err)
+	}
+	return nil
+}
+
+// GetApprovedObjectives returns every objective which has been approved but has not yet completed or been rejected.
+func (ds *DurableStore) GetApprovedObjectives() ([]protocols.Objective, error) {
+	toReturn := []protocols.Objective{}
+
+	iter := ds.db.NewIterator(util.BytesPrefix([]byte(objectivePrefix)), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		id := protocols.ObjectiveId(string(iter.Key())[len(objectivePrefix):])
+
+		obj, err := decodeObjective(id, iter.Value())
+		if err != nil {
+			return nil, fmt.Errorf("error decoding objective %s: %w", id, err)
+		}
+		if obj.GetStatus() != protocols.Approved {
+			continue
+		}
+		err = populateChannelData(obj, ds)
+		if err != nil {
+			return nil, fmt.Errorf("error populating channel data for objective %s: %w", id, err)
+		}
+		toReturn = append(toReturn, obj)
+	}
+
+	if err := iter.Error(); err != nil {
+		return nil, fmt.Errorf("error reading objectives: %w", err)
+	}
+	return toReturn, nil
+}
+
+// SetChannel sets the channel in the store.
+func (ds *DurableStore) SetChannel(ch *channel.Channel) error {
+	chJSON, err := ch.MarshalJSON()
+
+	if err != nil {
+		return err
+	}
+
+	return ds.db.Put([]byte(channelPrefix+ch.Id.String()), chJSON, nil)
+}
+
+// DestroyChannel deletes the channel with id id.
+func (ds *DurableStore) DestroyChannel(id types.Destination) {
+	_ = ds.db.Delete([]byte(channelPrefix+id.String()), nil)
+}
+
+// SetConsensusChannel sets the channel in the store.
+func (ds *DurableStore) SetConsensusChannel(ch *consensus_channel.ConsensusChannel) error {
+	chJSON, err := ch.MarshalJSON()
+
+	if err != nil {
+		return err
+	}
+
+	return ds.db.Put([]byte(consensusChannelPrefix+ch.Id.String()), chJSON, nil)
+}
+
+// DestroyConsensusChannel deletes the consensus channel with id id.
+func (ds *DurableStore) DestroyConsensusChannel(id types.Destination) {
+	_ = ds.db.Delete([]byte(consensusChannelPrefix+id.String()), nil)
+}
+
+// GetChannelById retrieves the channel with the supplied id, if it exists.
+func (ds *DurableStore) GetChannelById(id types.Destination) (c *channel.Channel, ok bool) {
+	ch, err := ds.getChannelById(id)
+
+	if err != nil {
+		return &channel.Channel{}, false
+	}
+
+	return &ch, true
+}
+
+// getChannelById returns the stored channel
+func (ds *DurableStore) getChannelById(id types.Destination) (channel.Channel, error) {
+	chJSON, err := ds.db.Get([]byte(channelPrefix+id.String()), nil)
+
+	if err != nil {
+		return channel.Channel{}, ErrNoSuchChannel
+	}
+
+	var ch channel.Channel
+	err = ch.UnmarshalJSON(chJSON)
+
+	if err != nil {
+		return channel.Channel{}, fmt.Errorf("error unmarshaling channel %s", ch.Id)
+	}
+
+	return ch, nil
+}
+
+// GetChannelsByParticipant returns any channels that include the given participant
+func (ds *DurableStore) GetChannelsByParticipant(participant types.Address) []*channel.Channel {
+	toReturn := []*channel.Channel{}
+
+	iter := ds.db.NewIterator(util.BytesPrefix([]byte(channelPrefix)), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		var ch channel.Channel
+		err := ch.UnmarshalJSON(iter.Value())
+
+		if err != nil {
+			continue // channel not found, continue looking
+		}
+
+		for _, p := range ch.FixedPart.Participants {
+			if p == participant {
+				chCopy := ch
+				toReturn = append(toReturn, &chCopy)
+			}
+		}
+	}
+
+	return toReturn
+}
+
+// GetConsensusChannelById returns a ConsensusChannel with the given channel id
+func (ds *DurableStore) GetConsensusChannelById(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error) {
+	chJSON, err := ds.db.Get([]byte(consensusChannelPrefix+id.String()), nil)
+
+	if err != nil {
+		return &consensus_channel.ConsensusChannel{}, ErrNoSuchChannel
+	}
+
+	ch := &consensus_channel.ConsensusChannel{}
+	err = ch.UnmarshalJSON(chJSON)
+
+	if err != nil {
+		return &consensus_channel.ConsensusChannel{}, fmt.Errorf("error unmarshaling channel %s", ch.Id)
+	}
+
+	return ch, nil
+}
+
+// GetAllConsensusChannels returns every ConsensusChannel in the store.
+func (ds *DurableStore) GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error) {
+	iter := ds.db.NewIterator(util.BytesPrefix([]byte(consensusChannelPrefix)), nil)
+	defer iter.Release()
+
+	toReturn := []*consensus_channel.ConsensusChannel{}
+	for iter.Next() {
+		ch := &consensus_channel.ConsensusChannel{}
+		err := ch.UnmarshalJSON(iter.Value())
+		if err != nil {
+			return []*consensus_channel.ConsensusChannel{}, fmt.Errorf("error unmarshaling consensus channel: %w", err)
+		}
+		toReturn = append(toReturn, ch)
+	}
+	return toReturn, iter.Error()
+}
+
+// GetConsensusChannel returns a ConsensusChannel between the calling client and
+// the supplied counterparty, if such channel exists
+func (ds *DurableStore) GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool) {
+	iter := ds.db.NewIterator(util.BytesPrefix([]byte(consensusChannelPrefix)), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		var ch consensus_channel.ConsensusChannel
+		err := ch.UnmarshalJSON(iter.Value())
+
+		if err != nil {
+			continue // channel not found, continue looking
+		}
+
+		participants := ch.Participants()
+		if len(participants) == 2 {
+			if participants[0] == counterparty || participants[1] == counterparty {
+				return &ch, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// GetLastBlockNumSeen returns the number of the latest block from which a chain event was processed, or zero if none has been.
+func (ds *DurableStore) GetLastBlockNumSeen() (uint64, error) {
+	blockNum, err := ds.db.Get([]byte(lastBlockNumSeenKey), nil)
+	if errors.Is(err, leveldb.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error reading last block number seen: %w", err)
+	}
+	return binary.BigEndian.Uint64(blockNum), nil
+}
+
+// SetLastBlockNumSeen records the number of the latest block from which a chain event was processed.
+func (ds *DurableStore) SetLastBlockNumSeen(blockNum uint64) error {
+	encoded := make([]byte, 8)
+	binary.BigEndian.PutUint64(encoded, blockNum)
+	return ds.db.Put([]byte(lastBlockNumSeenKey), encoded, nil)
+}
+
+func (ds *DurableStore) GetObjectiveByChannelId(channelId types.Destination) (protocols.Objective, bool) {
+	id, err := ds.db.Get([]byte(channelToObjectivePrefix+channelId.String()), nil)
+	if err != nil {
+		return &directfund.Objective{}, false
+	}
+
+	objective, err := ds.GetObjectiveById(protocols.ObjectiveId(id))
+	return objective, err == nil
+}
+
+func (ds *DurableStore) ReleaseChannelFromOwnership(channelId types.Destination) {
+	_ = ds.db.Delete([]byte(channelToObjectivePrefix+channelId.String()), nil)
+}
+
+// GetVoucherInfo returns the payment information for the channel with the supplied id, if it exists.
+func (ds *DurableStore) GetVoucherInfo(channelId types.Destination) (v *payments.VoucherInfo, ok bool) {
+	vJSON, err := ds.db.Get([]byte(voucherInfoPrefix+channelId.String()), nil)
+	if err != nil {
+		return nil, false
+	}
+
+	v = &payments.VoucherInfo{}
+	err = json.Unmarshal(vJSON, v)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// SetVoucherInfo sets the payment information for the channel with the supplied id.
+func (ds *DurableStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	vJSON, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error setting voucher info for channel %s: %w", channelId, err)
+	}
+
+	return ds.db.Put([]byte(voucherInfoPrefix+channelId.String()), vJSON, nil)
+}
+
+// RemoveVoucherInfo deletes the payment information for the channel with the supplied id.
+func (ds *DurableStore) RemoveVoucherInfo(channelId types.Destination) error {
+	return ds.db.Delete([]byte(voucherInfoPrefix+channelId.String()), nil)
+}
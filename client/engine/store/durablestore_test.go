@@ -0,0 +1,125 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+)
+
+// storeConstructors returns a constructor for each Store implementation, so that every implementation is run through the same tests.
+func storeConstructors() map[string]func(t *testing.T, sk []byte) store.Store {
+	return map[string]func(t *testing.T, sk []byte) store.Store{
+		"MemStore": func(t *testing.T, sk []byte) store.Store {
+			return store.NewMemStore(sk)
+		},
+		"DurableStore": func(t *testing.T, sk []byte) store.Store {
+			ds, err := store.NewDurableStore(sk, t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { ds.Close() })
+			return ds
+		},
+	}
+}
+
+func TestDurableStoreSurvivesRestart(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	dataFolder := t.TempDir()
+
+	ds, err := store.NewDurableStore(sk, dataFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	dfo.Status = protocols.Approved
+	if err := ds.SetObjective(&dfo); err != nil {
+		t.Fatalf("error setting objective %v: %s", dfo, err.Error())
+	}
+	lastBlockNumSeen := uint64(42)
+	if err := ds.SetLastBlockNumSeen(lastBlockNumSeen); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ds.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen the store from the same folder, as a restarted client would
+	restarted, err := store.NewDurableStore(sk, dataFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restarted.Close()
+
+	got, err := restarted.GetObjectiveById(dfo.Id())
+	if err != nil {
+		t.Fatalf("expected to find the objective after restart, but didn't: %s", err)
+	}
+	if diff := compareObjectives(got, &dfo); diff != "" {
+		t.Errorf("expected no diff between set and retrieved objective, but found:\n%s", diff)
+	}
+
+	owner, ok := restarted.GetObjectiveByChannelId(dfo.C.Id)
+	if !ok {
+		t.Fatalf("expected channel ownership to survive a restart")
+	}
+	if owner.Id() != dfo.Id() {
+		t.Errorf("expected channel to be owned by %s, but it was owned by %s", dfo.Id(), owner.Id())
+	}
+
+	if _, ok := restarted.GetChannelById(dfo.C.Id); !ok {
+		t.Errorf("expected to find channel %s after restart", dfo.C.Id)
+	}
+
+	if got, err := restarted.GetLastBlockNumSeen(); err != nil || got != lastBlockNumSeen {
+		t.Errorf("expected last block number seen %d after restart, got %d (error %v)", lastBlockNumSeen, got, err)
+	}
+}
+
+// rivalObjective is a directfund objective with a different id, so that it competes with the original for ownership of its channel.
+type rivalObjective struct {
+	*directfund.Objective
+}
+
+func (r rivalObjective) Id() protocols.ObjectiveId {
+	return protocols.ObjectiveId(directfund.ObjectivePrefix + "rival")
+}
+
+func TestDurableStoreOwnershipConflictWritesNothing(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	ds, err := store.NewDurableStore(sk, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ds.Close()
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	dfo.Status = protocols.Approved
+	if err := ds.SetObjective(&dfo); err != nil {
+		t.Fatal(err)
+	}
+
+	rival := td.Objectives.Directfund.GenericDFO()
+	rival.Status = protocols.Approved
+	rival.C.OnChainFunding = nil
+	if err := ds.SetObjective(rivalObjective{&rival}); err == nil {
+		t.Fatal("expected an error setting an objective for a channel owned by another objective")
+	}
+
+	if _, err := ds.GetObjectiveById(rivalObjective{&rival}.Id()); err == nil {
+		t.Error("expected the rival objective not to be written")
+	}
+	stored, ok := ds.GetChannelById(dfo.C.Id)
+	if !ok {
+		t.Fatalf("expected to find channel %s", dfo.C.Id)
+	}
+	if stored.OnChainFunding == nil {
+		t.Error("expected the channel not to be overwritten by the rival objective")
+	}
+}
@@ -3,13 +3,16 @@ package store
 import (
 	"encoding/json"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/client/engine/store/safesync"
 	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
@@ -22,6 +25,8 @@ type MemStore struct {
 	channels           safesync.Map[[]byte]
 	consensusChannels  safesync.Map[[]byte]
 	channelToObjective safesync.Map[protocols.ObjectiveId]
+	voucherInfo        safesync.Map[[]byte]
+	lastBlockNumSeen   uint64 // accessed atomically
 
 	key     string // the signing key of the store's engine
 	address string // the (Ethereum) address associated to the signing key
@@ -36,6 +41,7 @@ func NewMemStore(key []byte) Store {
 	ms.channels = safesync.Map[[]byte]{}
 	ms.consensusChannels = safesync.Map[[]byte]{}
 	ms.channelToObjective = safesync.Map[protocols.ObjectiveId]{}
+	ms.voucherInfo = safesync.Map[[]byte]{}
 
 	return &ms
 }
@@ -64,7 +70,7 @@ func (ms *MemStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Object
 		return nil, fmt.Errorf("error decoding objective %s: %w", id, err)
 	}
 
-	err = ms.populateChannelData(obj)
+	err = populateChannelData(obj, ms)
 	if err != nil {
 		// return existing objective data along with error
 		return obj, fmt.Errorf("error populating channel data for objective %s: %w", id, err)
@@ -222,6 +228,34 @@ func (ms *MemStore) GetConsensusChannelById(id types.Destination) (channel *cons
 	return ch, nil
 }
 
+func (ms *MemStore) GetLastBlockNumSeen() (uint64, error) {
+	return atomic.LoadUint64(&ms.lastBlockNumSeen), nil
+}
+
+func (ms *MemStore) SetLastBlockNumSeen(blockNum uint64) error {
+	atomic.StoreUint64(&ms.lastBlockNumSeen, blockNum)
+	return nil
+}
+
+// GetAllConsensusChannels returns every ConsensusChannel in the store.
+func (ms *MemStore) GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error) {
+	toReturn := []*consensus_channel.ConsensusChannel{}
+	var unmarshErr error
+	ms.consensusChannels.Range(func(key string, chJSON []byte) bool {
+		ch := &consensus_channel.ConsensusChannel{}
+		unmarshErr = ch.UnmarshalJSON(chJSON)
+		if unmarshErr != nil {
+			return false
+		}
+		toReturn = append(toReturn, ch)
+		return true
+	})
+	if unmarshErr != nil {
+		return []*consensus_channel.ConsensusChannel{}, fmt.Errorf("error unmarshaling consensus channel: %w", unmarshErr)
+	}
+	return toReturn, nil
+}
+
 // GetConsensusChannel returns a ConsensusChannel between the calling client and
 // the supplied counterparty, if such channel exists
 func (ms *MemStore) GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool) {
@@ -261,10 +295,78 @@ func (ms *MemStore) GetObjectiveByChannelId(channelId types.Destination) (protoc
 	return objective, err == nil
 }
 
+// GetApprovedObjectives returns every objective which has been approved but has not yet completed or been rejected.
+func (ms *MemStore) GetApprovedObjectives() ([]protocols.Objective, error) {
+	toReturn := []protocols.Objective{}
+	var err error
+
+	ms.objectives.Range(func(key string, objJSON []byte) bool {
+		var obj protocols.Objective
+		obj, err = decodeObjective(protocols.ObjectiveId(key), objJSON)
+		if err != nil {
+			err = fmt.Errorf("error decoding objective %s: %w", key, err)
+			return false
+		}
+		if obj.GetStatus() != protocols.Approved {
+			return true
+		}
+		err = populateChannelData(obj, ms)
+		if err != nil {
+			err = fmt.Errorf("error populating channel data for objective %s: %w", key, err)
+			return false
+		}
+		toReturn = append(toReturn, obj)
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+	return toReturn, nil
+}
+
+// GetVoucherInfo returns the payment information for the channel with the supplied id, if it exists.
+func (ms *MemStore) GetVoucherInfo(channelId types.Destination) (v *payments.VoucherInfo, ok bool) {
+	vJSON, ok := ms.voucherInfo.Load(channelId.String())
+	if !ok {
+		return nil, false
+	}
+
+	v = &payments.VoucherInfo{}
+	err := json.Unmarshal(vJSON, v)
+	if err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// SetVoucherInfo sets the payment information for the channel with the supplied id.
+func (ms *MemStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	vJSON, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error setting voucher info for channel %s: %w", channelId, err)
+	}
+
+	ms.voucherInfo.Store(channelId.String(), vJSON)
+	return nil
+}
+
+// RemoveVoucherInfo deletes the payment information for the channel with the supplied id.
+func (ms *MemStore) RemoveVoucherInfo(channelId types.Destination) error {
+	ms.voucherInfo.Delete(channelId.String())
+	return nil
+}
+
+// channelReader is implemented by stores which can supply the channel data referenced by stored objectives.
+type channelReader interface {
+	getChannelById(id types.Destination) (channel.Channel, error)
+	GetConsensusChannelById(id types.Destination) (*consensus_channel.ConsensusChannel, error)
+}
+
 // populateChannelData fetches stored Channel data relevant to the given
 // objective and attaches it to the objective. The channel data is attached
 // in-place of the objectives existing channel pointers.
-func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
+func populateChannelData(obj protocols.Objective, ms channelReader) error {
 	id := obj.Id()
 
 	switch o := obj.(type) {
@@ -288,13 +390,23 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 
 		o.C = &ch
 
+		return nil
+	case *challenge.Objective:
+		ch, err := ms.getChannelById(o.C.Id)
+
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
 		return nil
 	case *virtualfund.Objective:
 		v, err := ms.getChannelById(o.V.Id)
 		if err != nil {
 			return fmt.Errorf("error retrieving virtual channel data for objective %s: %w", id, err)
 		}
-		o.V = &channel.SingleHopVirtualChannel{Channel: v}
+		o.V = &channel.VirtualChannel{Channel: v}
 
 		zeroAddress := types.Destination{}
 
@@ -363,6 +475,10 @@ func decodeObjective(id protocols.ObjectiveId, data []byte) (protocols.Objective
 		ddfo := directdefund.Objective{}
 		err := ddfo.UnmarshalJSON(data)
 		return &ddfo, err
+	case challenge.IsChallengeObjective(id):
+		co := challenge.Objective{}
+		err := co.UnmarshalJSON(data)
+		return &co, err
 	case virtualfund.IsVirtualFundObjective(id):
 		vfo := virtualfund.Objective{}
 		err := vfo.UnmarshalJSON(data)
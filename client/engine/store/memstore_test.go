@@ -9,10 +9,10 @@ import (
 	"github.com/statechannels/go-nitro/channel"
 	cc "github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
-	"github.com/statechannels/go-nitro/client/engine/store"
 	nc "github.com/statechannels/go-nitro/crypto"
 	ta "github.com/statechannels/go-nitro/internal/testactors"
 	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
@@ -34,180 +34,323 @@ func compareObjectives(a, b protocols.Objective) string {
 }
 
 func TestNewMemStore(t *testing.T) {
-	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
-	store.NewMemStore(sk)
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+			sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+			newStore(t, sk)
+		})
+	}
 }
 
 func TestSetGetObjective(t *testing.T) {
-	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+			sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
 
-	ms := store.NewMemStore(sk)
+			ms := newStore(t, sk)
 
-	id := protocols.ObjectiveId("404")
-	got, err := ms.GetObjectiveById(id)
-	if err == nil {
-		t.Fatalf("expected not to find the %s objective, but found %v", id, got)
-	}
+			id := protocols.ObjectiveId("404")
+			got, err := ms.GetObjectiveById(id)
+			if err == nil {
+				t.Fatalf("expected not to find the %s objective, but found %v", id, got)
+			}
 
-	wants := []protocols.Objective{}
-	dfo := td.Objectives.Directfund.GenericDFO()
-	vfo := td.Objectives.Virtualfund.GenericVFO()
-	wants = append(wants, &dfo)
-	wants = append(wants, &vfo)
+			wants := []protocols.Objective{}
+			dfo := td.Objectives.Directfund.GenericDFO()
+			vfo := td.Objectives.Virtualfund.GenericVFO()
+			wants = append(wants, &dfo)
+			wants = append(wants, &vfo)
 
-	for _, want := range wants {
+			for _, want := range wants {
 
-		if err := ms.SetObjective(want); err != nil {
-			t.Errorf("error setting objective %v: %s", want, err.Error())
-		}
+				if err := ms.SetObjective(want); err != nil {
+					t.Errorf("error setting objective %v: %s", want, err.Error())
+				}
 
-		got, err = ms.GetObjectiveById(want.Id())
+				got, err = ms.GetObjectiveById(want.Id())
 
-		if err != nil {
-			t.Errorf("expected to find the inserted objective, but didn't: %s", err)
-		}
+				if err != nil {
+					t.Errorf("expected to find the inserted objective, but didn't: %s", err)
+				}
 
-		if got.Id() != want.Id() {
-			t.Errorf("expected to retrieve same objective Id as was passed in, but didn't")
-		}
+				if got.Id() != want.Id() {
+					t.Errorf("expected to retrieve same objective Id as was passed in, but didn't")
+				}
 
-		if diff := compareObjectives(got, want); diff != "" {
-			t.Errorf("expected no diff between set and retrieved objective, but found:\n%s", diff)
-		}
+				if diff := compareObjectives(got, want); diff != "" {
+					t.Errorf("expected no diff between set and retrieved objective, but found:\n%s", diff)
+				}
+			}
+		})
 	}
 }
 
 func TestGetObjectiveByChannelId(t *testing.T) {
-
-	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
-
-	ms := store.NewMemStore(sk)
-
-	dfo := td.Objectives.Directfund.GenericDFO()
-
-	// Store an unapproved objective
-	if err := ms.SetObjective(&dfo); err != nil {
-		t.Errorf("error setting objective %v: %s", dfo, err.Error())
-	}
-
-	_, ok := ms.GetObjectiveByChannelId(dfo.C.Id)
-	if ok {
-		t.Error("when an unapproved objective is stored, the objective should not own the channel")
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+
+			sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+			ms := newStore(t, sk)
+
+			dfo := td.Objectives.Directfund.GenericDFO()
+
+			// Store an unapproved objective
+			if err := ms.SetObjective(&dfo); err != nil {
+				t.Errorf("error setting objective %v: %s", dfo, err.Error())
+			}
+
+			_, ok := ms.GetObjectiveByChannelId(dfo.C.Id)
+			if ok {
+				t.Error("when an unapproved objective is stored, the objective should not own the channel")
+			}
+
+			// Now, approve the objective
+			dfo.Status = protocols.Approved
+			if err := ms.SetObjective(&dfo); err != nil {
+				t.Errorf("error setting objective %v: %s", dfo, err.Error())
+			}
+			got, ok := ms.GetObjectiveByChannelId(dfo.C.Id)
+
+			if !ok {
+				t.Errorf("expected to find the inserted objective, but didn't")
+			}
+			if got.Id() != dfo.Id() {
+				t.Errorf("expected to retrieve same objective Id as was passed in, but didn't")
+			}
+			if diff := compareObjectives(got, &dfo); diff != "" {
+				t.Errorf("expected no diff between set and retrieved objective, but found:\n%s", diff)
+			}
+		})
 	}
+}
 
-	// Now, approve the objective
-	dfo.Status = protocols.Approved
-	if err := ms.SetObjective(&dfo); err != nil {
-		t.Errorf("error setting objective %v: %s", dfo, err.Error())
-	}
-	got, ok := ms.GetObjectiveByChannelId(dfo.C.Id)
-
-	if !ok {
-		t.Errorf("expected to find the inserted objective, but didn't")
-	}
-	if got.Id() != dfo.Id() {
-		t.Errorf("expected to retrieve same objective Id as was passed in, but didn't")
-	}
-	if diff := compareObjectives(got, &dfo); diff != "" {
-		t.Errorf("expected no diff between set and retrieved objective, but found:\n%s", diff)
+func TestGetApprovedObjectives(t *testing.T) {
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+			sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+			ms := newStore(t, sk)
+
+			unapproved := td.Objectives.Virtualfund.GenericVFO()
+			unapproved.Status = protocols.Unapproved
+			if err := ms.SetObjective(&unapproved); err != nil {
+				t.Fatalf("error setting objective %v: %s", unapproved, err.Error())
+			}
+
+			approved := td.Objectives.Directfund.GenericDFO()
+			approved.Status = protocols.Approved
+			if err := ms.SetObjective(&approved); err != nil {
+				t.Fatalf("error setting objective %v: %s", approved, err.Error())
+			}
+
+			got, err := ms.GetApprovedObjectives()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("expected exactly 1 approved objective, but found %d", len(got))
+			}
+			if diff := compareObjectives(got[0], &approved); diff != "" {
+				t.Errorf("expected no diff between set and retrieved objective, but found:\n%s", diff)
+			}
+
+			// Once completed, the objective should no longer be returned
+			approved.Status = protocols.Completed
+			if err := ms.SetObjective(&approved); err != nil {
+				t.Fatalf("error setting objective %v: %s", approved, err.Error())
+			}
+			got, err = ms.GetApprovedObjectives()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 0 {
+				t.Errorf("expected no approved objectives, but found %d", len(got))
+			}
+		})
 	}
 }
 
 func TestGetChannelSecretKey(t *testing.T) {
-	// from state/test-fixtures.go
-	sk := common.Hex2Bytes("caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634")
-	pk := common.HexToAddress("0xF5A1BB5607C9D079E46d1B3Dc33f257d937b43BD")
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+			// from state/test-fixtures.go
+			sk := common.Hex2Bytes("caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634")
+			pk := common.HexToAddress("0xF5A1BB5607C9D079E46d1B3Dc33f257d937b43BD")
 
-	ms := store.NewMemStore(sk)
-	key := ms.GetChannelSecretKey()
+			ms := newStore(t, sk)
+			key := ms.GetChannelSecretKey()
 
-	msg := []byte("sign this")
+			msg := []byte("sign this")
 
-	signedMsg, _ := nc.SignEthereumMessage(msg, *key)
-	recoveredSigner, _ := nc.RecoverEthereumMessageSigner(msg, signedMsg)
+			signedMsg, _ := nc.SignEthereumMessage(msg, *key)
+			recoveredSigner, _ := nc.RecoverEthereumMessageSigner(msg, signedMsg)
 
-	if recoveredSigner != pk {
-		t.Fatalf("expected to recover %x, but got %x", pk, recoveredSigner)
+			if recoveredSigner != pk {
+				t.Fatalf("expected to recover %x, but got %x", pk, recoveredSigner)
+			}
+		})
 	}
 }
 
 func TestConsensusChannelStore(t *testing.T) {
-	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
-
-	ms := store.NewMemStore(sk)
-
-	got, ok := ms.GetConsensusChannel(ta.Alice.Address())
-	if ok {
-		t.Fatalf("expected not to find the a consensus channel, but found %v", got)
-	}
-
-	fp := td.Objectives.Directfund.GenericDFO().C.FixedPart // TODO replace with testdata not nested under GenericDFO
-	fp.Participants[0] = ta.Alice.Address()
-	fp.Participants[1] = ta.Bob.Address()
-	asset := types.Address{}
-	left := cc.NewBalance(ta.Alice.Destination(), big.NewInt(6))
-	right := cc.NewBalance(ta.Bob.Destination(), big.NewInt(4))
-
-	existingGuarantee := cc.NewGuarantee(big.NewInt(1), types.Destination{1}, left.AsAllocation().Destination, right.AsAllocation().Destination)
-	outcome := cc.NewLedgerOutcome(asset, left, right, []cc.Guarantee{existingGuarantee})
-
-	initialVars := cc.Vars{Outcome: *outcome, TurnNum: 0}
-
-	aliceSig, _ := initialVars.AsState(fp).Sign(ta.Alice.PrivateKey)
-	bobsSig, _ := initialVars.AsState(fp).Sign(ta.Bob.PrivateKey)
-
-	leader, err := cc.NewLeaderChannel(
-		fp,
-		0,
-		*outcome,
-		[2]state.Signature{aliceSig, bobsSig})
-
-	if err != nil {
-		t.Fatal(err)
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+			sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+			ms := newStore(t, sk)
+
+			got, ok := ms.GetConsensusChannel(ta.Alice.Address())
+			if ok {
+				t.Fatalf("expected not to find the a consensus channel, but found %v", got)
+			}
+
+			fp := td.Objectives.Directfund.GenericDFO().C.FixedPart // TODO replace with testdata not nested under GenericDFO
+			fp.Participants[0] = ta.Alice.Address()
+			fp.Participants[1] = ta.Bob.Address()
+			asset := types.Address{}
+			left := cc.NewBalance(ta.Alice.Destination(), types.Funds{asset: big.NewInt(6)})
+			right := cc.NewBalance(ta.Bob.Destination(), types.Funds{asset: big.NewInt(4)})
+
+			existingGuarantee := cc.NewGuarantee(types.Funds{asset: big.NewInt(1)}, types.Destination{1}, left.AsAllocation(asset).Destination, right.AsAllocation(asset).Destination)
+			outcome := cc.NewLedgerOutcome([]types.Address{asset}, left, right, []cc.Guarantee{existingGuarantee})
+
+			initialVars := cc.Vars{Outcome: *outcome, TurnNum: 0}
+
+			aliceSig, _ := initialVars.AsState(fp).Sign(ta.Alice.PrivateKey)
+			bobsSig, _ := initialVars.AsState(fp).Sign(ta.Bob.PrivateKey)
+
+			leader, err := cc.NewLeaderChannel(
+				fp,
+				0,
+				*outcome,
+				[2]state.Signature{aliceSig, bobsSig})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Generate a new proposal so we test that the proposal queue is being fetched properly
+			proposedGuarantee := cc.NewGuarantee(types.Funds{asset: big.NewInt(1)}, types.Destination{2}, left.AsAllocation(asset).Destination, right.AsAllocation(asset).Destination)
+			proposal := cc.NewAddProposal(leader.Id, proposedGuarantee, types.Funds{asset: big.NewInt(1)})
+			_, err = leader.Propose(proposal, ta.Alice.PrivateKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The store only deals with ConsensusChannels
+			want := leader
+
+			if err := ms.SetConsensusChannel(&want); err != nil {
+				t.Fatalf("error setting consensus channel %v: %s", want, err.Error())
+			}
+
+			got, ok = ms.GetConsensusChannel(fp.Participants[1])
+
+			if !ok {
+				t.Fatalf("expected to find the inserted consensus channel, but didn't")
+			}
+
+			if got.Id != want.Id {
+				t.Fatalf("expected to retrieve same channel Id as was passed in, but didn't")
+			}
+
+			if diff := cmp.Diff(*got, want, cmp.AllowUnexported(cc.ConsensusChannel{}, big.Int{}, cc.LedgerOutcome{}, cc.Balance{}, cc.Guarantee{}, cc.Add{}, cc.Proposal{}, cc.Remove{})); diff != "" {
+				t.Fatalf("fetched result different than expected %s", diff)
+			}
+
+			all, err := ms.GetAllConsensusChannels()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 1 || all[0].Id != want.Id {
+				t.Fatalf("expected to retrieve only the inserted consensus channel, but got %v", all)
+			}
+		})
 	}
+}
 
-	// Generate a new proposal so we test that the proposal queue is being fetched properly
-	proposedGuarantee := cc.NewGuarantee(big.NewInt(1), types.Destination{2}, left.AsAllocation().Destination, right.AsAllocation().Destination)
-	proposal := cc.NewAddProposal(leader.Id, proposedGuarantee, big.NewInt(1))
-	_, err = leader.Propose(proposal, ta.Alice.PrivateKey)
-	if err != nil {
-		t.Fatal(err)
-	}
+func TestGetChannelsByParticipant(t *testing.T) {
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+			sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
 
-	// The store only deals with ConsensusChannels
-	want := leader
+			ms := newStore(t, sk)
+			c := td.Objectives.Directfund.GenericDFO().C
+			want := []*channel.Channel{c}
+			_ = ms.SetChannel(c)
 
-	if err := ms.SetConsensusChannel(&want); err != nil {
-		t.Fatalf("error setting consensus channel %v: %s", want, err.Error())
-	}
+			got := ms.GetChannelsByParticipant(c.Participants[0])
 
-	got, ok = ms.GetConsensusChannel(fp.Participants[1])
+			if diff := cmp.Diff(got, want, cmp.AllowUnexported(channel.Channel{}, big.Int{}, state.SignedState{})); diff != "" {
+				t.Fatalf("fetched result different than expected %s", diff)
+			}
 
-	if !ok {
-		t.Fatalf("expected to find the inserted consensus channel, but didn't")
-	}
-
-	if got.Id != want.Id {
-		t.Fatalf("expected to retrieve same channel Id as was passed in, but didn't")
+		})
 	}
+}
 
-	if diff := cmp.Diff(*got, want, cmp.AllowUnexported(cc.ConsensusChannel{}, big.Int{}, cc.LedgerOutcome{}, cc.Balance{}, cc.Guarantee{}, cc.Add{}, cc.Proposal{}, cc.Remove{})); diff != "" {
-		t.Fatalf("fetched result different than expected %s", diff)
+func TestVoucherStore(t *testing.T) {
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+			sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+			ms := newStore(t, sk)
+
+			channelId := types.Destination{1}
+			if _, ok := ms.GetVoucherInfo(channelId); ok {
+				t.Fatalf("expected not to find voucher info for channel %s", channelId)
+			}
+
+			voucher := payments.Voucher{ChannelId: channelId, Amount: big.NewInt(3)}
+			if err := voucher.Sign(ta.Alice.PrivateKey); err != nil {
+				t.Fatal(err)
+			}
+			want := payments.VoucherInfo{
+				ChannelPayer:    ta.Alice.Address(),
+				ChannelPayee:    ta.Bob.Address(),
+				StartingBalance: big.NewInt(10),
+				LargestVoucher:  voucher,
+			}
+			if err := ms.SetVoucherInfo(channelId, want); err != nil {
+				t.Fatal(err)
+			}
+
+			got, ok := ms.GetVoucherInfo(channelId)
+			if !ok {
+				t.Fatalf("expected to find voucher info for channel %s", channelId)
+			}
+			if diff := cmp.Diff(want, *got, cmp.AllowUnexported(big.Int{})); diff != "" {
+				t.Errorf("fetched result different than expected %s", diff)
+			}
+
+			if err := ms.RemoveVoucherInfo(channelId); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := ms.GetVoucherInfo(channelId); ok {
+				t.Errorf("expected voucher info for channel %s to have been removed", channelId)
+			}
+		})
 	}
 }
 
-func TestGetChannelsByParticipant(t *testing.T) {
-	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
-
-	ms := store.NewMemStore(sk)
-	c := td.Objectives.Directfund.GenericDFO().C
-	want := []*channel.Channel{c}
-	_ = ms.SetChannel(c)
-
-	got := ms.GetChannelsByParticipant(c.Participants[0])
-
-	if diff := cmp.Diff(got, want, cmp.AllowUnexported(channel.Channel{}, big.Int{}, state.SignedState{})); diff != "" {
-		t.Fatalf("fetched result different than expected %s", diff)
+func TestLastBlockNumSeen(t *testing.T) {
+	for name, newStore := range storeConstructors() {
+		t.Run(name, func(t *testing.T) {
+			sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+			ms := newStore(t, sk)
+
+			got, err := ms.GetLastBlockNumSeen()
+			if err != nil || got != 0 {
+				t.Fatalf("expected last block number seen 0 in an empty store, got %d (error %v)", got, err)
+			}
+
+			if err := ms.SetLastBlockNumSeen(42); err != nil {
+				t.Fatal(err)
+			}
+			got, err = ms.GetLastBlockNumSeen()
+			if err != nil || got != 42 {
+				t.Fatalf("expected last block number seen 42, got %d (error %v)", got, err)
+			}
+		})
 	}
-
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -23,6 +24,7 @@ type Store interface {
 	GetObjectiveById(protocols.ObjectiveId) (protocols.Objective, error)          // Read an existing objective
 	GetObjectiveByChannelId(types.Destination) (obj protocols.Objective, ok bool) // Get the objective that currently owns the channel with the supplied ChannelId
 	SetObjective(protocols.Objective) error                                       // Write an objective
+	GetApprovedObjectives() ([]protocols.Objective, error)                        // Read every objective which is approved but not yet completed or rejected
 
 	GetChannelById(id types.Destination) (c *channel.Channel, ok bool)
 	GetChannelsByParticipant(participant types.Address) []*channel.Channel // Returns any channels that includes the given participant
@@ -31,12 +33,17 @@ type Store interface {
 
 	ReleaseChannelFromOwnership(types.Destination) // Release channel from being owned by any objective
 
+	GetLastBlockNumSeen() (uint64, error) // Read the number of the latest block from which a chain event was processed (zero if none has been)
+	SetLastBlockNumSeen(uint64) error     // Write the number of the latest block from which a chain event was processed
+
 	ConsensusChannelStore
+	payments.VoucherStore
 }
 
 type ConsensusChannelStore interface {
 	GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool)
 	GetConsensusChannelById(id types.Destination) (channel *consensus_channel.ConsensusChannel, err error)
+	GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error) // Returns every ConsensusChannel in the store
 	SetConsensusChannel(*consensus_channel.ConsensusChannel) error
 	DestroyConsensusChannel(id types.Destination)
 }
@@ -6,3 +6,4 @@ var alice = testactors.Alice
 var bob = testactors.Bob
 var irene = testactors.Irene
 var brian = testactors.Brian
+var ivan = testactors.Ivan
@@ -0,0 +1,90 @@
+package client_test
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+)
+
+// TestChallenge checks that Alice can recover the funds in a ledger channel without Bob's cooperation, by challenging on chain.
+func TestChallenge(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_challenge.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	// Setup chain service
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	chainA := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	chainB := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	// End chain service setup
+
+	broker := messageservice.NewBroker()
+
+	clientA, storeA := setupClient(alice.PrivateKey, chainA, broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chainB, broker, logDestination, 0)
+
+	channelId := directlyFundALedgerChannel(t, clientA, clientB)
+
+	// Alice does not need Bob to respond
+	id := clientA.ChallengeChannel(channelId)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, id)
+
+	// Alice's ledger channel is replaced by a regular channel
+	c, ok := storeA.GetChannelById(channelId)
+	if !ok {
+		t.Fatalf("expected a Channel to have been created")
+	}
+	if _, err := storeA.GetConsensusChannelById(channelId); err == nil {
+		t.Fatalf("expected ConsensusChannel to have been destroyed")
+	}
+	if c.OnChainFunding.IsNonZero() {
+		t.Fatalf("expected zero on chain funding, but got %v", c.OnChainFunding)
+	}
+
+	// Every asset has been paid out by the adjudicator
+	holdings, err := bindings.Adjudicator.Contract.Holdings(&bind.CallOpts{}, common.Address{}, channelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holdings.Sign() != 0 {
+		t.Fatalf("expected the adjudicator to hold nothing for channel %s, but it holds %s", channelId, holdings)
+	}
+}
+
+// TestChallengeMockChain checks that Alice can recover the funds in a ledger channel by challenging on the MockChain.
+func TestChallengeMockChain(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_challenge.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientA, storeA := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+
+	channelId := directlyFundALedgerChannel(t, clientA, clientB)
+
+	id := clientA.ChallengeChannel(channelId)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, id)
+
+	c, ok := storeA.GetChannelById(channelId)
+	if !ok {
+		t.Fatalf("expected a Channel to have been created")
+	}
+	if c.OnChainFunding.IsNonZero() {
+		t.Fatalf("expected zero on chain funding, but got %v", c.OnChainFunding)
+	}
+}
@@ -86,7 +86,7 @@ func TestDirectDefund(t *testing.T) {
 		outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), 1, 1)
 		request := virtualfund.ObjectiveRequest{
 			CounterParty:      bob.Address(),
-			Intermediary:      irene.Address(),
+			Intermediaries:    []types.Address{irene.Address()},
 			Outcome:           outcome,
 			AppDefinition:     types.Address{},
 			AppData:           types.Bytes{},
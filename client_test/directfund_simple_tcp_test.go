@@ -9,6 +9,7 @@ import (
 	"github.com/statechannels/go-nitro/client"
 	"github.com/statechannels/go-nitro/client/engine"
 	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
 	simpletcp "github.com/statechannels/go-nitro/client/engine/messageservice/simple-tcp"
 	"github.com/statechannels/go-nitro/client/engine/store"
 	"github.com/statechannels/go-nitro/crypto"
@@ -18,9 +19,9 @@ import (
 // setupClientWithSimpleTCP is a helper function that contructs a client and returns the new client and its store.
 func setupClientWithSimpleTCP(pk []byte, chain *chainservice.MockChain, peers map[types.Address]string, logDestination io.Writer, meanMessageDelay time.Duration) (client.Client, *simpletcp.SimpleTCPMessageService) {
 	myAddress := crypto.GetAddressFromSecretKeyBytes(pk)
-	messageservice := simpletcp.NewSimpleTCPMessageService(peers[myAddress], peers)
+	messageservice := simpletcp.NewSimpleTCPMessageService(myAddress, peers[myAddress], peers)
 	storeA := store.NewMemStore(pk)
-	return client.New(messageservice, chain, storeA, logDestination, &engine.PermissivePolicy{}, nil), messageservice
+	return mustNewClient(messageservice, chain, storeA, logDestination, &engine.PermissivePolicy{}), messageservice
 }
 
 func TestSimpleTCPMessageService(t *testing.T) {
@@ -43,3 +44,39 @@ func TestSimpleTCPMessageService(t *testing.T) {
 	directlyFundALedgerChannel(t, clientA, clientB)
 
 }
+
+// TestSimpleTCPMessageServiceWithHello checks that a client can reach a peer which only learns the client's url from the hello it sends.
+func TestSimpleTCPMessageServiceWithHello(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_direct_fund_with_simple_tcp_hello.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+
+	aliceUrl, bobUrl := "localhost:3011", "localhost:3012"
+	clientA, msA := setupClientWithSimpleTCP(alice.PrivateKey, chain, map[types.Address]string{alice.Address(): aliceUrl, bob.Address(): bobUrl}, logDestination, 0)
+	clientB, msB := setupClientWithSimpleTCP(bob.PrivateKey, chain, map[types.Address]string{bob.Address(): bobUrl}, logDestination, 0)
+	defer msA.Close()
+	defer msB.Close()
+
+	if err := messageservice.SendHello(msA, bob.Address(), aliceUrl, alice.PrivateKey); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		if url, ok := msB.AddressBook().Lookup(alice.Address()); ok {
+			if url != aliceUrl {
+				t.Fatalf("expected bob to register alice at %s, got %s", aliceUrl, url)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected bob to register alice's hello within %s", defaultTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	directlyFundALedgerChannel(t, clientA, clientB)
+}
@@ -22,6 +22,11 @@ import (
 const ledgerChannelDeposit = 5_000_000
 
 func directlyFundALedgerChannel(t *testing.T, alpha client.Client, beta client.Client) types.Destination {
+	return directlyFundALedgerChannelWithChallengeDuration(t, alpha, beta, 0)
+}
+
+// directlyFundALedgerChannelWithChallengeDuration funds a ledger channel whose challenges finalize after challengeDuration seconds.
+func directlyFundALedgerChannelWithChallengeDuration(t *testing.T, alpha client.Client, beta client.Client, challengeDuration int64) types.Destination {
 	// Set up an outcome that requires both participants to deposit
 	outcome := testdata.Outcomes.Create(*alpha.Address, *beta.Address, ledgerChannelDeposit, ledgerChannelDeposit)
 
@@ -30,7 +35,7 @@ func directlyFundALedgerChannel(t *testing.T, alpha client.Client, beta client.C
 		Outcome:           outcome,
 		AppDefinition:     types.Address{},
 		AppData:           types.Bytes{},
-		ChallengeDuration: big.NewInt(0),
+		ChallengeDuration: big.NewInt(challengeDuration),
 		Nonce:             int64(rand.Int31()),
 	}
 	response := alpha.CreateDirectChannel(request)
@@ -65,7 +70,11 @@ func TestWhenObjectiveIsRejected(t *testing.T) {
 	{
 		messageservice := messageservice.NewTestMessageService(bob.Address(), broker, meanMessageDelay)
 		storeB = store.NewMemStore(bob.PrivateKey)
-		clientB = client.New(messageservice, chain, storeB, logDestination, &RejectingPolicyMaker{}, nil)
+		var err error
+		clientB, err = client.New(messageservice, chain, storeB, logDestination, &RejectingPolicyMaker{}, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
 	}
 
 	outcome := testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit)
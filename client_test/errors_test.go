@@ -0,0 +1,401 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestBadMessageDoesNotCrashClient checks that a message which cannot be handled fails the objective it is addressed to,
+// is reported on the client's Errors chan, and leaves the client able to run other objectives.
+func TestBadMessageDoesNotCrashClient(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_bad_message.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+
+	// Brian sends Bob a proposal for an objective Bob has never heard of
+	brianMessageService := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+	target := types.Destination{1}
+	proposal := consensus_channel.NewAddProposal(types.Destination{2}, consensus_channel.NewGuarantee(types.Funds{types.Address{}: big.NewInt(1)}, target, alice.Destination(), bob.Destination()), types.Funds{types.Address{}: big.NewInt(1)})
+	msg, err := protocols.CreateSignedProposalMessage(bob.Address(), consensus_channel.SignedProposal{Proposal: proposal, TurnNum: 2}).Sign(brian.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = brianMessageService.Send(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantFailed := protocols.ObjectiveId(virtualfund.ObjectivePrefix + target.String())
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected objective %s to fail, but no failures occurred within %s", wantFailed, defaultTimeout)
+	case failed := <-clientB.FailedObjectives():
+		if failed != wantFailed {
+			t.Errorf("expected objective %s to fail, but %s failed", wantFailed, failed)
+		}
+	}
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientB.Errors():
+		var oe *engine.ObjectiveError
+		if !errors.As(err, &oe) {
+			t.Fatalf("expected an *engine.ObjectiveError, but got %T: %v", err, err)
+		}
+		if oe.ObjectiveId != wantFailed {
+			t.Errorf("expected error to identify objective %s, but it identified %s", wantFailed, oe.ObjectiveId)
+		}
+	}
+
+	// Bob should still be able to fund a channel with Alice
+	directlyFundALedgerChannel(t, clientA, clientB)
+}
+
+// TestStateForWrongChainIsRejected checks that a client rejects an objective proposed with states for a chain other than its own.
+func TestStateForWrongChainIsRejected(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_wrong_chain.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chainservice.NewMockChain(), broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chainservice.NewMockChainWithChainID(big.NewInt(9001)), broker, logDestination, 0)
+
+	request := directfund.ObjectiveRequest{
+		CounterParty:      bob.Address(),
+		Outcome:           testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             rand.Int63(),
+	}
+	clientA.CreateDirectChannel(request)
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientB.Errors():
+		if !errors.Is(err, engine.ErrWrongChain) {
+			t.Fatalf("expected an error wrapping %v, but got %v", engine.ErrWrongChain, err)
+		}
+	}
+}
+
+// unreachableChain is a chain service which cannot report its chain id, as though its node could not be reached.
+type unreachableChain struct {
+	*chainservice.MockChain
+}
+
+var errUnreachableChain = errors.New("chain unreachable")
+
+func (unreachableChain) ChainID() (*big.Int, error) {
+	return nil, errUnreachableChain
+}
+
+// TestClientForUnreachableChainIsNotConstructed checks that a client is not constructed if its chain service cannot report the chain id.
+func TestClientForUnreachableChainIsNotConstructed(t *testing.T) {
+	chain := unreachableChain{chainservice.NewMockChain()}
+	msg := messageservice.NewTestMessageService(alice.Address(), messageservice.NewBroker(), 0)
+
+	_, err := client.New(msg, chain, store.NewMemStore(alice.PrivateKey), io.Discard, &engine.PermissivePolicy{}, nil, nil)
+	if !errors.Is(err, errUnreachableChain) {
+		t.Fatalf("expected an error wrapping %v, but got %v", errUnreachableChain, err)
+	}
+}
+
+// TestUnsignedMessageIsRejected checks that a client rejects a message which is not signed by its sender.
+func TestUnsignedMessageIsRejected(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_unsigned_message.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	broker := messageservice.NewBroker()
+	clientB, _ := setupClient(bob.PrivateKey, chainservice.NewMockChain(), broker, logDestination, 0)
+
+	brianMessageService := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+	msg := protocols.CreateSignedStateMessages(protocols.ObjectiveId(directfund.ObjectivePrefix+"0x01"), state.NewSignedState(stateWithParticipants(alice.Address(), bob.Address())), 0)[0]
+	msg.From = alice.Address()
+	if err := brianMessageService.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientB.Errors():
+		if !errors.Is(err, engine.ErrUnsignedMessage) {
+			t.Fatalf("expected an error wrapping %v, but got %v", engine.ErrUnsignedMessage, err)
+		}
+	}
+}
+
+// inboxMessageService hands messages pushed to its inbox straight to the client, without serializing them as a real message service would.
+// Messages the client sends are discarded.
+type inboxMessageService struct {
+	inbox chan protocols.Message
+}
+
+func (s inboxMessageService) Out() <-chan protocols.Message {
+	return s.inbox
+}
+
+func (s inboxMessageService) Send(protocols.Message) error {
+	return nil
+}
+
+// TestMessageSignedByAnotherIsRejected checks that a client rejects a message which claims to be from one participant, but is signed by someone else.
+// Message services reject such messages when deserializing them, so the message is handed to the client directly.
+func TestMessageSignedByAnotherIsRejected(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_message_signed_by_another.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	msgService := inboxMessageService{make(chan protocols.Message, 1)}
+	storeB := store.NewMemStore(bob.PrivateKey)
+	clientB := mustNewClient(msgService, chainservice.NewMockChain(), storeB, logDestination, &engine.PermissivePolicy{})
+
+	// Brian signs a message, then claims it is from alice
+	s := stateWithParticipants(alice.Address(), bob.Address())
+	id := protocols.ObjectiveId(directfund.ObjectivePrefix + s.ChannelId().String())
+	msg, err := protocols.CreateSignedStateMessages(id, state.NewSignedState(s), 0)[0].Sign(brian.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.From = alice.Address()
+	msgService.inbox <- msg
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientB.Errors():
+		if !errors.Is(err, engine.ErrUnsignedMessage) {
+			t.Fatalf("expected an error wrapping %v, but got %v", engine.ErrUnsignedMessage, err)
+		}
+	}
+	if _, err := storeB.GetObjectiveById(id); err == nil {
+		t.Errorf("expected no objective to be created for the rejected message")
+	}
+}
+
+// TestStateForUnrelatedChannelIsRejected checks that a client ignores a state addressed to an objective which does not own the state's channel,
+// rather than failing the objective.
+func TestStateForUnrelatedChannelIsRejected(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_state_for_unrelated_channel.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	broker := messageservice.NewBroker()
+	clientB, storeB := setupClient(bob.PrivateKey, chainservice.NewMockChain(), broker, logDestination, 0)
+	aliceMessageService := messageservice.NewTestMessageService(alice.Address(), broker, 0)
+
+	send := func(id protocols.ObjectiveId, s state.State) {
+		ss := state.NewSignedState(s)
+		sig, err := s.Sign(alice.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ss.AddSignature(sig); err != nil {
+			t.Fatal(err)
+		}
+		msg, err := protocols.CreateSignedStateMessages(id, ss, 0)[0].Sign(alice.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := aliceMessageService.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Alice proposes a channel with bob, then sends a state for another of their channels to the same objective
+	s := stateWithParticipants(alice.Address(), bob.Address())
+	id := protocols.ObjectiveId(directfund.ObjectivePrefix + s.ChannelId().String())
+	send(id, s)
+	waitForObjective := time.Now().Add(defaultTimeout)
+	for {
+		if _, err := storeB.GetObjectiveById(id); err == nil {
+			break
+		}
+		if time.Now().After(waitForObjective) {
+			t.Fatalf("expected objective %s to be created within %s", id, defaultTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	send(id, stateWithParticipants(alice.Address(), bob.Address()))
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientB.Errors():
+		if !errors.Is(err, engine.ErrUnrelatedChannel) {
+			t.Fatalf("expected an error wrapping %v, but got %v", engine.ErrUnrelatedChannel, err)
+		}
+	}
+	objective, err := storeB.GetObjectiveById(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := objective.GetStatus(); status == protocols.Failed {
+		t.Errorf("expected objective %s not to be failed", id)
+	}
+}
+
+// TestPayloadFromNonParticipantIsRejected checks that a client ignores a payload for an objective in which the sender is not a participant,
+// rather than creating (or failing) the objective.
+func TestPayloadFromNonParticipantIsRejected(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_payload_from_non_participant.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	broker := messageservice.NewBroker()
+	clientB, storeB := setupClient(bob.PrivateKey, chainservice.NewMockChain(), broker, logDestination, 0)
+
+	// Brian proposes a channel between alice and bob, signing the message with brian's key
+	brianMessageService := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+	s := stateWithParticipants(alice.Address(), bob.Address())
+	ss := state.NewSignedState(s)
+	sig, err := s.Sign(alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.AddSignature(sig); err != nil {
+		t.Fatal(err)
+	}
+	id := protocols.ObjectiveId(directfund.ObjectivePrefix + s.ChannelId().String())
+	msg, err := protocols.CreateSignedStateMessages(id, ss, 0)[0].Sign(brian.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := brianMessageService.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientB.Errors():
+		if !errors.Is(err, engine.ErrNotParticipant) {
+			t.Fatalf("expected an error wrapping %v, but got %v", engine.ErrNotParticipant, err)
+		}
+	}
+	if _, err := storeB.GetObjectiveById(id); err == nil {
+		t.Errorf("expected no objective to be created for the rejected state")
+	}
+}
+
+// TestMalformedStateDoesNotCrashClient checks that a client reports a state whose outcome is missing an amount, rather than panicking while handling it.
+func TestMalformedStateDoesNotCrashClient(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_malformed_state.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientB, _ := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+
+	// Alice sends Bob a state whose outcome is missing an amount. Her client is started afterwards, and takes over her address on the broker
+	aliceMessageService := messageservice.NewTestMessageService(alice.Address(), broker, 0)
+	s := stateWithParticipants(alice.Address(), bob.Address())
+	s.Outcome[0].Allocations[1].Amount = nil
+	id := protocols.ObjectiveId(directfund.ObjectivePrefix + s.ChannelId().String())
+	msg, err := protocols.CreateSignedStateMessages(id, state.NewSignedState(s), 0)[0].Sign(alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := aliceMessageService.Send(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientB.Errors():
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+	}
+
+	// Bob should still be able to fund a channel with Alice
+	clientA, _ := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	directlyFundALedgerChannel(t, clientA, clientB)
+}
+
+// TestUnreadErrorsDoNotBlockClient checks that a client keeps running objectives when the consuming application does not read its Errors chan.
+func TestUnreadErrorsDoNotBlockClient(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_unread_errors.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	clientB, _ := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+
+	// Brian sends Bob more unsigned messages than the client and engine buffer errors for
+	brianMessageService := messageservice.NewTestMessageService(brian.Address(), broker, 0)
+	msg := protocols.CreateSignedStateMessages(protocols.ObjectiveId(directfund.ObjectivePrefix+"0x01"), state.NewSignedState(stateWithParticipants(alice.Address(), bob.Address())), 0)[0]
+	for i := 0; i < 250; i++ {
+		if err := brianMessageService.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Bob should still be able to fund a channel with Alice
+	directlyFundALedgerChannel(t, clientA, clientB)
+}
+
+// stateWithParticipants returns a prefund state for a directly funded channel between the participants.
+func stateWithParticipants(participants ...types.Address) state.State {
+	return state.State{
+		ChainId:           big.NewInt(1337),
+		Participants:      participants,
+		ChannelNonce:      big.NewInt(rand.Int63()),
+		AppDefinition:     types.Address{},
+		ChallengeDuration: big.NewInt(0),
+		AppData:           types.Bytes{},
+		Outcome:           testdata.Outcomes.Create(participants[0], participants[1], ledgerChannelDeposit, ledgerChannelDeposit),
+		TurnNum:           0,
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/statechannels/go-nitro/client/engine/store"
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
 )
 
 const defaultTimeout = 10 * time.Second
@@ -65,12 +66,41 @@ func waitTimeForCompletedObjectiveIds(t *testing.T, client *client.Client, timeo
 	}
 }
 
+// waitTimeForReceivedVouchers waits up to the given timeout for the client to receive a voucher on each of the given channels.
+// If the timeout lapses and vouchers have not been received on every channel, the parent test will be failed.
+func waitTimeForReceivedVouchers(t *testing.T, client *client.Client, timeout time.Duration, channelIds ...types.Destination) {
+	received := make(map[types.Destination]bool)
+	deadline := time.After(timeout)
+
+	for len(received) < len(channelIds) {
+		select {
+		case <-deadline:
+			t.Fatalf("Client %s received vouchers on %d of %d channels within %s", client.Address, len(received), len(channelIds), timeout)
+		case voucher := <-client.ReceivedVouchers():
+			for _, id := range channelIds {
+				if voucher.ChannelId == id {
+					received[id] = true
+				}
+			}
+		}
+	}
+}
+
 // setupClient is a helper function that contructs a client and returns the new client and its store.
 func setupClient(pk []byte, chain chainservice.ChainService, msgBroker messageservice.Broker, logDestination io.Writer, meanMessageDelay time.Duration) (client.Client, store.Store) {
 	myAddress := crypto.GetAddressFromSecretKeyBytes(pk)
 	messageservice := messageservice.NewTestMessageService(myAddress, msgBroker, meanMessageDelay)
 	storeA := store.NewMemStore(pk)
-	return client.New(messageservice, chain, storeA, logDestination, &engine.PermissivePolicy{}, nil), storeA
+	return mustNewClient(messageservice, chain, storeA, logDestination, &engine.PermissivePolicy{}), storeA
+}
+
+// mustNewClient constructs a client for helpers which are not passed a *testing.T, and panics if the client cannot be constructed.
+func mustNewClient(msg messageservice.MessageService, chain chainservice.ChainService, store store.Store, logDestination io.Writer, policymaker engine.PolicyMaker) client.Client {
+	c, err := client.New(msg, chain, store, logDestination, policymaker, nil, nil)
+	if err != nil {
+		panic(err)
+	}
+	return c
 }
 
 func truncateLog(logFile string) {
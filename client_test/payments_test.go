@@ -0,0 +1,159 @@
+package client_test
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestPayments checks that Alice can stream payments to Bob over a virtual channel, and that the channel is defunded according to those payments when Bob closes it.
+func TestPayments(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_payments.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	clientB, storeB := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+	clientI, _ := setupClient(irene.PrivateKey, chain, broker, logDestination, 0)
+
+	directlyFundALedgerChannel(t, clientA, clientI)
+	directlyFundALedgerChannel(t, clientI, clientB)
+
+	request := virtualfund.ObjectiveRequest{
+		CounterParty:      bob.Address(),
+		Intermediaries:    []types.Address{irene.Address()},
+		Outcome:           td.Outcomes.Create(alice.Address(), bob.Address(), 10, 0),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             rand.Int63(),
+	}
+	response := clientA.CreateVirtualChannel(request)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, response.Id)
+
+	// Each voucher Bob receives is for the total paid so far
+	totalPaid := int64(0)
+	for _, amount := range []int64{1, 2, 3} {
+		clientA.Pay(response.ChannelId, big.NewInt(amount))
+		totalPaid += amount
+
+		select {
+		case <-time.After(defaultTimeout):
+			t.Fatalf("expected bob to receive a voucher within %s", defaultTimeout)
+		case voucher := <-clientB.ReceivedVouchers():
+			if voucher.ChannelId != response.ChannelId {
+				t.Errorf("expected a voucher for channel %s, but got one for %s", response.ChannelId, voucher.ChannelId)
+			}
+			if voucher.Amount.Cmp(big.NewInt(totalPaid)) != 0 {
+				t.Errorf("expected a voucher for a total of %d, but got %s", totalPaid, voucher.Amount)
+			}
+		}
+	}
+
+	// Alice cannot pay more than she deposited in the channel
+	clientA.Pay(response.ChannelId, big.NewInt(5))
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientA.Errors():
+		if !errors.Is(err, payments.ErrInsufficientFunds) {
+			t.Errorf("expected %v, but got %v", payments.ErrInsufficientFunds, err)
+		}
+	}
+
+	// Bob closes the channel, claiming the largest voucher he has received
+	id := clientB.CloseVirtualChannel(response.ChannelId)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, id)
+
+	o, err := storeB.GetObjectiveById(protocols.ObjectiveId(virtualdefund.ObjectivePrefix + response.ChannelId.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdfo := o.(*virtualdefund.Objective)
+	checkIreneBobLedgerOutcome(t, vdfo.VId(), vdfo.ToMyLeft.ConsensusVars().Outcome, uint(totalPaid))
+}
+
+// TestUnreadVouchersDoNotBlockClient checks that a client keeps receiving payments when the consuming application does not read its ReceivedVouchers chan.
+func TestUnreadVouchersDoNotBlockClient(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_unread_vouchers.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	clientB, storeB := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+	clientI, _ := setupClient(irene.PrivateKey, chain, broker, logDestination, 0)
+
+	directlyFundALedgerChannel(t, clientA, clientI)
+	directlyFundALedgerChannel(t, clientI, clientB)
+
+	request := virtualfund.ObjectiveRequest{
+		CounterParty:      bob.Address(),
+		Intermediaries:    []types.Address{irene.Address()},
+		Outcome:           td.Outcomes.Create(alice.Address(), bob.Address(), 300, 0),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             rand.Int63(),
+	}
+	response := clientA.CreateVirtualChannel(request)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, response.Id)
+
+	// Alice makes more payments than the client and engine buffer vouchers for, none of which are read by Bob's application
+	const paymentCount = 250
+	for i := 0; i < paymentCount; i++ {
+		clientA.Pay(response.ChannelId, big.NewInt(1))
+	}
+
+	// Bob's client should receive every payment
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		info, ok := storeB.GetVoucherInfo(response.ChannelId)
+		if ok && info.Paid().Cmp(big.NewInt(paymentCount)) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected bob to receive %d payments within %s", paymentCount, defaultTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Bob can still close the channel, and is paid in full
+	id := clientB.CloseVirtualChannel(response.ChannelId)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, id)
+
+	o, err := storeB.GetObjectiveById(protocols.ObjectiveId(virtualdefund.ObjectivePrefix + response.ChannelId.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vdfo := o.(*virtualdefund.Objective)
+	checkIreneBobLedgerOutcome(t, vdfo.VId(), vdfo.ToMyLeft.ConsensusVars().Outcome, paymentCount)
+}
@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestResumeObjectiveAfterRestart checks that an objective which is in flight when a client stops is completed once the client is restarted.
+func TestResumeObjectiveAfterRestart(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_resume_after_restart.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+	dataFolder := t.TempDir()
+
+	// Bob is offline: messages addressed to him are accepted, but never processed
+	messageservice.NewTestMessageService(bob.Address(), broker, 0)
+
+	storeA, err := store.NewDurableStore(alice.PrivateKey, dataFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientA, err := client.New(messageservice.NewTestMessageService(alice.Address(), broker, 0), chain, storeA, logDestination, &engine.PermissivePolicy{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := directfund.ObjectiveRequest{
+		CounterParty:      bob.Address(),
+		Outcome:           testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             rand.Int63(),
+	}
+	response := clientA.CreateDirectChannel(request)
+
+	// Wait for Alice to sign and send her prefund state, which Bob never receives
+	waitForObjectiveToBeStored(t, storeA, response.Id)
+
+	// Simulate Alice crashing by closing her store, and then restarting her from the same data folder
+	if err := storeA.Close(); err != nil {
+		t.Fatal(err)
+	}
+	restartedStoreA, err := store.NewDurableStore(alice.PrivateKey, dataFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer restartedStoreA.Close()
+
+	// Bob comes online
+	clientB, _ := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+
+	restartedClientA, err := client.New(messageservice.NewTestMessageService(alice.Address(), broker, 0), chain, restartedStoreA, logDestination, &engine.PermissivePolicy{}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitTimeForCompletedObjectiveIds(t, &restartedClientA, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, response.Id)
+}
+
+// waitForObjectiveToBeStored polls the store until the objective with the given id is found, failing the test if this does not happen within the default timeout.
+func waitForObjectiveToBeStored(t *testing.T, s store.Store, id protocols.ObjectiveId) {
+	deadline := time.Now().Add(defaultTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := s.GetObjectiveById(id); err == nil {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Objective %s was not stored within %s", id, defaultTimeout)
+}
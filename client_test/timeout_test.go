@@ -0,0 +1,92 @@
+package client_test
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestObjectiveTimeout checks that an objective which stalls because the counterparty is unresponsive is failed once its timeout has elapsed.
+func TestObjectiveTimeout(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_objective_timeout.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	// Bob is unresponsive: messages addressed to him are accepted, but never processed
+	messageservice.NewTestMessageService(bob.Address(), broker, 0)
+
+	timeout := 200 * time.Millisecond
+	storeA := store.NewMemStore(alice.PrivateKey)
+	clientA, err := client.New(
+		messageservice.NewTestMessageService(alice.Address(), broker, 0),
+		chain,
+		storeA,
+		logDestination,
+		&engine.PermissivePolicy{},
+		nil,
+		engine.ObjectiveTimeouts{directfund.ObjectivePrefix: timeout},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := directfund.ObjectiveRequest{
+		CounterParty:      bob.Address(),
+		Outcome:           testdata.Outcomes.Create(alice.Address(), bob.Address(), ledgerChannelDeposit, ledgerChannelDeposit),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             rand.Int63(),
+	}
+	start := time.Now()
+	response := clientA.CreateDirectChannel(request)
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected objective %s to time out, but no failures occurred within %s", response.Id, defaultTimeout)
+	case failed := <-clientA.FailedObjectives():
+		if failed != response.Id {
+			t.Fatalf("expected objective %s to fail, but %s failed", response.Id, failed)
+		}
+		if elapsed := time.Since(start); elapsed < timeout {
+			t.Errorf("expected objective to fail after %s, but it failed after %s", timeout, elapsed)
+		}
+	}
+
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected a reason for the failure to be reported within %s", defaultTimeout)
+	case err := <-clientA.Errors():
+		if !errors.Is(err, engine.ErrObjectiveTimedOut) {
+			t.Errorf("expected the objective to have failed with %v, but got %v", engine.ErrObjectiveTimedOut, err)
+		}
+	}
+
+	obj, err := storeA.GetObjectiveById(response.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj.GetStatus() != protocols.Failed {
+		t.Errorf("expected objective to be stored as failed, but its status is %v", obj.GetStatus())
+	}
+	if _, owned := storeA.GetObjectiveByChannelId(response.ChannelId); owned {
+		t.Errorf("expected channel %s to have been released by the failed objective", response.ChannelId)
+	}
+}
@@ -58,9 +58,14 @@ func runVirtualDefundIntegrationTest(t *testing.T, messageDelay time.Duration, o
 
 	cIds := openVirtualChannels(t, clientA, clientB, clientI, numOfVirtualChannels)
 
+	for _, cId := range cIds {
+		clientA.Pay(cId, big.NewInt(int64(paidToBob)))
+	}
+	waitTimeForReceivedVouchers(t, &clientB, objectiveTimeout, cIds...)
+
 	ids := make([]protocols.ObjectiveId, len(cIds))
 	for i := 0; i < len(cIds); i++ {
-		ids[i] = clientA.CloseVirtualChannel(cIds[i], big.NewInt(int64(paidToBob)))
+		ids[i] = clientA.CloseVirtualChannel(cIds[i])
 
 	}
 	waitTimeForCompletedObjectiveIds(t, &clientA, objectiveTimeout, ids...)
@@ -94,17 +99,66 @@ func runVirtualDefundIntegrationTest(t *testing.T, messageDelay time.Duration, o
 	}
 }
 
+// TestVirtualDefundByIntermediary checks that an intermediary which closes a virtual channel agrees to the final state proposed by the payee.
+func TestVirtualDefundByIntermediary(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_virtual_defund_by_intermediary.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientA, _ := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	clientB, storeB := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+	clientI, storeI := setupClient(irene.PrivateKey, chain, broker, logDestination, 0)
+
+	cId := openVirtualChannels(t, clientA, clientB, clientI, 1)[0]
+	clientA.Pay(cId, big.NewInt(1))
+	waitTimeForReceivedVouchers(t, &clientB, defaultTimeout, cId)
+
+	// Irene closes the channel first, but cannot sign the final state until bob proposes it
+	id := clientI.CloseVirtualChannel(cId)
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		if _, err := storeI.GetObjectiveById(id); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected irene to start objective %s within %s", id, defaultTimeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	clientB.CloseVirtualChannel(cId)
+
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, id)
+
+	o, err := storeB.GetObjectiveById(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIreneBobLedgerOutcome(t, cId, o.(*virtualdefund.Objective).ToMyLeft.ConsensusVars().Outcome, 1)
+	o, err = storeI.GetObjectiveById(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIreneBobLedgerOutcome(t, cId, o.(*virtualdefund.Objective).ToMyRight.ConsensusVars().Outcome, 1)
+}
+
 // checkAliceIreneLedgerOutcome checks the ledger outcome between alice and irene is as expected
 func checkAliceIreneLedgerOutcome(t *testing.T, vId types.Destination, outcome consensus_channel.LedgerOutcome, totalPaidToBob uint) {
 	if outcome.IncludesTarget(vId) {
 		t.Errorf("The outcome %+v should not contain a guarantee for the virtual channel %s", outcome, vId)
 	}
-	expectedLeaderBalance := consensus_channel.NewBalance(alice.Destination(), big.NewInt(int64(ledgerChannelDeposit-totalPaidToBob)))
+	expectedLeaderBalance := consensus_channel.NewBalance(alice.Destination(), types.Funds{types.Address{}: big.NewInt(int64(ledgerChannelDeposit - totalPaidToBob))})
 	if diff := cmp.Diff(expectedLeaderBalance, outcome.Leader()); diff != "" {
 		t.Errorf("Unexpected leader balance: %s", diff)
 	}
 
-	expectedFollowerBalance := consensus_channel.NewBalance(irene.Destination(), big.NewInt(int64(ledgerChannelDeposit+totalPaidToBob)))
+	expectedFollowerBalance := consensus_channel.NewBalance(irene.Destination(), types.Funds{types.Address{}: big.NewInt(int64(ledgerChannelDeposit + totalPaidToBob))})
 	if diff := cmp.Diff(expectedFollowerBalance, outcome.Follower()); diff != "" {
 		t.Errorf("Unexpected follower balance: %s", diff)
 	}
@@ -115,12 +169,12 @@ func checkIreneBobLedgerOutcome(t *testing.T, vId types.Destination, outcome con
 	if outcome.IncludesTarget(vId) {
 		t.Errorf("The outcome %+v should not contain a guarantee for the virtual channel %s", outcome, vId)
 	}
-	expectedLeaderBalance := consensus_channel.NewBalance(irene.Destination(), big.NewInt(int64(ledgerChannelDeposit-totalPaidToBob)))
+	expectedLeaderBalance := consensus_channel.NewBalance(irene.Destination(), types.Funds{types.Address{}: big.NewInt(int64(ledgerChannelDeposit - totalPaidToBob))})
 	if diff := cmp.Diff(expectedLeaderBalance, outcome.Leader()); diff != "" {
 		t.Errorf("Unexpected leader balance: %s", diff)
 	}
 
-	expectedFollowerBalance := consensus_channel.NewBalance(bob.Destination(), big.NewInt(int64(ledgerChannelDeposit+totalPaidToBob)))
+	expectedFollowerBalance := consensus_channel.NewBalance(bob.Destination(), types.Funds{types.Address{}: big.NewInt(int64(ledgerChannelDeposit + totalPaidToBob))})
 	if diff := cmp.Diff(expectedFollowerBalance, outcome.Follower()); diff != "" {
 		t.Errorf("Unexpected follower balance: %s", diff)
 	}
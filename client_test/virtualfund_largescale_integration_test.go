@@ -75,24 +75,33 @@ func TestLargeScaleVirtualFundIntegration(t *testing.T) {
 	// Switch to instrumented clients
 	chain = chainservice.NewMockChain()
 	broker = messageservice.NewBroker()
-	retrievalProvider = client.New(
+	var err error
+	retrievalProvider, err = client.New(
 		messageservice.NewVectorClockTestMessageService(bob.Address(), broker, 0, vectorClockLogDir),
 		chain,
 		retrievalProviderStore,
 		logDestination,
 		&engine.PermissivePolicy{},
 		nil,
+		nil,
 	)
-	paymentHub = client.New(
+	if err != nil {
+		t.Fatal(err)
+	}
+	paymentHub, err = client.New(
 		messageservice.NewVectorClockTestMessageService(irene.Address(), broker, 0, vectorClockLogDir),
 		chain,
 		paymentHubStore,
 		logDestination,
 		&engine.PermissivePolicy{},
 		nil,
+		nil,
 	)
+	if err != nil {
+		t.Fatal(err)
+	}
 	for i := range retrievalClients {
-		retrievalClients[i] =
+		retrievalClients[i], err =
 			client.New(
 				messageservice.NewVectorClockTestMessageService(*retrievalClients[i].Address, broker, 0, vectorClockLogDir),
 				chain,
@@ -100,7 +109,11 @@ func TestLargeScaleVirtualFundIntegration(t *testing.T) {
 				logDestination,
 				&engine.PermissivePolicy{},
 				nil,
+				nil,
 			)
+		if err != nil {
+			t.Fatal(err)
+		}
 	}
 
 	// All Retrieval Clients try to start a virtual channel with the retrievalProvider, through the Payment Hub
@@ -185,8 +198,8 @@ func prettify(t *testing.T, logDir string, combinedLogsFilename string, prettyPr
 
 func createVirtualChannelWithRetrievalProvider(c client.Client, retrievalProvider client.Client, prettyPrintDict *safesync.Map[string], i int) {
 	withRetrievalProvider := virtualfund.ObjectiveRequest{
-		CounterParty: *retrievalProvider.Address,
-		Intermediary: irene.Address(),
+		CounterParty:   *retrievalProvider.Address,
+		Intermediaries: []types.Address{irene.Address()},
 		Outcome: td.Outcomes.Create(
 			*c.Address,
 			*retrievalProvider.Address,
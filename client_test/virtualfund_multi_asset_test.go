@@ -0,0 +1,144 @@
+package client_test
+
+import (
+	"errors"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// someToken is the address of an ERC20 token held alongside the chain's native token
+var someToken = types.Address{'t', 'o', 'k', 'e', 'n'}
+
+// twoAssetOutcome returns an outcome allocating the given amounts to a and b in both the native token and someToken.
+func twoAssetOutcome(a, b types.Address, aNative, bNative, aToken, bToken uint) outcome.Exit {
+	allocations := func(aAmount, bAmount uint) outcome.Allocations {
+		return outcome.Allocations{
+			{Destination: types.AddressToDestination(a), Amount: big.NewInt(int64(aAmount))},
+			{Destination: types.AddressToDestination(b), Amount: big.NewInt(int64(bAmount))},
+		}
+	}
+	return outcome.Exit{
+		{Asset: types.Address{}, Allocations: allocations(aNative, bNative)},
+		{Asset: someToken, Allocations: allocations(aToken, bToken)},
+	}
+}
+
+// directlyFundATwoAssetLedgerChannel funds a ledger channel between alpha and beta holding both the native token and someToken.
+func directlyFundATwoAssetLedgerChannel(t *testing.T, alpha client.Client, beta client.Client) {
+	request := directfund.ObjectiveRequest{
+		CounterParty:      *beta.Address,
+		Outcome:           twoAssetOutcome(*alpha.Address, *beta.Address, ledgerChannelDeposit, ledgerChannelDeposit, ledgerChannelDeposit, ledgerChannelDeposit),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             int64(rand.Int31()),
+	}
+	response := alpha.CreateDirectChannel(request)
+
+	waitTimeForCompletedObjectiveIds(t, &alpha, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &beta, defaultTimeout, response.Id)
+}
+
+// TestVirtualFundMultiAsset tests the scenario where Alice virtually funds a channel with Bob holding two assets, through ledger channels which each hold both assets,
+// and Bob then closes the channel.
+func TestVirtualFundMultiAsset(t *testing.T) {
+
+	logFile := "test_virtual_fund_multi_asset.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientAlice, storeAlice := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	clientIrene, _ := setupClient(irene.PrivateKey, chain, broker, logDestination, 0)
+	clientBob, storeBob := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+
+	directlyFundATwoAssetLedgerChannel(t, clientAlice, clientIrene)
+	directlyFundATwoAssetLedgerChannel(t, clientIrene, clientBob)
+
+	request := virtualfund.ObjectiveRequest{
+		CounterParty:      bob.Address(),
+		Intermediaries:    []types.Address{irene.Address()},
+		Outcome:           twoAssetOutcome(alice.Address(), bob.Address(), 3, 1, 7, 2),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             rand.Int63(),
+	}
+	response := clientAlice.CreateVirtualChannel(request)
+
+	waitTimeForCompletedObjectiveIds(t, &clientAlice, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientIrene, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientBob, defaultTimeout, response.Id)
+
+	// checkLedgerAmounts checks whether the ledger with the counterparty guarantees the virtual channel, and the amounts held by its leader and follower
+	checkLedgerAmounts := func(s store.Store, counterparty types.Address, guaranteed bool, expectedLeader, expectedFollower types.Funds) {
+		ledger, ok := s.GetConsensusChannel(counterparty)
+		if !ok {
+			t.Fatalf("could not find a ledger channel with %s", counterparty)
+		}
+		if ledger.IncludesTarget(response.ChannelId) != guaranteed {
+			t.Errorf("expected ledger %s to guarantee channel %s: %t", ledger.Id, response.ChannelId, guaranteed)
+		}
+		lo := ledger.ConsensusVars().Outcome
+		for asset, amount := range expectedLeader {
+			if got := lo.Leader().Amount(asset); got.Cmp(amount) != 0 {
+				t.Errorf("expected leader of %s to hold %s of asset %s, got %s", ledger.Id, amount, asset, got)
+			}
+		}
+		for asset, amount := range expectedFollower {
+			if got := lo.Follower().Amount(asset); got.Cmp(amount) != 0 {
+				t.Errorf("expected follower of %s to hold %s of asset %s, got %s", ledger.Id, amount, asset, got)
+			}
+		}
+	}
+
+	// Each ledger should guarantee the virtual channel in both assets, with the left participant funding the guarantee
+	deposit := func(less int64) *big.Int { return big.NewInt(ledgerChannelDeposit - less) }
+	checkLedgerAmounts(storeAlice, irene.Address(), true,
+		types.Funds{types.Address{}: deposit(3), someToken: deposit(7)},
+		types.Funds{types.Address{}: deposit(1), someToken: deposit(2)},
+	)
+	checkLedgerAmounts(storeBob, irene.Address(), true,
+		types.Funds{types.Address{}: deposit(3), someToken: deposit(7)},
+		types.Funds{types.Address{}: deposit(1), someToken: deposit(2)},
+	)
+
+	// Vouchers do not name an asset, so payments cannot be made on the channel
+	clientAlice.Pay(response.ChannelId, big.NewInt(1))
+	select {
+	case <-time.After(defaultTimeout):
+		t.Fatalf("expected an error to be reported within %s", defaultTimeout)
+	case err := <-clientAlice.Errors():
+		if !errors.Is(err, payments.ErrChannelNotRegistered) {
+			t.Errorf("expected %v, but got %v", payments.ErrChannelNotRegistered, err)
+		}
+	}
+
+	// Once Bob closes the channel, each ledger pays out the virtual channel's outcome in both assets
+	id := clientBob.CloseVirtualChannel(response.ChannelId)
+	waitTimeForCompletedObjectiveIds(t, &clientAlice, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientIrene, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientBob, defaultTimeout, id)
+
+	// Nothing was paid on the channel, so every participant in each ledger gets back what they put towards the guarantee
+	for _, s := range []store.Store{storeAlice, storeBob} {
+		checkLedgerAmounts(s, irene.Address(), false,
+			types.Funds{types.Address{}: deposit(0), someToken: deposit(0)},
+			types.Funds{types.Address{}: deposit(0), someToken: deposit(0)},
+		)
+	}
+}
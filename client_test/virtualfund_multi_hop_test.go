@@ -0,0 +1,109 @@
+package client_test
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestVirtualFundMultiHop tests the scenario where Alice creates, pays on and closes a virtual channel with Bob using both Irene and Ivan as intermediaries.
+func TestVirtualFundMultiHop(t *testing.T) {
+
+	logFile := "test_virtual_fund_multi_hop.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	clientAlice, storeAlice := setupClient(alice.PrivateKey, chain, broker, logDestination, 0)
+	clientIrene, storeIrene := setupClient(irene.PrivateKey, chain, broker, logDestination, 0)
+	clientIvan, storeIvan := setupClient(ivan.PrivateKey, chain, broker, logDestination, 0)
+	clientBob, storeBob := setupClient(bob.PrivateKey, chain, broker, logDestination, 0)
+
+	directlyFundALedgerChannel(t, clientAlice, clientIrene)
+	directlyFundALedgerChannel(t, clientIrene, clientIvan)
+	directlyFundALedgerChannel(t, clientIvan, clientBob)
+
+	request := virtualfund.ObjectiveRequest{
+		CounterParty:      bob.Address(),
+		Intermediaries:    []types.Address{irene.Address(), ivan.Address()},
+		Outcome:           td.Outcomes.Create(alice.Address(), bob.Address(), 1, 1),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             rand.Int63(),
+	}
+	response := clientAlice.CreateVirtualChannel(request)
+
+	clients := []*clientAndStore{
+		{&clientAlice, storeAlice},
+		{&clientIrene, storeIrene},
+		{&clientIvan, storeIvan},
+		{&clientBob, storeBob},
+	}
+	for _, c := range clients {
+		waitTimeForCompletedObjectiveIds(t, c.client, defaultTimeout, response.Id)
+	}
+
+	paidToBob := uint(1)
+	clientAlice.Pay(response.ChannelId, big.NewInt(int64(paidToBob)))
+	waitTimeForReceivedVouchers(t, &clientBob, defaultTimeout, response.ChannelId)
+
+	id := clientAlice.CloseVirtualChannel(response.ChannelId)
+	for _, c := range clients {
+		waitTimeForCompletedObjectiveIds(t, c.client, defaultTimeout, id)
+	}
+
+	// Every ledger along the path should have the guarantee removed and the payment passed on to the right
+	path := []types.Address{alice.Address(), irene.Address(), ivan.Address(), bob.Address()}
+	for i, c := range clients {
+		o, err := c.store.GetObjectiveById(id)
+		if err != nil {
+			t.Fatalf("Could not get objective: %v", err)
+		}
+		vdfo := o.(*virtualdefund.Objective)
+		if vdfo.GetStatus() != protocols.Completed {
+			t.Errorf("Expected objective %s to be completed", vdfo.Id())
+		}
+		if i > 0 {
+			checkLedgerOutcome(t, vdfo.VId(), vdfo.ToMyLeft.ConsensusVars().Outcome, path[i-1], path[i], paidToBob)
+		}
+		if i < len(path)-1 {
+			checkLedgerOutcome(t, vdfo.VId(), vdfo.ToMyRight.ConsensusVars().Outcome, path[i], path[i+1], paidToBob)
+		}
+	}
+}
+
+type clientAndStore struct {
+	client *client.Client
+	store  store.Store
+}
+
+// checkLedgerOutcome checks that the ledger outcome no longer guarantees the virtual channel, and that the amount paid to Bob has moved from the leader to the follower
+func checkLedgerOutcome(t *testing.T, vId types.Destination, outcome consensus_channel.LedgerOutcome, leader, follower types.Address, totalPaidToBob uint) {
+	if outcome.IncludesTarget(vId) {
+		t.Errorf("The outcome %+v should not contain a guarantee for the virtual channel %s", outcome, vId)
+	}
+	expectedLeaderBalance := consensus_channel.NewBalance(types.AddressToDestination(leader), types.Funds{types.Address{}: big.NewInt(int64(ledgerChannelDeposit - totalPaidToBob))})
+	if diff := cmp.Diff(expectedLeaderBalance, outcome.Leader()); diff != "" {
+		t.Errorf("Unexpected leader balance: %s", diff)
+	}
+
+	expectedFollowerBalance := consensus_channel.NewBalance(types.AddressToDestination(follower), types.Funds{types.Address{}: big.NewInt(int64(ledgerChannelDeposit + totalPaidToBob))})
+	if diff := cmp.Diff(expectedFollowerBalance, outcome.Follower()); diff != "" {
+		t.Errorf("Unexpected follower balance: %s", diff)
+	}
+}
@@ -31,8 +31,8 @@ func TestVirtualFundMultiParty(t *testing.T) {
 	directlyFundALedgerChannel(t, clientIrene, clientBob)
 	directlyFundALedgerChannel(t, clientIrene, clientBrian)
 	withBobRequest := virtualfund.ObjectiveRequest{
-		CounterParty: bob.Address(),
-		Intermediary: irene.Address(),
+		CounterParty:   bob.Address(),
+		Intermediaries: []types.Address{irene.Address()},
 		Outcome: td.Outcomes.Create(
 			alice.Address(),
 			bob.Address(),
@@ -45,8 +45,8 @@ func TestVirtualFundMultiParty(t *testing.T) {
 		Nonce:             rand.Int63(),
 	}
 	withBrianRequest := virtualfund.ObjectiveRequest{
-		CounterParty: brian.Address(),
-		Intermediary: irene.Address(),
+		CounterParty:   brian.Address(),
+		Intermediaries: []types.Address{irene.Address()},
 		Outcome: td.Outcomes.Create(
 			alice.Address(),
 			brian.Address(),
@@ -0,0 +1,98 @@
+package client_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice/tcp"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// setupClientWithTCP is a helper function that contructs a client which uses a TCPMessageService, and returns the new client and its message service.
+func setupClientWithTCP(pk []byte, chain *chainservice.MockChain, peers map[types.Address]string, logDestination io.Writer) (client.Client, *tcp.TCPMessageService) {
+	myAddress := crypto.GetAddressFromSecretKeyBytes(pk)
+	messageservice := tcp.NewTCPMessageService(myAddress, peers[myAddress], peers)
+	storeA := store.NewMemStore(pk)
+	return mustNewClient(messageservice, chain, storeA, logDestination, &engine.PermissivePolicy{}), messageservice
+}
+
+// setupClientWithSecureTCP is a helper function that contructs a client which uses a ReliableMessageService wrapping a secure TCPMessageService,
+// and returns the new client and both message services.
+func setupClientWithSecureTCP(pk []byte, chain *chainservice.MockChain, peers map[types.Address]string, logDestination io.Writer) (client.Client, *messageservice.ReliableMessageService, *tcp.TCPMessageService) {
+	myAddress := crypto.GetAddressFromSecretKeyBytes(pk)
+	tcpService := tcp.NewSecureTCPMessageService(peers[myAddress], peers, pk)
+	reliableService := messageservice.NewReliableMessageService(pk, tcpService)
+	storeA := store.NewMemStore(pk)
+	return mustNewClient(reliableService, chain, storeA, logDestination, &engine.PermissivePolicy{}), reliableService, tcpService
+}
+
+func TestVirtualFundWithTCPMessageService(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_virtual_fund_with_tcp.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+
+	peers := map[types.Address]string{
+		alice.Address(): "localhost:3205",
+		bob.Address():   "localhost:3206",
+		irene.Address(): "localhost:3207",
+	}
+
+	clientA, msgA := setupClientWithTCP(alice.PrivateKey, chain, peers, logDestination)
+	clientB, msgB := setupClientWithTCP(bob.PrivateKey, chain, peers, logDestination)
+	clientI, msgI := setupClientWithTCP(irene.PrivateKey, chain, peers, logDestination)
+	defer msgA.Close()
+	defer msgB.Close()
+	defer msgI.Close()
+
+	directlyFundALedgerChannel(t, clientA, clientI)
+	directlyFundALedgerChannel(t, clientI, clientB)
+
+	ids := createVirtualChannels(clientA, bob.Address(), irene.Address(), 5)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, ids...)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, ids...)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, ids...)
+}
+
+func TestVirtualFundWithReliableSecureTCPMessageService(t *testing.T) {
+
+	// Setup logging
+	logFile := "test_virtual_fund_with_secure_tcp.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+
+	peers := map[types.Address]string{
+		alice.Address(): "localhost:3208",
+		bob.Address():   "localhost:3209",
+		irene.Address(): "localhost:3210",
+	}
+
+	clientA, msgA, tcpA := setupClientWithSecureTCP(alice.PrivateKey, chain, peers, logDestination)
+	clientB, msgB, tcpB := setupClientWithSecureTCP(bob.PrivateKey, chain, peers, logDestination)
+	clientI, msgI, tcpI := setupClientWithSecureTCP(irene.PrivateKey, chain, peers, logDestination)
+	defer tcpA.Close()
+	defer tcpB.Close()
+	defer tcpI.Close()
+	defer msgA.Close()
+	defer msgB.Close()
+	defer msgI.Close()
+
+	directlyFundALedgerChannel(t, clientA, clientI)
+	directlyFundALedgerChannel(t, clientI, clientB)
+
+	ids := createVirtualChannels(clientA, bob.Address(), irene.Address(), 5)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, ids...)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, ids...)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, ids...)
+}
@@ -24,7 +24,7 @@ func openVirtualChannels(t *testing.T, clientA client.Client, clientB client.Cli
 		outcome := td.Outcomes.Create(alice.Address(), bob.Address(), 1, 1)
 		request := virtualfund.ObjectiveRequest{
 			CounterParty:      bob.Address(),
-			Intermediary:      irene.Address(),
+			Intermediaries:    []types.Address{irene.Address()},
 			Outcome:           outcome,
 			AppDefinition:     types.Address{},
 			AppData:           types.Bytes{},
@@ -53,7 +53,7 @@ func createVirtualChannels(client client.Client, counterParty types.Address, int
 		request := virtualfund.ObjectiveRequest{
 
 			CounterParty:      counterParty,
-			Intermediary:      intermediary,
+			Intermediaries:    []types.Address{intermediary},
 			Outcome:           outcome,
 			AppDefinition:     types.Address{},
 			AppData:           types.Bytes{},
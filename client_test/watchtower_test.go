@@ -0,0 +1,323 @@
+package client_test
+
+import (
+	"io"
+	"math/big"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/client"
+	"github.com/statechannels/go-nitro/client/engine"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/client/engine/store"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/statechannels/go-nitro/watchtower"
+)
+
+// challengePolicy approves every objective, and always responds to stale challenges in the same way.
+type challengePolicy struct {
+	response engine.ChallengeResponse
+}
+
+func (p challengePolicy) ShouldApprove(o protocols.Objective) bool {
+	return true
+}
+
+func (p challengePolicy) RespondToChallenge(challenge chainservice.ChallengeRegisteredEvent, latestSupported state.SignedState) engine.ChallengeResponse {
+	return p.response
+}
+
+// advanceLedgerChannel opens and closes a virtual channel between Alice and Bob, which advances the ledger channel between Alice and Irene.
+// Irene's client ignores challenges, while Alice's client responds to them as directed by aliceResponse.
+//
+// It returns Alice's client, the ledger channel, a stale state Irene may challenge the ledger channel with, and the turn number of the latest supported state.
+func advanceLedgerChannel(t *testing.T, chainA, chainB, chainI chainservice.ChainService, broker messageservice.Broker, logDestination io.Writer, aliceResponse engine.ChallengeResponse) (client.Client, types.Destination, state.SignedState, uint64) {
+	storeA := store.NewMemStore(alice.PrivateKey)
+	clientA, err := client.New(messageservice.NewTestMessageService(alice.Address(), broker, 0), chainA, storeA, logDestination, challengePolicy{aliceResponse}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientB, _ := setupClient(bob.PrivateKey, chainB, broker, logDestination, 0)
+	storeI := store.NewMemStore(irene.PrivateKey)
+	clientI, err := client.New(messageservice.NewTestMessageService(irene.Address(), broker, 0), chainI, storeI, logDestination, challengePolicy{engine.Ignore}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Challenges on these ledger channels take a minute to finalize
+	ledgerId := directlyFundALedgerChannelWithChallengeDuration(t, clientA, clientI, 60)
+	directlyFundALedgerChannelWithChallengeDuration(t, clientI, clientB, 60)
+
+	ledger, err := storeI.GetConsensusChannelById(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := ledger.SupportedSignedState()
+
+	request := virtualfund.ObjectiveRequest{
+		CounterParty:      bob.Address(),
+		Intermediaries:    []types.Address{irene.Address()},
+		Outcome:           td.Outcomes.Create(alice.Address(), bob.Address(), 1, 1),
+		AppDefinition:     types.Address{},
+		AppData:           types.Bytes{},
+		ChallengeDuration: big.NewInt(0),
+		Nonce:             rand.Int63(),
+	}
+	response := clientA.CreateVirtualChannel(request)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, response.Id)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, response.Id)
+
+	id := clientA.CloseVirtualChannel(response.ChannelId)
+	waitTimeForCompletedObjectiveIds(t, &clientA, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientB, defaultTimeout, id)
+	waitTimeForCompletedObjectiveIds(t, &clientI, defaultTimeout, id)
+
+	ledger, err = storeA.GetConsensusChannelById(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latestTurnNum := ledger.SupportedSignedState().State().TurnNum
+	if latestTurnNum <= stale.State().TurnNum {
+		t.Fatalf("expected the ledger channel to have advanced beyond turn %d", stale.State().TurnNum)
+	}
+
+	return clientA, ledgerId, stale, latestTurnNum
+}
+
+// challengeWithStaleState has Irene challenge the ledger channel with the stale state, and returns the chain events observed by a third party from then on.
+func challengeWithStaleState(t *testing.T, chainI chainservice.ChainService, ledgerId types.Destination, stale state.SignedState) <-chan chainservice.Event {
+	events := chainI.SubscribeToEvents(brian.Address())
+	chainI.Monitor(brian.Address(), ledgerId)
+
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(stale.State(), irene.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = chainI.SendTransaction(protocols.NewChallengeTransaction(ledgerId, stale, challengerSig))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return events
+}
+
+// waitForChainEvent waits for an event satisfying the supplied predicate, and fails the test if none arrives within the timeout.
+func waitForChainEvent(t *testing.T, events <-chan chainservice.Event, timeout time.Duration, predicate func(chainservice.Event) bool) {
+	deadline := time.After(timeout)
+	for {
+		select {
+		case event := <-events:
+			if predicate(event) {
+				return
+			}
+		case <-deadline:
+			t.Fatalf("expected chain event was not observed within %v", timeout)
+		}
+	}
+}
+
+func runCheckpointTest(t *testing.T, chainA, chainB, chainI chainservice.ChainService, logDestination io.Writer) {
+	_, ledgerId, stale, latestTurnNum := advanceLedgerChannel(t, chainA, chainB, chainI, messageservice.NewBroker(), logDestination, engine.Checkpoint)
+	events := challengeWithStaleState(t, chainI, ledgerId, stale)
+
+	// Alice clears the challenge with her latest supported state
+	waitForChainEvent(t, events, defaultTimeout, func(event chainservice.Event) bool {
+		cleared, ok := event.(chainservice.ChallengeClearedEvent)
+		return ok && cleared.ChannelID() == ledgerId && cleared.NewTurnNumRecord == latestTurnNum
+	})
+}
+
+// runStandaloneWatchtowerTest has Alice hand her ledger channel to a watchtower, and then ignore challenges as though she were offline.
+func runStandaloneWatchtowerTest(t *testing.T, chainA, chainB, chainI, chainW chainservice.ChainService, logDestination io.Writer) {
+	broker := messageservice.NewBroker()
+	towerStore := watchtower.NewMemStore()
+	tower := watchtower.New(ivan.Address(), messageservice.NewTestMessageService(ivan.Address(), broker, 0), chainW, towerStore, logDestination)
+
+	clientA, ledgerId, stale, latestTurnNum := advanceLedgerChannel(t, chainA, chainB, chainI, broker, logDestination, engine.Ignore)
+	clientA.GuardChannel(ledgerId, ivan.Address())
+
+	// Alice goes offline once the watchtower holds her latest state
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		if ss, ok := towerStore.GetState(ledgerId); ok && ss.State().TurnNum == latestTurnNum {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("watchtower did not receive the latest state of channel %s", ledgerId)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	events := challengeWithStaleState(t, chainI, ledgerId, stale)
+
+	// The watchtower clears the challenge with Alice's latest supported state
+	waitForChainEvent(t, events, defaultTimeout, func(event chainservice.Event) bool {
+		cleared, ok := event.(chainservice.ChallengeClearedEvent)
+		return ok && cleared.ChannelID() == ledgerId && cleared.NewTurnNumRecord == latestTurnNum
+	})
+	select {
+	case err := <-tower.Errors():
+		t.Fatalf("unexpected watchtower error: %v", err)
+	default:
+	}
+}
+
+// runRestartedWatchtowerTest has Alice hand her ledger channel to a watchtower which goes down before the channel is challenged.
+// The watchtower clears the challenge once it is started again.
+func runRestartedWatchtowerTest(t *testing.T, chainA, chainB, chainI, chainW chainservice.ChainService, logDestination io.Writer) {
+	broker := messageservice.NewBroker()
+	towerMessages := messageservice.NewTestMessageService(ivan.Address(), broker, 0)
+	towerStore := watchtower.NewMemStore()
+
+	clientA, ledgerId, stale, latestTurnNum := advanceLedgerChannel(t, chainA, chainB, chainI, broker, logDestination, engine.Ignore)
+	clientA.GuardChannel(ledgerId, ivan.Address())
+
+	// The watchtower stores Alice's latest state before it goes down
+	deadline := time.After(defaultTimeout)
+	for {
+		if ss, ok := towerStore.GetState(ledgerId); ok && ss.State().TurnNum == latestTurnNum {
+			break
+		}
+		select {
+		case message := <-towerMessages.Out():
+			for _, ss := range message.WatchedStates() {
+				towerStore.SetState(ss)
+			}
+		case <-deadline:
+			t.Fatalf("watchtower did not receive the latest state of channel %s", ledgerId)
+		}
+	}
+
+	events := challengeWithStaleState(t, chainI, ledgerId, stale)
+	waitForChainEvent(t, events, defaultTimeout, func(event chainservice.Event) bool {
+		registered, ok := event.(chainservice.ChallengeRegisteredEvent)
+		return ok && registered.ChannelID() == ledgerId
+	})
+
+	tower := watchtower.New(ivan.Address(), towerMessages, chainW, towerStore, logDestination)
+
+	// The restarted watchtower clears the challenge with Alice's latest supported state
+	waitForChainEvent(t, events, defaultTimeout, func(event chainservice.Event) bool {
+		cleared, ok := event.(chainservice.ChallengeClearedEvent)
+		return ok && cleared.ChannelID() == ledgerId && cleared.NewTurnNumRecord == latestTurnNum
+	})
+	select {
+	case err := <-tower.Errors():
+		t.Fatalf("unexpected watchtower error: %v", err)
+	default:
+	}
+}
+
+func runCounterChallengeTest(t *testing.T, chainA, chainB, chainI chainservice.ChainService, logDestination io.Writer) {
+	_, ledgerId, stale, latestTurnNum := advanceLedgerChannel(t, chainA, chainB, chainI, messageservice.NewBroker(), logDestination, engine.CounterChallenge)
+	events := challengeWithStaleState(t, chainI, ledgerId, stale)
+
+	// Alice replaces the challenge with one registered with her latest supported state
+	waitForChainEvent(t, events, defaultTimeout, func(event chainservice.Event) bool {
+		registered, ok := event.(chainservice.ChallengeRegisteredEvent)
+		return ok && registered.ChannelID() == ledgerId && registered.TurnNumRecord == latestTurnNum
+	})
+}
+
+// TestWatchtowerCheckpointMockChain checks that Alice's client clears a challenge registered with a stale state.
+func TestWatchtowerCheckpointMockChain(t *testing.T) {
+	logFile := "test_watchtower.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	runCheckpointTest(t, chain, chain, chain, logDestination)
+}
+
+// TestWatchtowerCounterChallengeMockChain checks that Alice's client counters a challenge registered with a stale state, when its policy says so.
+func TestWatchtowerCounterChallengeMockChain(t *testing.T) {
+	logFile := "test_watchtower.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	runCounterChallengeTest(t, chain, chain, chain, logDestination)
+}
+
+// TestStandaloneWatchtowerMockChain checks that a watchtower clears a stale challenge against a channel handed to it by an offline client.
+func TestStandaloneWatchtowerMockChain(t *testing.T) {
+	logFile := "test_watchtower.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	runStandaloneWatchtowerTest(t, chain, chain, chain, chain, logDestination)
+}
+
+// TestRestartedWatchtowerMockChain checks that a watchtower clears a stale challenge registered while it was down.
+func TestRestartedWatchtowerMockChain(t *testing.T) {
+	logFile := "test_watchtower.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	chain := chainservice.NewMockChain()
+	runRestartedWatchtowerTest(t, chain, chain, chain, chain, logDestination)
+}
+
+// TestStandaloneWatchtower checks that a watchtower clears a stale challenge against a channel handed to it by an offline client, on the simulated backend.
+func TestStandaloneWatchtower(t *testing.T) {
+	logFile := "test_watchtower.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	chainA := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	chainB := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	chainI := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[2])
+	chainW := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[3])
+	runStandaloneWatchtowerTest(t, chainA, chainB, chainI, chainW, logDestination)
+}
+
+// TestWatchtowerCheckpoint checks that Alice's client clears a challenge registered with a stale state on the simulated backend.
+func TestWatchtowerCheckpoint(t *testing.T) {
+	logFile := "test_watchtower.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	chainA := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	chainB := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	chainI := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[2])
+	runCheckpointTest(t, chainA, chainB, chainI, logDestination)
+}
+
+// TestWatchtowerCounterChallenge checks that Alice's client counters a challenge registered with a stale state on the simulated backend.
+func TestWatchtowerCounterChallenge(t *testing.T) {
+	logFile := "test_watchtower.log"
+	truncateLog(logFile)
+	logDestination := newLogWriter(logFile)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	chainA := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	chainB := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	chainI := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[2])
+	runCounterChallengeTest(t, chainA, chainB, chainI, logDestination)
+}
@@ -48,3 +48,11 @@ var Irene Actor = Actor{
 	1,
 	"irene",
 }
+
+// Ivan has the address 0x222b63060e5D308526B90fcde0537364aaAF298E
+// Ivan is the second intermediary in a two hop virtual channel (Alice, Irene, Ivan, Bob)
+var Ivan Actor = Actor{
+	common.Hex2Bytes(`9db8e2ca7e22fe4b9050586c8f7870fb120360fb851d9a14036cdee6e270cb63`),
+	2,
+	"ivan",
+}
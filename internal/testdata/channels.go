@@ -25,7 +25,7 @@ var Channels channelCollection = channelCollection{
 func mockConsensusChannel(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool) {
 	ts := testState.Clone()
 	ts.TurnNum = 0
-	testObj, err := directfund.ConstructFromState(true, ts, ts.Participants[0])
+	testObj, err := directfund.ConstructFromState(true, ts, ts.Participants[0], noHoldings)
 
 	if err != nil {
 		return &consensus_channel.ConsensusChannel{}, false
@@ -49,6 +49,11 @@ func mockConsensusChannel(counterparty types.Address) (ledger *consensus_channel
 	return cc, true
 }
 
+// noHoldings reports that nothing is held on chain for any channel
+func noHoldings(channelId types.Destination, assets []common.Address) (types.Funds, uint64, error) {
+	return types.Funds{}, 0, nil
+}
+
 // LedgerNetwork is a collection of in-memory consensus_channel ledgers
 // which expose both the leader and follower perspective on the ledger
 type LedgerNetwork struct {
@@ -119,9 +124,9 @@ func createTestLedger(leader, follower testactors.Actor) TestLedger {
 	fp.Participants[1] = follower.Address()
 
 	outcome := consensus_channel.NewLedgerOutcome(
-		types.Address{}, // the zero asset
-		consensus_channel.NewBalance(leader.Destination(), big.NewInt(100)),
-		consensus_channel.NewBalance(follower.Destination(), big.NewInt(200)),
+		[]types.Address{{}}, // the zero asset
+		consensus_channel.NewBalance(leader.Destination(), types.Funds{types.Address{}: big.NewInt(100)}),
+		consensus_channel.NewBalance(follower.Destination(), types.Funds{types.Address{}: big.NewInt(200)}),
 		[]consensus_channel.Guarantee{},
 	)
 
@@ -6,6 +6,7 @@ import (
 	"github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
 )
 
 // objectiveCollection namespaces literal objectives, precomputed objectives, and
@@ -44,7 +45,7 @@ var Objectives objectiveCollection = objectiveCollection{
 func genericDFO() directfund.Objective {
 	ts := testState.Clone()
 	ts.TurnNum = 0
-	testObj, err := directfund.ConstructFromState(false, ts, ts.Participants[0])
+	testObj, err := directfund.ConstructFromState(false, ts, ts.Participants[0], noHoldings)
 	if err != nil {
 		panic(fmt.Errorf("error constructing genericDFO: %w", err))
 	}
@@ -58,13 +59,14 @@ func genericVFO() virtualfund.Objective {
 	ts.Participants[2] = testactors.Bob.Address()
 
 	request := virtualfund.ObjectiveRequest{
-		ts.Participants[1],
+		[]types.Address{ts.Participants[1]},
 		ts.Participants[2],
 		ts.AppDefinition,
 		ts.AppData,
 		ts.ChallengeDuration,
 		ts.Outcome,
 		ts.ChannelNonce.Int64(),
+		ts.ChainId,
 	}
 
 	ledgerPath := createLedgerPath([]testactors.Actor{
@@ -0,0 +1,5 @@
+// Package payments contains the types and functions used to make and receive payments on a virtual channel.
+//
+// A payment is made by sending the payee a Voucher: a signature by the payer over the virtual channel id and the
+// total (cumulative) amount paid so far. The payee only ever needs to keep the largest voucher it has received.
+package payments // import "github.com/statechannels/go-nitro/payments"
@@ -0,0 +1,84 @@
+package payments
+
+import (
+	"fmt"
+	"math/big"
+
+	ethAbi "github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/abi"
+	"github.com/statechannels/go-nitro/channel/state"
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Voucher is a payer's signed promise to pay the payee Amount (in total) from the virtual channel ChannelId.
+type Voucher struct {
+	ChannelId types.Destination
+	Amount    *big.Int
+	Signature state.Signature
+}
+
+// encode abi encodes the channel id and amount of the voucher.
+func (v Voucher) encode() (types.Bytes, error) {
+	return ethAbi.Arguments{
+		{Type: abi.Destination}, // channel id
+		{Type: abi.Uint256},     // amount
+	}.Pack(v.ChannelId, v.amount())
+}
+
+// Hash returns the keccak256 hash of the voucher's channel id and amount.
+func (v Voucher) Hash() (types.Bytes32, error) {
+	encoded, err := v.encode()
+	if err != nil {
+		return types.Bytes32{}, fmt.Errorf("failed to encode voucher: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// Sign generates an ECDSA signature on the voucher using the supplied private key, and stores it on the voucher.
+func (v *Voucher) Sign(secretKey []byte) error {
+	hash, err := v.Hash()
+	if err != nil {
+		return err
+	}
+	sig, err := nc.SignEthereumMessage(hash.Bytes(), secretKey)
+	if err != nil {
+		return err
+	}
+	v.Signature = sig
+	return nil
+}
+
+// RecoverSigner computes the Ethereum address which generated the voucher's signature.
+func (v Voucher) RecoverSigner() (types.Address, error) {
+	hash, err := v.Hash()
+	if err != nil {
+		return types.Address{}, err
+	}
+	return nc.RecoverEthereumMessageSigner(hash[:], v.Signature)
+}
+
+// Equal returns true if the receiver and other are for the same channel and amount, and carry the same signature.
+func (v Voucher) Equal(other Voucher) bool {
+	return v.ChannelId == other.ChannelId &&
+		v.amount().Cmp(other.amount()) == 0 &&
+		v.Signature.Equal(other.Signature)
+}
+
+// Clone returns a deep copy of the receiver.
+func (v Voucher) Clone() Voucher {
+	return Voucher{
+		ChannelId: v.ChannelId,
+		Amount:    new(big.Int).Set(v.amount()),
+		Signature: state.CloneSignature(v.Signature),
+	}
+}
+
+// amount returns the amount of the voucher, treating a nil amount as zero.
+func (v Voucher) amount() *big.Int {
+	if v.Amount == nil {
+		return big.NewInt(0)
+	}
+	return v.Amount
+}
@@ -0,0 +1,164 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+var (
+	ErrChannelNotRegistered = errors.New("payments: channel not registered")
+	ErrInsufficientFunds    = errors.New("payments: insufficient funds")
+	ErrInvalidVoucher       = errors.New("payments: invalid voucher")
+)
+
+// VoucherInfo records what is known about payments on a virtual channel: who pays whom, how much the payer
+// started with, and the largest voucher made (by the payer) or received (by the payee).
+type VoucherInfo struct {
+	ChannelPayer    types.Address
+	ChannelPayee    types.Address
+	StartingBalance *big.Int
+	LargestVoucher  Voucher
+}
+
+// Paid returns the total amount paid so far.
+func (vi VoucherInfo) Paid() *big.Int {
+	return new(big.Int).Set(vi.LargestVoucher.amount())
+}
+
+// Remaining returns the amount the payer can still pay.
+func (vi VoucherInfo) Remaining() *big.Int {
+	return new(big.Int).Sub(vi.StartingBalance, vi.LargestVoucher.amount())
+}
+
+// VoucherStore is responsible for persisting VoucherInfo, keyed by channel id.
+type VoucherStore interface {
+	GetVoucherInfo(channelId types.Destination) (v *VoucherInfo, ok bool)
+	SetVoucherInfo(channelId types.Destination, v VoucherInfo) error
+	RemoveVoucherInfo(channelId types.Destination) error
+}
+
+// VoucherManager makes payments on channels where the calling client is the payer, and validates and tracks payments
+// on channels where the calling client is the payee.
+type VoucherManager struct {
+	me    types.Address
+	store VoucherStore
+}
+
+// NewVoucherManager returns a VoucherManager for the client with the supplied address, persisting to the supplied store.
+func NewVoucherManager(me types.Address, store VoucherStore) *VoucherManager {
+	return &VoucherManager{me: me, store: store}
+}
+
+// Register starts tracking payments on the channel with the supplied id.
+func (vm *VoucherManager) Register(channelId types.Destination, payer types.Address, payee types.Address, startingBalance *big.Int) error {
+	if _, ok := vm.store.GetVoucherInfo(channelId); ok {
+		return fmt.Errorf("channel %s already registered", channelId)
+	}
+
+	info := VoucherInfo{
+		ChannelPayer:    payer,
+		ChannelPayee:    payee,
+		StartingBalance: new(big.Int).Set(startingBalance),
+		LargestVoucher:  Voucher{ChannelId: channelId, Amount: big.NewInt(0)},
+	}
+	return vm.store.SetVoucherInfo(channelId, info)
+}
+
+// Remove stops tracking payments on the channel with the supplied id.
+func (vm *VoucherManager) Remove(channelId types.Destination) error {
+	return vm.store.RemoveVoucherInfo(channelId)
+}
+
+// ChannelRegistered returns true if payments on the channel with the supplied id are being tracked.
+func (vm *VoucherManager) ChannelRegistered(channelId types.Destination) bool {
+	_, ok := vm.store.GetVoucherInfo(channelId)
+	return ok
+}
+
+// Pay returns a voucher, signed with the supplied secret key, which pays a further amount to the payee of the channel.
+// The voucher is for the total amount paid so far, so that the payee only needs to keep the largest voucher.
+func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, secretKey []byte) (Voucher, error) {
+	info, ok := vm.store.GetVoucherInfo(channelId)
+	if !ok {
+		return Voucher{}, fmt.Errorf("%w: %s", ErrChannelNotRegistered, channelId)
+	}
+	if info.ChannelPayer != vm.me {
+		return Voucher{}, fmt.Errorf("cannot pay on channel %s: payer is %s", channelId, info.ChannelPayer)
+	}
+	if amount == nil || amount.Sign() <= 0 {
+		return Voucher{}, fmt.Errorf("cannot pay a non-positive amount %s", amount)
+	}
+	if amount.Cmp(info.Remaining()) > 0 {
+		return Voucher{}, fmt.Errorf("%w: cannot pay %s on channel %s, only %s remains", ErrInsufficientFunds, amount, channelId, info.Remaining())
+	}
+
+	voucher := Voucher{ChannelId: channelId, Amount: new(big.Int).Add(info.Paid(), amount)}
+	if err := voucher.Sign(secretKey); err != nil {
+		return Voucher{}, fmt.Errorf("could not sign voucher: %w", err)
+	}
+
+	info.LargestVoucher = voucher
+	if err := vm.store.SetVoucherInfo(channelId, *info); err != nil {
+		return Voucher{}, err
+	}
+	return voucher, nil
+}
+
+// Receive validates the supplied voucher and records it if it is the largest voucher received on its channel.
+// It returns the total amount received on the channel.
+func (vm *VoucherManager) Receive(voucher Voucher) (*big.Int, error) {
+	info, ok := vm.store.GetVoucherInfo(voucher.ChannelId)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotRegistered, voucher.ChannelId)
+	}
+	if info.ChannelPayee != vm.me {
+		return nil, fmt.Errorf("%w: received a voucher on channel %s, but payee is %s", ErrInvalidVoucher, voucher.ChannelId, info.ChannelPayee)
+	}
+	if voucher.Amount == nil || voucher.Amount.Sign() < 0 {
+		return nil, fmt.Errorf("%w: voucher amount %v is missing or negative", ErrInvalidVoucher, voucher.Amount)
+	}
+
+	signer, err := voucher.RecoverSigner()
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not recover signer: %v", ErrInvalidVoucher, err)
+	}
+	if signer != info.ChannelPayer {
+		return nil, fmt.Errorf("%w: voucher signed by %s, expected payer %s", ErrInvalidVoucher, signer, info.ChannelPayer)
+	}
+	if voucher.amount().Cmp(info.StartingBalance) > 0 {
+		return nil, fmt.Errorf("%w: voucher amount %s exceeds the payer's starting balance %s", ErrInvalidVoucher, voucher.amount(), info.StartingBalance)
+	}
+
+	// Vouchers are cumulative, so a smaller voucher (e.g. one delivered out of order) carries no new information
+	if voucher.amount().Cmp(info.Paid()) <= 0 {
+		return info.Paid(), nil
+	}
+
+	info.LargestVoucher = voucher.Clone()
+	if err := vm.store.SetVoucherInfo(voucher.ChannelId, *info); err != nil {
+		return nil, err
+	}
+	return info.Paid(), nil
+}
+
+// Paid returns the total amount paid on the channel with the supplied id: the amount the calling client has paid if it is the payer,
+// or the largest voucher the calling client has received if it is the payee.
+func (vm *VoucherManager) Paid(channelId types.Destination) (*big.Int, error) {
+	info, ok := vm.store.GetVoucherInfo(channelId)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotRegistered, channelId)
+	}
+	return info.Paid(), nil
+}
+
+// Payee returns the payee of the channel with the supplied id.
+func (vm *VoucherManager) Payee(channelId types.Destination) (types.Address, error) {
+	info, ok := vm.store.GetVoucherInfo(channelId)
+	if !ok {
+		return types.Address{}, fmt.Errorf("%w: %s", ErrChannelNotRegistered, channelId)
+	}
+	return info.ChannelPayee, nil
+}
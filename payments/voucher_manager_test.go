@@ -0,0 +1,126 @@
+package payments_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/client/engine/store"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestVoucherSignature(t *testing.T) {
+	v := payments.Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(5)}
+	if err := v.Sign(ta.Alice.PrivateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := v.RecoverSigner()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != ta.Alice.Address() {
+		t.Errorf("expected voucher to be signed by %s, but recovered %s", ta.Alice.Address(), signer)
+	}
+
+	tampered := v.Clone()
+	tampered.Amount = big.NewInt(6)
+	signer, err = tampered.RecoverSigner()
+	if err == nil && signer == ta.Alice.Address() {
+		t.Errorf("expected a tampered voucher not to recover to the original signer")
+	}
+}
+
+func TestVoucherManager(t *testing.T) {
+	channelId := types.Destination{1}
+	startingBalance := big.NewInt(10)
+
+	payer := payments.NewVoucherManager(ta.Alice.Address(), store.NewMemStore(ta.Alice.PrivateKey))
+	payee := payments.NewVoucherManager(ta.Bob.Address(), store.NewMemStore(ta.Bob.PrivateKey))
+
+	if _, err := payer.Pay(channelId, big.NewInt(1), ta.Alice.PrivateKey); !errors.Is(err, payments.ErrChannelNotRegistered) {
+		t.Fatalf("expected %v paying on an unregistered channel, got %v", payments.ErrChannelNotRegistered, err)
+	}
+
+	for _, vm := range []*payments.VoucherManager{payer, payee} {
+		if err := vm.Register(channelId, ta.Alice.Address(), ta.Bob.Address(), startingBalance); err != nil {
+			t.Fatal(err)
+		}
+		if err := vm.Register(channelId, ta.Alice.Address(), ta.Bob.Address(), startingBalance); err == nil {
+			t.Fatalf("expected an error registering a channel twice")
+		}
+	}
+
+	// Vouchers are cumulative
+	first, err := payer.Pay(channelId, big.NewInt(3), ta.Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := payer.Pay(channelId, big.NewInt(4), ta.Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Amount.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("expected second voucher to be for 7, got %s", second.Amount)
+	}
+
+	if _, err := payer.Pay(channelId, big.NewInt(4), ta.Alice.PrivateKey); !errors.Is(err, payments.ErrInsufficientFunds) {
+		t.Errorf("expected %v overpaying, got %v", payments.ErrInsufficientFunds, err)
+	}
+	if _, err := payee.Pay(channelId, big.NewInt(1), ta.Bob.PrivateKey); err == nil {
+		t.Errorf("expected an error when the payee tries to pay")
+	}
+
+	// Vouchers may arrive out of order: only the largest counts
+	total, err := payee.Receive(second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("expected total received to be 7, got %s", total)
+	}
+	total, err = payee.Receive(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("expected total received to remain 7, got %s", total)
+	}
+
+	paid, err := payee.Paid(channelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if paid.Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("expected payee to have been paid 7, got %s", paid)
+	}
+
+	// Vouchers must be signed by the payer, and be within the payer's starting balance
+	forged := payments.Voucher{ChannelId: channelId, Amount: big.NewInt(8)}
+	_ = forged.Sign(ta.Irene.PrivateKey)
+	if _, err := payee.Receive(forged); !errors.Is(err, payments.ErrInvalidVoucher) {
+		t.Errorf("expected %v receiving a voucher signed by a third party, got %v", payments.ErrInvalidVoucher, err)
+	}
+	tooLarge := payments.Voucher{ChannelId: channelId, Amount: big.NewInt(11)}
+	_ = tooLarge.Sign(ta.Alice.PrivateKey)
+	if _, err := payee.Receive(tooLarge); !errors.Is(err, payments.ErrInvalidVoucher) {
+		t.Errorf("expected %v receiving a voucher exceeding the starting balance, got %v", payments.ErrInvalidVoucher, err)
+	}
+
+	// Vouchers with a missing or negative amount are rejected before their signature is checked
+	for _, amount := range []*big.Int{nil, big.NewInt(-1)} {
+		malformed := payments.Voucher{ChannelId: channelId, Amount: amount, Signature: second.Signature}
+		if _, err := payee.Receive(malformed); !errors.Is(err, payments.ErrInvalidVoucher) {
+			t.Errorf("expected %v receiving a voucher for amount %v, got %v", payments.ErrInvalidVoucher, amount, err)
+		}
+	}
+
+	if err := payee.Remove(channelId); err != nil {
+		t.Fatal(err)
+	}
+	if payee.ChannelRegistered(channelId) {
+		t.Errorf("expected channel %s to no longer be registered", channelId)
+	}
+}
@@ -0,0 +1,260 @@
+// Package challenge implements an on-chain protocol to recover the funds in a directly-funded channel when a counterparty stops responding.
+package challenge // import "github.com/statechannels/go-nitro/protocols/challenge"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForChallenge    protocols.WaitingFor = "WaitingForChallenge"
+	WaitingForFinalization protocols.WaitingFor = "WaitingForFinalization"
+	WaitingForWithdraw     protocols.WaitingFor = "WaitingForWithdraw"
+	WaitingForNothing      protocols.WaitingFor = "WaitingForNothing" // Finished
+)
+
+const ObjectivePrefix = "Challenge-"
+
+var (
+	ErrNoSupportedState = errors.New("cannot challenge a channel without a supported state")
+	ErrNotEmpty         = errors.New("ledger channel has running guarantees")
+)
+
+// Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data
+type Objective struct {
+	Status protocols.ObjectiveStatus
+	C      *channel.Channel
+
+	challengeSubmitted bool   // whether a challenge transaction has been submitted (or a challenge registered by somebody else)
+	challengedTurnNum  uint64 // the turn number of the state registered on chain
+	finalizesAt        uint64 // the chain time at which the registered challenge finalizes, or 0 if no challenge is registered
+	finalized          bool   // whether the registered challenge has finalized
+	transferSubmitted  bool   // whether a transfer transaction has been submitted
+}
+
+// GetChannelByIdFunction specifies a function that can be used to retrieve channels from a store.
+type GetChannelByIdFunction func(id types.Destination) (channel *channel.Channel, ok bool)
+
+// GetConsensusChannel describes functions which return a ConsensusChannel ledger channel for a channel id.
+type GetConsensusChannel func(channelId types.Destination) (ledger *consensus_channel.ConsensusChannel, err error)
+
+// NewObjective initiates an Objective to challenge the supplied channel.
+//
+// The channel is either a ledger channel, or a channel which is already being defunded (for example by a directdefund.Objective which has stalled).
+func NewObjective(
+	request ObjectiveRequest,
+	preApprove bool,
+	getChannel GetChannelByIdFunction,
+	getConsensusChannel GetConsensusChannel,
+) (Objective, error) {
+	var c *channel.Channel
+	if cc, err := getConsensusChannel(request.ChannelId); err == nil {
+		if len(cc.FundingTargets()) != 0 {
+			return Objective{}, ErrNotEmpty
+		}
+		c, err = directdefund.CreateChannelFromConsensusChannel(*cc)
+		if err != nil {
+			return Objective{}, fmt.Errorf("could not create Channel from ConsensusChannel; %w", err)
+		}
+	} else if existing, ok := getChannel(request.ChannelId); ok {
+		c = existing.Clone()
+	} else {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", request.ChannelId, err)
+	}
+
+	if _, err := c.LatestSupportedSignedState(); err != nil {
+		return Objective{}, ErrNoSupportedState
+	}
+
+	init := Objective{C: c}
+	if preApprove {
+		init.Status = protocols.Approved
+	} else {
+		init.Status = protocols.Unapproved
+	}
+	return init, nil
+}
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + o.C.Id.String())
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Approved
+	return &updated
+}
+
+func (o *Objective) Reject() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	return &updated
+}
+
+func (o *Objective) Fail() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Failed
+	return &updated
+}
+
+// OwnsChannel returns the channel that the objective is challenging.
+func (o Objective) OwnsChannel() types.Destination {
+	return o.C.Id
+}
+
+// GetStatus returns the status of the objective.
+func (o Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{o.C}
+}
+
+// Update receives an ObjectiveEvent and returns an updated objective.
+//
+// A challenge is driven entirely by chain events, so only signed states for the channel are accepted (they may be used in a later challenge).
+func (o *Objective) Update(event protocols.ObjectiveEvent) (protocols.Objective, error) {
+	if o.Id() != event.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(event.ObjectiveId), string(o.Id()))
+	}
+	updated := o.clone()
+	if len(event.SignedState.Signatures()) != 0 {
+		updated.C.AddSignedState(event.SignedState)
+	}
+	return &updated, nil
+}
+
+// UpdateWithChainEvent updates the objective with observed on-chain data.
+func (o *Objective) UpdateWithChainEvent(event chainservice.Event) (protocols.Objective, error) {
+	updated := o.clone()
+	switch e := event.(type) {
+	case chainservice.ChallengeRegisteredEvent:
+		if latest, err := updated.C.LatestSupportedState(); err == nil && latest.TurnNum > e.TurnNumRecord {
+			// A counterparty registered a stale state: challenge again with the latest supported state
+			updated.challengeSubmitted = false
+			updated.finalizesAt = 0
+			break
+		}
+		// The challenge may have been registered by a counterparty, in which case we wait for it to finalize rather than submitting our own
+		updated.challengeSubmitted = true
+		updated.challengedTurnNum = e.TurnNumRecord
+		updated.finalizesAt = e.FinalizesAt
+	case chainservice.ChallengeClearedEvent:
+		latest, err := updated.C.LatestSupportedState()
+		if err != nil || latest.TurnNum < e.NewTurnNumRecord {
+			return &updated, fmt.Errorf("challenge was cleared with turn number %d, which is newer than any supported state", e.NewTurnNumRecord)
+		}
+		// The channel was checkpointed: submit a fresh challenge
+		updated.challengeSubmitted = false
+		updated.finalizesAt = 0
+	case chainservice.ChallengeFinalizedEvent:
+		if e.FinalizesAt == updated.finalizesAt {
+			updated.finalized = true
+		}
+	case chainservice.DepositedEvent:
+		updated.C.OnChainFunding[e.Asset] = e.NowHeld
+	case chainservice.AllocationUpdatedEvent:
+		updated.C.OnChainFunding[e.AssetAddress] = e.AssetAmount
+	case chainservice.ConcludedEvent, chainservice.EventRevertedEvent:
+		break
+	default:
+		return &updated, fmt.Errorf("objective %+v cannot handle event %+v", updated, event)
+	}
+	return &updated, nil
+}
+
+// HandlesChallenges returns true, since the objective challenges again when a challenge is registered with a stale state.
+func (o *Objective) HandlesChallenges() bool {
+	return true
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	// Register a challenge with the latest supported state
+	if !updated.challengeSubmitted {
+		candidate, err := updated.C.LatestSupportedSignedState()
+		if err != nil {
+			return &updated, sideEffects, WaitingForChallenge, ErrNoSupportedState
+		}
+		challengerSig, err := NitroAdjudicator.SignChallengeMessage(candidate.State(), *secretKey)
+		if err != nil {
+			return &updated, sideEffects, WaitingForChallenge, fmt.Errorf("could not sign challenge: %w", err)
+		}
+		challenge := protocols.NewChallengeTransaction(updated.C.Id, candidate, challengerSig)
+		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, challenge)
+		updated.challengeSubmitted = true
+	}
+
+	if updated.finalizesAt == 0 {
+		return &updated, sideEffects, WaitingForChallenge, nil
+	}
+
+	if !updated.finalized {
+		return &updated, sideEffects, WaitingForFinalization, nil
+	}
+
+	// Withdrawal of funds
+	if updated.C.OnChainFunding.IsNonZero() {
+		if !updated.transferSubmitted {
+			challenged, ok := updated.C.SignedStateForTurnNum[updated.challengedTurnNum]
+			if !ok {
+				return &updated, sideEffects, WaitingForWithdraw, fmt.Errorf("no state with the challenged turn number %d", updated.challengedTurnNum)
+			}
+			transferAll := protocols.NewTransferAllTransaction(updated.C.Id, challenged.State())
+			sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, transferAll)
+			updated.transferSubmitted = true
+		}
+		return &updated, sideEffects, WaitingForWithdraw, nil
+	}
+
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// IsChallengeObjective inspects a objective id and returns true if the objective id is for a challenge objective.
+func IsChallengeObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := Objective{}
+	clone.Status = o.Status
+	clone.C = o.C.Clone()
+	clone.challengeSubmitted = o.challengeSubmitted
+	clone.challengedTurnNum = o.challengedTurnNum
+	clone.finalizesAt = o.finalizesAt
+	clone.finalized = o.finalized
+	clone.transferSubmitted = o.transferSubmitted
+
+	return clone
+}
+
+// ObjectiveRequest represents a request to create a new challenge objective.
+type ObjectiveRequest struct {
+	ChannelId types.Destination
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address) protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + r.ChannelId.String())
+}
@@ -0,0 +1,196 @@
+package challenge
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/client/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice = testactors.Alice
+
+// newTestObjective returns a challenge Objective constructed with a MockConsensusChannel.
+func newTestObjective() (Objective, error) {
+	cc, _ := testdata.Channels.MockConsensusChannel(alice.Address())
+
+	getChannel := func(id types.Destination) (*channel.Channel, bool) {
+		return nil, false
+	}
+	getConsensusChannel := func(id types.Destination) (*consensus_channel.ConsensusChannel, error) {
+		return cc, nil
+	}
+	return NewObjective(ObjectiveRequest{ChannelId: cc.Id}, true, getChannel, getConsensusChannel)
+}
+
+func TestNew(t *testing.T) {
+	if _, err := newTestObjective(); err != nil {
+		t.Error(err)
+	}
+
+	getChannel := func(id types.Destination) (*channel.Channel, bool) {
+		return nil, false
+	}
+	getConsensusChannel := func(id types.Destination) (*consensus_channel.ConsensusChannel, error) {
+		return nil, errors.New("no such channel")
+	}
+	if _, err := NewObjective(ObjectiveRequest{}, true, getChannel, getConsensusChannel); err == nil {
+		t.Error("expected an error challenging an unknown channel")
+	}
+}
+
+func TestCrank(t *testing.T) {
+	o, err := newTestObjective()
+	testhelpers.Ok(t, err)
+
+	// The first crank. Alice is expected to challenge with the latest supported state
+	updated, se, wf, err := o.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	if wf != WaitingForChallenge {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForChallenge, wf)
+	}
+
+	candidate, err := o.C.LatestSupportedSignedState()
+	testhelpers.Ok(t, err)
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(candidate.State(), alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	expectedSE := protocols.SideEffects{TransactionsToSubmit: []protocols.ChainTransaction{protocols.NewChallengeTransaction(o.C.Id, candidate, challengerSig)}}
+	if diff := cmp.Diff(expectedSE, se, cmp.AllowUnexported(expectedSE, state.SignedState{}, protocols.ChainTransactionBase{})); diff != "" {
+		t.Fatalf("Side effects mismatch (-want +got):\n%s", diff)
+	}
+
+	// Cranking again does not submit a second challenge
+	_, se, _, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	if len(se.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected no transactions, got %+v", se.TransactionsToSubmit)
+	}
+
+	// The challenge is registered, and Alice waits for it to finalize
+	updated, err = updated.(*Objective).UpdateWithChainEvent(chainservice.ChallengeRegisteredEvent{TurnNumRecord: candidate.State().TurnNum, FinalizesAt: 100})
+	testhelpers.Ok(t, err)
+	updated, _, wf, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	if wf != WaitingForFinalization {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForFinalization, wf)
+	}
+
+	// Finalization of an earlier challenge is ignored
+	updated, err = updated.(*Objective).UpdateWithChainEvent(chainservice.ChallengeFinalizedEvent{FinalizesAt: 99})
+	testhelpers.Ok(t, err)
+	updated, _, wf, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	if wf != WaitingForFinalization {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForFinalization, wf)
+	}
+
+	// Once the challenge finalizes, Alice transfers the channel's assets
+	updated, err = updated.(*Objective).UpdateWithChainEvent(chainservice.ChallengeFinalizedEvent{FinalizesAt: 100})
+	testhelpers.Ok(t, err)
+	updated, se, wf, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	if wf != WaitingForWithdraw {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForWithdraw, wf)
+	}
+	expectedSE = protocols.SideEffects{TransactionsToSubmit: []protocols.ChainTransaction{protocols.NewTransferAllTransaction(o.C.Id, candidate.State())}}
+	if diff := cmp.Diff(expectedSE, se, cmp.AllowUnexported(expectedSE, protocols.ChainTransactionBase{})); diff != "" {
+		t.Fatalf("Side effects mismatch (-want +got):\n%s", diff)
+	}
+
+	// The objective completes once the channel holds no assets
+	updated, err = updated.(*Objective).UpdateWithChainEvent(chainservice.AllocationUpdatedEvent{AssetAddress: common.Address{}, AssetAmount: common.Big0})
+	testhelpers.Ok(t, err)
+	updated, _, wf, err = updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	if wf != WaitingForNothing {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForNothing, wf)
+	}
+	if updated.GetStatus() != protocols.Completed {
+		t.Fatalf("expected objective to be completed, got status %v", updated.GetStatus())
+	}
+}
+
+func TestChallengeCleared(t *testing.T) {
+	o, err := newTestObjective()
+	testhelpers.Ok(t, err)
+	candidate, err := o.C.LatestSupportedState()
+	testhelpers.Ok(t, err)
+
+	updated, _, _, err := o.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	updated, err = updated.(*Objective).UpdateWithChainEvent(chainservice.ChallengeRegisteredEvent{TurnNumRecord: candidate.TurnNum, FinalizesAt: 100})
+	testhelpers.Ok(t, err)
+
+	// A checkpoint with a state Alice does not hold cannot be countered
+	if _, err := updated.(*Objective).UpdateWithChainEvent(chainservice.ChallengeClearedEvent{NewTurnNumRecord: candidate.TurnNum + 1}); err == nil {
+		t.Fatal("expected an error when the challenge is cleared with an unknown state")
+	}
+
+	// Otherwise Alice challenges again
+	updated, err = updated.(*Objective).UpdateWithChainEvent(chainservice.ChallengeClearedEvent{NewTurnNumRecord: candidate.TurnNum})
+	testhelpers.Ok(t, err)
+	_, se, wf, err := updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	if wf != WaitingForChallenge {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForChallenge, wf)
+	}
+	if len(se.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected a fresh challenge transaction, got %+v", se.TransactionsToSubmit)
+	}
+}
+
+func TestStaleChallenge(t *testing.T) {
+	o, err := newTestObjective()
+	testhelpers.Ok(t, err)
+	candidate, err := o.C.LatestSupportedState()
+	testhelpers.Ok(t, err)
+
+	updated, _, _, err := o.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+
+	// A counterparty registers a challenge with an older state, so Alice challenges again
+	updated, err = updated.(*Objective).UpdateWithChainEvent(chainservice.ChallengeRegisteredEvent{TurnNumRecord: candidate.TurnNum - 1, FinalizesAt: 100})
+	testhelpers.Ok(t, err)
+	_, se, wf, err := updated.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+	if wf != WaitingForChallenge {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForChallenge, wf)
+	}
+	if len(se.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected a fresh challenge transaction, got %+v", se.TransactionsToSubmit)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	o, err := newTestObjective()
+	testhelpers.Ok(t, err)
+	o.challengeSubmitted = true
+	o.challengedTurnNum = 1
+	o.finalizesAt = 100
+
+	encoded, err := json.Marshal(o)
+	testhelpers.Ok(t, err)
+
+	got := Objective{}
+	if err := got.UnmarshalJSON(encoded); err != nil {
+		t.Fatalf("error unmarshaling test challenge objective: %s", err.Error())
+	}
+
+	if got.C.Id != o.C.Id {
+		t.Fatalf("expected channel Id %s but got %s", o.C.Id, got.C.Id)
+	}
+	if got.Status != o.Status || got.challengeSubmitted != o.challengeSubmitted || got.challengedTurnNum != o.challengedTurnNum || got.finalizesAt != o.finalizesAt {
+		t.Fatalf("expected %+v but got %+v", o, got)
+	}
+}
@@ -0,0 +1,69 @@
+package challenge
+
+import (
+	"encoding/json"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// jsonObjective replaces the challenge.Objective's channel pointer with
+// the channel's ID, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status             protocols.ObjectiveStatus
+	C                  types.Destination
+	ChallengeSubmitted bool
+	ChallengedTurnNum  uint64
+	FinalizesAt        uint64
+	Finalized          bool
+	TransferSubmitted  bool
+}
+
+// MarshalJSON returns a JSON representation of the challenge Objective
+//
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+//       (other than Id) from the field C is discarded
+func (o Objective) MarshalJSON() ([]byte, error) {
+	jsonCO := jsonObjective{
+		o.Status,
+		o.C.Id,
+		o.challengeSubmitted,
+		o.challengedTurnNum,
+		o.finalizesAt,
+		o.finalized,
+		o.transferSubmitted,
+	}
+
+	return json.Marshal(jsonCO)
+}
+
+// UnmarshalJSON populates the calling challenge Objective with the
+// json-encoded data
+//
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+//       (other than Id) from the field C is discarded
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonCO jsonObjective
+	err := json.Unmarshal(data, &jsonCO)
+
+	if err != nil {
+		return err
+	}
+
+	o.C = &channel.Channel{}
+
+	o.Status = jsonCO.Status
+	o.C.Id = jsonCO.C
+	o.challengeSubmitted = jsonCO.ChallengeSubmitted
+	o.challengedTurnNum = jsonCO.ChallengedTurnNum
+	o.finalizesAt = jsonCO.FinalizesAt
+	o.finalized = jsonCO.Finalized
+	o.transferSubmitted = jsonCO.TransferSubmitted
+
+	return nil
+}
@@ -163,6 +163,12 @@ func (o *Objective) Reject() protocols.Objective {
 	return &updated
 }
 
+func (o *Objective) Fail() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Failed
+	return &updated
+}
+
 // OwnsChannel returns the channel that the objective is funding.
 func (ddo Objective) OwnsChannel() types.Destination {
 	return ddo.C.Id
@@ -204,7 +210,7 @@ func (o *Objective) Update(event protocols.ObjectiveEvent) (protocols.Objective,
 
 // UpdateWithChainEvent updates the objective with observed on-chain data.
 //
-// Only Allocation Updated events are currently handled.
+// Allocation Updated events are handled. Challenge events are ignored, since the engine responds to challenges itself.
 func (o *Objective) UpdateWithChainEvent(event chainservice.Event) (protocols.Objective, error) {
 	updated := o.clone()
 	switch e := event.(type) {
@@ -213,6 +219,8 @@ func (o *Objective) UpdateWithChainEvent(event chainservice.Event) (protocols.Ob
 		updated.C.OnChainFunding[e.AssetAddress] = e.AssetAmount
 	case chainservice.ConcludedEvent:
 		break
+	case chainservice.ChallengeRegisteredEvent, chainservice.ChallengeClearedEvent, chainservice.ChallengeFinalizedEvent, chainservice.EventRevertedEvent:
+		break
 	default:
 		return &updated, fmt.Errorf("objective %+v cannot handle event %+v", updated, event)
 	}
@@ -274,6 +282,20 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	return &updated, sideEffects, WaitingForNothing, nil
 }
 
+// Resume returns side effects which resend every final state in the channel that has been signed by the calling client.
+// Earlier states (such as the consensus state the channel was created from) are not resent, since the counterparty's objective rejects them.
+func (o *Objective) Resume() protocols.SideEffects {
+	sideEffects := protocols.SideEffects{}
+	for _, ss := range o.C.SignedStatesSignedByMe() {
+		if !ss.State().IsFinal {
+			continue
+		}
+		messages := protocols.CreateSignedStateMessages(o.Id(), ss, o.C.MyIndex)
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+	return sideEffects
+}
+
 // IsDirectDefundObjective inspects a objective id and returns true if the objective id is for a direct defund objective.
 func IsDirectDefundObjective(id protocols.ObjectiveId) bool {
 	return strings.HasPrefix(string(id), ObjectivePrefix)
@@ -328,3 +328,29 @@ func TestApproveReject(t *testing.T) {
 		t.Errorf("Expected rejceted status, got %v", approved.GetStatus())
 	}
 }
+
+func TestResume(t *testing.T) {
+	o, err := newTestObjective()
+	testhelpers.Ok(t, err)
+
+	// The consensus state the channel was created from is not final, so it is not resent
+	if se := o.Resume(); len(se.MessagesToSend) != 0 {
+		t.Fatalf("expected no messages to be resent, got %d", len(se.MessagesToSend))
+	}
+
+	updated, _, _, err := o.Crank(&alice.PrivateKey)
+	testhelpers.Ok(t, err)
+
+	finalState, err := o.C.LatestSupportedState()
+	testhelpers.Ok(t, err)
+	finalState.TurnNum = 2
+	finalState.IsFinal = true
+	finalStateSignedByAlice, _ := signedTestState(finalState, []bool{true, false})
+
+	expectedSE := protocols.SideEffects{
+		MessagesToSend: protocols.CreateSignedStateMessages(o.Id(), finalStateSignedByAlice, 0),
+	}
+	if diff := compareSideEffect(expectedSE, updated.(*Objective).Resume()); diff != "" {
+		t.Errorf("Side effects mismatch (-want +got):\n%s", diff)
+	}
+}
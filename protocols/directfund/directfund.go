@@ -7,6 +7,7 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
@@ -49,12 +50,16 @@ type GetChannelsByParticipantFunction func(participant types.Address) []*channel
 // the calling client and the given counterparty, if such a channel exists.
 type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool)
 
+// GetHoldingsFunction describes functions which return the amount of each supplied asset currently held on chain for a channel,
+// along with the number of the block the holdings were read at.
+type GetHoldingsFunction func(channelId types.Destination, assets []common.Address) (holdings types.Funds, blockNum uint64, err error)
+
 // NewObjective creates a new direct funding objective from a given request.
-func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Address, getChannels GetChannelsByParticipantFunction, getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction) (Objective, error) {
+func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Address, getChannels GetChannelsByParticipantFunction, getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction, getHoldings GetHoldingsFunction) (Objective, error) {
 
 	objective, err := ConstructFromState(preApprove,
 		state.State{
-			ChainId:           big.NewInt(1337), // TODO https://github.com/statechannels/go-nitro/issues/601
+			ChainId:           request.ChainId,
 			Participants:      []types.Address{myAddress, request.CounterParty},
 			ChannelNonce:      big.NewInt(request.Nonce),
 			AppDefinition:     request.AppDefinition,
@@ -65,6 +70,7 @@ func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Add
 			IsFinal:           false,
 		},
 		myAddress,
+		getHoldings,
 	)
 	if err != nil {
 		return Objective{}, fmt.Errorf("could not create new objective: %w", err)
@@ -93,11 +99,13 @@ func channelsExistWithCounterparty(counterparty types.Address, getChannels GetCh
 }
 
 // ConstructFromState initiates a Objective with data calculated from
-// the supplied initialState and client address
+// the supplied initialState and client address. The channel's funding is initialised
+// from its current on chain holdings, so that deposits made before the objective was constructed are not missed.
 func ConstructFromState(
 	preApprove bool,
 	initialState state.State,
 	myAddress types.Address,
+	getHoldings GetHoldingsFunction,
 ) (Objective, error) {
 	var err error
 
@@ -144,6 +152,19 @@ func ConstructFromState(
 		types.AddressToDestination(myAddress),
 	)
 
+	assets := []common.Address{}
+	for asset := range init.C.OnChainFunding {
+		assets = append(assets, asset)
+	}
+	holdings, blockNum, err := getHoldings(init.C.Id, assets)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not read on chain holdings for channel %s: %w", init.C.Id, err)
+	}
+	for asset, amount := range holdings {
+		init.C.OnChainFunding[asset] = amount
+	}
+	init.latestBlockNumber = blockNum
+
 	init.fullyFundedThreshold = initialState.Outcome.TotalAllocated()
 	init.myDepositSafetyThreshold = initialState.Outcome.DepositSafetyThreshold(
 		types.AddressToDestination(myAddress),
@@ -181,12 +202,8 @@ func (dfo *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChan
 	}
 	signatures := [2]state.Signature{leaderSig, followerSig}
 
-	if len(signedPostFund.State().Outcome) != 1 {
-		return nil, fmt.Errorf("a consensus channel only supports a single asset")
-	}
-	assetExit := signedPostFund.State().Outcome[0]
 	turnNum := signedPostFund.State().TurnNum
-	outcome, err := consensus_channel.FromExit(assetExit)
+	outcome, err := consensus_channel.FromExit(signedPostFund.State().Outcome)
 
 	if err != nil {
 		return nil, fmt.Errorf("could not create ledger outcome from channel exit: %w", err)
@@ -231,6 +248,12 @@ func (o *Objective) Reject() protocols.Objective {
 	return &updated
 }
 
+func (o *Objective) Fail() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Failed
+	return &updated
+}
+
 // Update receives an ObjectiveEvent, applies all applicable event data to the DirectFundingObjectiveState,
 // and returns the updated state
 func (o *Objective) Update(event protocols.ObjectiveEvent) (protocols.Objective, error) {
@@ -246,23 +269,42 @@ func (o *Objective) Update(event protocols.ObjectiveEvent) (protocols.Objective,
 
 // UpdateWithChainEvent updates the objective with observed on-chain data.
 //
-// Only Channel Deposit events are currently handled.
+// Channel Deposit events are handled. Challenge events are ignored, since the engine responds to challenges itself.
 func (o *Objective) UpdateWithChainEvent(event chainservice.Event) (protocols.Objective, error) {
 	updated := o.clone()
 
-	de, ok := event.(chainservice.DepositedEvent)
-	if !ok {
+	switch de := event.(type) {
+	case chainservice.DepositedEvent:
+		if de.BlockNum >= updated.latestBlockNumber { // a single block may contain deposits of several assets
+			updated.C.OnChainFunding[de.Asset] = de.NowHeld
+			updated.latestBlockNumber = de.BlockNum
+		}
+	case chainservice.EventRevertedEvent:
+		if reverted, ok := de.Reverted.(chainservice.DepositedEvent); ok {
+			updated.revertDeposit(reverted)
+		}
+	case chainservice.ChallengeRegisteredEvent, chainservice.ChallengeClearedEvent, chainservice.ChallengeFinalizedEvent:
+		break
+	default:
 		return &updated, fmt.Errorf("objective %+v cannot handle event %+v", updated, event)
 	}
-	if de.BlockNum > updated.latestBlockNumber {
-		updated.C.OnChainFunding[de.Asset] = de.NowHeld
-		updated.latestBlockNumber = de.BlockNum
-	}
 
 	return &updated, nil
 
 }
 
+// revertDeposit rolls back the on chain funding to what was held before a deposit which a reorg has removed from the chain.
+// A reorg reverts every deposit after the fork, possibly in any order, so the funding is only ever lowered.
+func (o *Objective) revertDeposit(de chainservice.DepositedEvent) {
+	heldBefore := new(big.Int).Sub(de.NowHeld, de.AmountDeposited)
+	if held, ok := o.C.OnChainFunding[de.Asset]; ok && held.Cmp(heldBefore) > 0 {
+		o.C.OnChainFunding[de.Asset] = heldBefore
+	}
+	if de.BlockNum <= o.latestBlockNumber {
+		o.latestBlockNumber = de.BlockNum - 1
+	}
+}
+
 // Crank inspects the extended state and declares a list of Effects to be executed
 // It's like a state machine transition function where the finite / enumerable state is returned (computed from the extended state)
 // rather than being independent of the extended state; and where there is only one type of event ("the crank") with no data on it at all
@@ -299,7 +341,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	if !fundingComplete && safeToDeposit && amountToDeposit.IsNonZero() && !updated.transactionSubmitted {
-		deposit := protocols.NewDepositTransaction(updated.C.Id, amountToDeposit)
+		deposit := protocols.NewDepositTransactionWithExpectedHeld(updated.C.Id, amountToDeposit, updated.C.OnChainFunding.Clone())
 		updated.transactionSubmitted = true
 		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, deposit)
 	}
@@ -329,6 +371,16 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	return &updated, sideEffects, WaitingForNothing, nil
 }
 
+// Resume returns side effects which resend every state in the channel that has been signed by the calling client.
+func (o *Objective) Resume() protocols.SideEffects {
+	sideEffects := protocols.SideEffects{}
+	for _, ss := range o.C.SignedStatesSignedByMe() {
+		messages := protocols.CreateSignedStateMessages(o.Id(), ss, o.C.MyIndex)
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+	return sideEffects
+}
+
 func (o *Objective) Related() []protocols.Storable {
 	return []protocols.Storable{o.C}
 }
@@ -407,6 +459,7 @@ func IsDirectFundObjective(id protocols.ObjectiveId) bool {
 }
 
 // ObjectiveRequest represents a request to create a new direct funding objective.
+// ChainId is the id of the chain the channel is to be funded on.
 type ObjectiveRequest struct {
 	CounterParty      types.Address
 	AppDefinition     types.Address
@@ -414,17 +467,24 @@ type ObjectiveRequest struct {
 	ChallengeDuration *types.Uint256
 	Outcome           outcome.Exit
 	Nonce             int64
+	ChainId           *big.Int
 }
 
 // Id returns the objective id for the request.
 func (r ObjectiveRequest) Id(myAddress types.Address) protocols.ObjectiveId {
-	fixedPart := state.FixedPart{ChainId: big.NewInt(9001), // TODO add this field to the request and pull it from there. https://github.com/statechannels/go-nitro/issues/601
+	return protocols.ObjectiveId(ObjectivePrefix + r.channelId(myAddress).String())
+}
+
+// channelId returns the id of the channel requested by the client with the supplied address.
+func (r ObjectiveRequest) channelId(myAddress types.Address) types.Destination {
+	fixedPart := state.FixedPart{
+		ChainId:           r.ChainId,
 		Participants:      []types.Address{myAddress, r.CounterParty},
 		ChannelNonce:      big.NewInt(r.Nonce),
-		ChallengeDuration: r.ChallengeDuration}
-
-	channelId := fixedPart.ChannelId()
-	return protocols.ObjectiveId(ObjectivePrefix + channelId.String())
+		ChallengeDuration: r.ChallengeDuration,
+		AppDefinition:     r.AppDefinition,
+	}
+	return fixedPart.ChannelId()
 }
 
 // ObjectiveResponse is the type returned across the API in response to the ObjectiveRequest.
@@ -435,19 +495,9 @@ type ObjectiveResponse struct {
 
 // Response computes and returns the appropriate response from the request.
 func (r ObjectiveRequest) Response(myAddress types.Address) ObjectiveResponse {
-	fixedPart := state.FixedPart{
-		ChainId:           big.NewInt(1337), // TODO add this field to the request and pull it from there. https://github.com/statechannels/go-nitro/issues/601
-		Participants:      []types.Address{myAddress, r.CounterParty},
-		ChannelNonce:      big.NewInt(r.Nonce),
-		ChallengeDuration: r.ChallengeDuration,
-		AppDefinition:     r.AppDefinition,
-	}
-
-	channelId := fixedPart.ChannelId()
-
 	return ObjectiveResponse{
-		Id:        protocols.ObjectiveId(ObjectivePrefix + channelId.String()),
-		ChannelId: channelId,
+		Id:        r.Id(myAddress),
+		ChannelId: r.channelId(myAddress),
 	}
 }
 
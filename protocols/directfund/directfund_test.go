@@ -46,6 +46,11 @@ var testState = state.State{
 	IsFinal: false,
 }
 
+// getNoHoldings reports that nothing is held on chain for any channel
+func getNoHoldings(channelId types.Destination, assets []common.Address) (types.Funds, uint64, error) {
+	return types.Funds{}, 0, nil
+}
+
 // TestNew tests the constructor using a TestState fixture
 func TestNew(t *testing.T) {
 
@@ -63,25 +68,32 @@ func TestNew(t *testing.T) {
 		ChallengeDuration: testState.ChallengeDuration,
 		Outcome:           testState.Outcome,
 		Nonce:             testState.ChannelNonce.Int64(),
+		ChainId:           testState.ChainId,
 	}
 	// Assert that valid constructor args do not result in error
-	if _, err := NewObjective(request, false, testState.Participants[0], getByParticipant, getByConsensus); err != nil {
+	o, err := NewObjective(request, false, testState.Participants[0], getByParticipant, getByConsensus, getNoHoldings)
+	if err != nil {
 		t.Error(err)
 	}
+	// Assert that the request's id and response agree with the objective
+	response := request.Response(testState.Participants[0])
+	if request.Id(testState.Participants[0]) != o.Id() || response.Id != o.Id() || response.ChannelId != o.C.Id {
+		t.Errorf("expected the request's id and response to match objective %s, got %s and %+v", o.Id(), request.Id(testState.Participants[0]), response)
+	}
 
 	getByParticipantHasChannel := func(id types.Address) []*channel.Channel {
 		c, _ := channel.New(testState, 0)
 		return []*channel.Channel{c}
 	}
 
-	if _, err := NewObjective(request, false, testState.Participants[0], getByParticipantHasChannel, getByConsensus); err == nil {
+	if _, err := NewObjective(request, false, testState.Participants[0], getByParticipantHasChannel, getByConsensus, getNoHoldings); err == nil {
 		t.Errorf("Expected an error when constructing with an objective when an existing channel exists")
 	}
 
 	getByConsensusHasChannel := func(id types.Address) (*consensus_channel.ConsensusChannel, bool) {
 		return nil, true
 	}
-	if _, err := NewObjective(request, false, testState.Participants[0], getByParticipant, getByConsensusHasChannel); err == nil {
+	if _, err := NewObjective(request, false, testState.Participants[0], getByParticipant, getByConsensusHasChannel, getNoHoldings); err == nil {
 		t.Errorf("Expected an error when constructing with an objective when an existing channel consensus channel exists")
 	}
 
@@ -89,7 +101,7 @@ func TestNew(t *testing.T) {
 
 func TestConstructFromState(t *testing.T) {
 	// Assert that valid constructor args do not result in error
-	if _, err := ConstructFromState(false, testState, testState.Participants[0]); err != nil {
+	if _, err := ConstructFromState(false, testState, testState.Participants[0], getNoHoldings); err != nil {
 		t.Error(err)
 	}
 
@@ -97,18 +109,42 @@ func TestConstructFromState(t *testing.T) {
 	finalState := testState.Clone()
 	finalState.IsFinal = true
 
-	if _, err := ConstructFromState(false, finalState, testState.Participants[0]); err == nil {
+	if _, err := ConstructFromState(false, finalState, testState.Participants[0], getNoHoldings); err == nil {
 		t.Error("expected an error when constructing with an initial state marked final, but got nil")
 	}
 
 	nonParticipant := common.HexToAddress("0x5b53f71453aeCb03D837bfe170570d40aE736CB4")
-	if _, err := ConstructFromState(false, testState, nonParticipant); err == nil {
+	if _, err := ConstructFromState(false, testState, nonParticipant, getNoHoldings); err == nil {
 		t.Error("expected an error when constructing with a participant not in the channel, but got nil")
 	}
+
+	// Assert that existing deposits are recorded, and that older deposit events are then ignored
+	asset := common.Address{}
+	getHoldings := func(channelId types.Destination, assets []common.Address) (types.Funds, uint64, error) {
+		if len(assets) != 1 || assets[0] != asset {
+			t.Fatalf("expected holdings to be requested for asset %s, got %v", asset, assets)
+		}
+		return types.Funds{asset: big.NewInt(5)}, 10, nil
+	}
+	o, err := ConstructFromState(false, testState, testState.Participants[1], getHoldings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.C.OnChainFunding[asset].Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("expected on chain funding of 5, got %v", o.C.OnChainFunding[asset])
+	}
+	stale := chainservice.DepositedEvent{CommonEvent: chainservice.NewCommonEvent(o.C.Id, 9), Asset: asset, AmountDeposited: big.NewInt(2), NowHeld: big.NewInt(2)}
+	updated, err := o.UpdateWithChainEvent(stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.(*Objective).C.OnChainFunding[asset].Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("expected a deposit event from before the holdings were read to be ignored")
+	}
 }
 func TestUpdate(t *testing.T) {
 	// Construct various variables for use in TestUpdate
-	var s, _ = ConstructFromState(false, testState, testState.Participants[0])
+	var s, _ = ConstructFromState(false, testState, testState.Participants[0], getNoHoldings)
 
 	var stateToSign state.State = s.C.PreFundState()
 	var correctSignatureByParticipant, _ = stateToSign.Sign(alice.PrivateKey)
@@ -180,6 +216,47 @@ func TestUpdate(t *testing.T) {
 
 }
 
+func TestRevertDeposit(t *testing.T) {
+	var s, _ = ConstructFromState(false, testState, testState.Participants[0], getNoHoldings)
+	asset := common.Address{}
+	first := chainservice.DepositedEvent{Asset: asset, AmountDeposited: big.NewInt(2), NowHeld: big.NewInt(2), CommonEvent: chainservice.CommonEvent{BlockNum: 100}}
+	second := chainservice.DepositedEvent{Asset: asset, AmountDeposited: big.NewInt(1), NowHeld: big.NewInt(3), CommonEvent: chainservice.CommonEvent{BlockNum: 101}}
+
+	updated := &s
+	for _, de := range []chainservice.DepositedEvent{first, second} {
+		updatedObjective, err := updated.UpdateWithChainEvent(de)
+		testhelpers.Ok(t, err)
+		updated = updatedObjective.(*Objective)
+	}
+
+	// A reorg removes the second deposit: the funding is rolled back to what was held after the first
+	updatedObjective, err := updated.UpdateWithChainEvent(chainservice.EventRevertedEvent{Reverted: second})
+	testhelpers.Ok(t, err)
+	updated = updatedObjective.(*Objective)
+	if want := (types.Funds{asset: big.NewInt(2)}); !updated.C.OnChainFunding.Equal(want) {
+		t.Fatalf("expected funding %v, got %v", want, updated.C.OnChainFunding)
+	}
+
+	// A deposit included in the new chain at the same height is not treated as stale
+	replacement := chainservice.DepositedEvent{Asset: asset, AmountDeposited: big.NewInt(2), NowHeld: big.NewInt(4), CommonEvent: chainservice.CommonEvent{BlockNum: 101}}
+	updatedObjective, err = updated.UpdateWithChainEvent(replacement)
+	testhelpers.Ok(t, err)
+	updated = updatedObjective.(*Objective)
+	if want := (types.Funds{asset: big.NewInt(4)}); !updated.C.OnChainFunding.Equal(want) {
+		t.Fatalf("expected funding %v, got %v", want, updated.C.OnChainFunding)
+	}
+
+	// Reverting several deposits in any order leaves the funding held before the earliest of them
+	for _, de := range []chainservice.DepositedEvent{first, replacement} {
+		updatedObjective, err = updated.UpdateWithChainEvent(chainservice.EventRevertedEvent{Reverted: de})
+		testhelpers.Ok(t, err)
+		updated = updatedObjective.(*Objective)
+	}
+	if want := (types.Funds{asset: big.NewInt(0)}); !updated.C.OnChainFunding.Equal(want) {
+		t.Fatalf("expected funding %v, got %v", want, updated.C.OnChainFunding)
+	}
+}
+
 func compareSideEffect(a, b protocols.SideEffects) string {
 	return cmp.Diff(a, b, cmp.AllowUnexported(a, state.SignedState{}, consensus_channel.Add{}, consensus_channel.Guarantee{}, consensus_channel.Remove{}, protocols.Message{}))
 }
@@ -187,7 +264,7 @@ func compareSideEffect(a, b protocols.SideEffects) string {
 func TestCrank(t *testing.T) {
 
 	// BEGIN test data preparation
-	var s, _ = ConstructFromState(false, testState, testState.Participants[0])
+	var s, _ = ConstructFromState(false, testState, testState.Participants[0], getNoHoldings)
 	var correctSignatureByAliceOnPreFund, _ = s.C.PreFundState().Sign(alice.PrivateKey)
 	var correctSignatureByBobOnPreFund, _ = s.C.PreFundState().Sign(bob.PrivateKey)
 
@@ -208,7 +285,9 @@ func TestCrank(t *testing.T) {
 	}
 	expectedFundingSideEffects := protocols.SideEffects{
 		TransactionsToSubmit: []protocols.ChainTransaction{
-			protocols.NewDepositTransaction(s.C.Id, types.Funds{
+			protocols.NewDepositTransactionWithExpectedHeld(s.C.Id, types.Funds{
+				testState.Outcome[0].Asset: testState.Outcome[0].Allocations[0].Amount,
+			}, types.Funds{
 				testState.Outcome[0].Asset: testState.Outcome[0].Allocations[0].Amount,
 			})}}
 	// END test data preparation
@@ -304,7 +383,7 @@ func TestClone(t *testing.T) {
 		return cmp.Diff(&a, &b, cmp.AllowUnexported(Objective{}, channel.Channel{}, big.Int{}, state.SignedState{}))
 	}
 
-	var s, _ = ConstructFromState(false, testState, testState.Participants[0])
+	var s, _ = ConstructFromState(false, testState, testState.Participants[0], getNoHoldings)
 
 	clone := s.clone()
 
@@ -314,7 +393,7 @@ func TestClone(t *testing.T) {
 }
 
 func TestMarshalJSON(t *testing.T) {
-	dfo, _ := ConstructFromState(false, testState, testState.Participants[0])
+	dfo, _ := ConstructFromState(false, testState, testState.Participants[0], getNoHoldings)
 
 	encodedDfo, err := json.Marshal(dfo)
 
@@ -348,7 +427,7 @@ func TestMarshalJSON(t *testing.T) {
 }
 
 func TestApproveReject(t *testing.T) {
-	o, err := ConstructFromState(false, testState, testState.Participants[0])
+	o, err := ConstructFromState(false, testState, testState.Participants[0], getNoHoldings)
 	testhelpers.Ok(t, err)
 
 	approved := o.Approve()
@@ -6,6 +6,7 @@ import (
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -27,15 +28,25 @@ func (cct ChainTransactionBase) ChannelId() types.Destination {
 	return cct.channelId
 }
 
+// DepositTransaction deposits funds into a channel.
+// ExpectedHeld records the holdings the depositor believed the channel to have when it computed the deposit, for some or all of the assets.
+// The holdings of those assets are topped up to ExpectedHeld + Deposit, so a deposit made concurrently by another participant is not paid for twice.
+// For other assets the deposit is added to whatever is held when the transaction is sent.
 type DepositTransaction struct {
 	ChainTransaction
-	Deposit types.Funds
+	Deposit      types.Funds
+	ExpectedHeld types.Funds
 }
 
 func NewDepositTransaction(channelId types.Destination, deposit types.Funds) DepositTransaction {
 	return DepositTransaction{ChainTransaction: ChainTransactionBase{channelId: channelId}, Deposit: deposit}
 }
 
+// NewDepositTransactionWithExpectedHeld returns a DepositTransaction which tops the holdings of the channel up to expectedHeld + deposit.
+func NewDepositTransactionWithExpectedHeld(channelId types.Destination, deposit types.Funds, expectedHeld types.Funds) DepositTransaction {
+	return DepositTransaction{ChainTransaction: ChainTransactionBase{channelId: channelId}, Deposit: deposit, ExpectedHeld: expectedHeld}
+}
+
 type WithdrawAllTransaction struct {
 	ChainTransaction
 	SignedState state.SignedState
@@ -45,6 +56,92 @@ func NewWithdrawAllTransaction(channelId types.Destination, signedState state.Si
 	return WithdrawAllTransaction{SignedState: signedState, ChainTransaction: ChainTransactionBase{channelId: channelId}}
 }
 
+// ChallengeTransaction registers a challenge on chain with a supported candidate state.
+// ChallengerSig is the challenger's signature on the candidate, which proves that the challenger is a participant in the channel.
+type ChallengeTransaction struct {
+	ChainTransaction
+	Candidate     state.SignedState
+	ChallengerSig state.Signature
+}
+
+func NewChallengeTransaction(channelId types.Destination, candidate state.SignedState, challengerSig state.Signature) ChallengeTransaction {
+	return ChallengeTransaction{Candidate: candidate, ChallengerSig: challengerSig, ChainTransaction: ChainTransactionBase{channelId: channelId}}
+}
+
+// CheckpointTransaction records a supported state on chain, clearing any challenge registered with an older state.
+type CheckpointTransaction struct {
+	ChainTransaction
+	SignedState state.SignedState
+}
+
+func NewCheckpointTransaction(channelId types.Destination, signedState state.SignedState) CheckpointTransaction {
+	return CheckpointTransaction{SignedState: signedState, ChainTransaction: ChainTransactionBase{channelId: channelId}}
+}
+
+// TransferAllTransaction pays out the outcome of a finalized channel. State must be the state whose outcome was finalized on chain.
+type TransferAllTransaction struct {
+	ChainTransaction
+	State state.State
+}
+
+func NewTransferAllTransaction(channelId types.Destination, s state.State) TransferAllTransaction {
+	return TransferAllTransaction{State: s, ChainTransaction: ChainTransactionBase{channelId: channelId}}
+}
+
+// TransferTransaction pays out some of the allocations of a single asset in a finalized channel.
+//
+// State is the state which was finalized on chain, and Outcome is the channel's outcome as currently recorded on chain
+// (which differs from State.Outcome once allocations have been paid out). Indices lists the allocations of Outcome[AssetIndex]
+// to pay out, in increasing order. If Indices is empty, every allocation is paid out.
+type TransferTransaction struct {
+	ChainTransaction
+	State      state.State
+	Outcome    outcome.Exit
+	AssetIndex uint
+	Indices    []uint
+}
+
+func NewTransferTransaction(channelId types.Destination, s state.State, o outcome.Exit, assetIndex uint, indices []uint) TransferTransaction {
+	return TransferTransaction{State: s, Outcome: o, AssetIndex: assetIndex, Indices: indices, ChainTransaction: ChainTransactionBase{channelId: channelId}}
+}
+
+// ClaimTransaction pays out the allocations of a finalized target channel, using a guarantee for the target in a finalized source channel.
+// The transaction's channel is the source channel.
+//
+// Source and Target are the states which were finalized on chain, and SourceOutcome and TargetOutcome are the channels' outcomes as currently recorded on chain.
+// IndexOfTargetInSource locates the guarantee among the allocations of SourceOutcome[SourceAssetIndex].
+// TargetAllocationIndicesToPayout lists the allocations of TargetOutcome[TargetAssetIndex] to pay out. If it is empty, every allocation is paid out.
+//
+// The deployed adjudicator checks for the guarantee at index TargetAssetIndex of the source's allocations rather than at IndexOfTargetInSource,
+// so a claim only succeeds if the guarantee is at both indices. Ledger channels laid out by go-nitro (guarantees after the two balances) cannot
+// be claimed from until the adjudicator is fixed.
+type ClaimTransaction struct {
+	ChainTransaction
+	Source                          state.State
+	SourceOutcome                   outcome.Exit
+	SourceAssetIndex                uint
+	IndexOfTargetInSource           uint
+	Target                          state.State
+	TargetOutcome                   outcome.Exit
+	TargetAssetIndex                uint
+	TargetAllocationIndicesToPayout []uint
+}
+
+func NewClaimTransaction(source state.State, sourceOutcome outcome.Exit, sourceAssetIndex uint, indexOfTargetInSource uint,
+	target state.State, targetOutcome outcome.Exit, targetAssetIndex uint, targetAllocationIndicesToPayout []uint) ClaimTransaction {
+	return ClaimTransaction{
+		ChainTransaction:                ChainTransactionBase{channelId: source.ChannelId()},
+		Source:                          source,
+		SourceOutcome:                   sourceOutcome,
+		SourceAssetIndex:                sourceAssetIndex,
+		IndexOfTargetInSource:           indexOfTargetInSource,
+		Target:                          target,
+		TargetOutcome:                   targetOutcome,
+		TargetAssetIndex:                targetAssetIndex,
+		TargetAllocationIndicesToPayout: targetAllocationIndicesToPayout,
+	}
+}
+
 // SideEffects are effects to be executed by an imperative shell
 type SideEffects struct {
 	MessagesToSend       []Message
@@ -58,9 +155,9 @@ type WaitingFor string
 // AdjudicationStatus mirrors the on chain adjudication status of a particular channel.
 // Everything that is stored on chain, other than holdings.
 type AdjudicationStatus struct {
-	TurnNumRecord uint
-	// TODO This struct is a placeholder for the time being, until we add a chain-service
-	// TODO eventually this struct will contain the other fields stored in (or committed to by) the adjudicator
+	TurnNumRecord uint64
+	FinalizesAt   uint64   // the chain timestamp (in seconds) at which the channel's outcome becomes final, or 0 if no challenge is ongoing
+	Fingerprint   [20]byte // the low 160 bits of the hash of the recorded state hash and outcome hash
 }
 
 // ObjectiveEvent holds information used to update an Objective. Some fields may be nil.
@@ -88,6 +185,7 @@ type Objective interface {
 
 	Approve() Objective                                                  // returns an updated Objective (a copy, no mutation allowed), does not declare effects
 	Reject() Objective                                                   // returns an updated Objective (a copy, no mutation allowed), does not declare effects
+	Fail() Objective                                                     // returns an updated Objective (a copy, no mutation allowed), does not declare effects
 	Update(event ObjectiveEvent) (Objective, error)                      // returns an updated Objective (a copy, no mutation allowed), does not declare effects
 	Crank(secretKey *[]byte) (Objective, SideEffects, WaitingFor, error) // does *not* accept an event, but *does* accept a pointer to a signing key; declare side effects; return an updated Objective
 
@@ -101,6 +199,13 @@ type Objective interface {
 	GetStatus() ObjectiveStatus
 }
 
+// Resumable describes objectives which can regenerate the messages they have previously sent to counterparties.
+// This allows a client which is restarted part way through a protocol to re-engage peers that may have missed those messages.
+type Resumable interface {
+	// Resume returns side effects which resend the latest signed states and ledger proposals (or countersignatures) held by the objective.
+	Resume() SideEffects
+}
+
 // ObjectiveId is a unique identifier for an Objective.
 type ObjectiveId string
 
@@ -111,6 +216,7 @@ const (
 	Approved
 	Rejected
 	Completed
+	Failed // the objective was abandoned after an error was encountered while handling it
 )
 
 // ObjectiveRequest is a request to create a new objective.
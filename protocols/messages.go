@@ -3,11 +3,14 @@ package protocols
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -18,10 +21,36 @@ const (
 	SignedProposalPayload PayloadType = "SignedProposalPayload"
 )
 
-// Message is an object to be sent across the wire. It can contain a proposal and signed states, and is addressed to a counterparty.
+// Message is an object to be sent across the wire. It can contain a proposal and signed states, as well as payment vouchers, and is addressed to a counterparty.
+// A message addressed to a watchtower carries the supported states of the channels it should guard.
+//
+// A message may be signed by its sender (see Sign). The From field of a message received from a peer can only be trusted if its Signer is From.
 type Message struct {
-	To       types.Address
-	payloads []messagePayload
+	To            types.Address
+	From          types.Address
+	payloads      []messagePayload
+	payments      []payments.Voucher
+	watchedStates []state.SignedState
+	delivery      *DeliveryInfo
+	hello         *Hello
+	signature     *nc.Signature
+}
+
+// Hello announces the url on which the sender's message service listens, so that the recipient can send messages to the sender
+// without being configured with its url. A hello is only trustworthy if the message carrying it is signed.
+type Hello struct {
+	Url      string
+	IssuedAt uint64 // the time the hello was created, in milliseconds since the unix epoch. A peer's later hellos supersede its earlier ones.
+}
+
+// DeliveryInfo is attached to messages by message services which retry messages until they are acknowledged, so that the recipient
+// can acknowledge the message and discard duplicates. It is not interpreted by the engine.
+//
+// DeliveryInfo is covered by the message's signature, so a message service which attaches it must sign the message again.
+// The recipient should only act on the DeliveryInfo of a message whose Signer is its From.
+type DeliveryInfo struct {
+	Id   string   // uniquely identifies the message among those sent by the message's sender. It is empty for messages which only carry acknowledgements.
+	Acks []string // the ids of messages from the recipient which the sender has received
 }
 
 // messagePayload is an objective id and EITHER a SignedState or SignedProposal. This package guarantees that a payload has only one value by:
@@ -41,7 +70,7 @@ func (p messagePayload) hasState() bool {
 
 // hasProposal returns true if the payload contains a signed proposal.
 func (p messagePayload) hasProposal() bool {
-	return p.SignedProposal.Proposal != consensus_channel.Proposal{}
+	return !p.SignedProposal.Proposal.Equal(&consensus_channel.Proposal{})
 }
 
 // Type returns the type of the payload, either a SignedProposal or SignedState.
@@ -91,16 +120,127 @@ func (m Message) SignedProposals() []ObjectivePayload[consensus_channel.SignedPr
 	return signedProposals
 }
 
+// Payments returns the payment vouchers contained in the message.
+func (m Message) Payments() []payments.Voucher {
+	return m.payments
+}
+
+// WatchedStates returns the supported states a watchtower is asked to guard.
+func (m Message) WatchedStates() []state.SignedState {
+	return m.watchedStates
+}
+
+// Delivery returns the delivery information attached to the message, and false if there is none.
+func (m Message) Delivery() (DeliveryInfo, bool) {
+	if m.delivery == nil {
+		return DeliveryInfo{}, false
+	}
+	return *m.delivery, true
+}
+
+// WithDelivery returns a copy of the message with the supplied delivery information attached.
+func (m Message) WithDelivery(d DeliveryInfo) Message {
+	m.delivery = &d
+	return m
+}
+
+// Hello returns the hello carried by the message, and false if there is none.
+func (m Message) Hello() (Hello, bool) {
+	if m.hello == nil {
+		return Hello{}, false
+	}
+	return *m.hello, true
+}
+
+// Sign returns a copy of the message sent from the address of the supplied secret key, and signed with it.
+// The signature covers the whole message, including any delivery information.
+func (m Message) Sign(secretKey []byte) (Message, error) {
+	m.From = nc.GetAddressFromSecretKeyBytes(secretKey)
+	m.signature = nil
+
+	serialized, err := json.Marshal(m.toJSON())
+	if err != nil {
+		return Message{}, err
+	}
+	content, err := signedContentOf(serialized)
+	if err != nil {
+		return Message{}, err
+	}
+	signature, err := nc.SignEthereumMessage(content, secretKey)
+	if err != nil {
+		return Message{}, err
+	}
+	m.signature = &signature
+	return m, nil
+}
+
+// Signer recovers the address which signed the message. An error wrapping ErrInvalidMessageSignature is returned if the message is not signed.
+func (m Message) Signer() (types.Address, error) {
+	if m.signature == nil {
+		return types.Address{}, fmt.Errorf("%w: message has no signature", ErrInvalidMessageSignature)
+	}
+	serialized, err := json.Marshal(m.toJSON())
+	if err != nil {
+		return types.Address{}, err
+	}
+	content, err := signedContentOf(serialized)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return nc.RecoverEthereumMessageSigner(content, *m.signature)
+}
+
+// HasSignature returns true if the message carries a signature. It does not check who made the signature: see Signer.
+func (m Message) HasSignature() bool {
+	return m.signature != nil
+}
+
+// IsEmpty returns true if the message carries nothing for the engine: no payloads, payments or watched states.
+func (m Message) IsEmpty() bool {
+	return len(m.payloads) == 0 && len(m.payments) == 0 && len(m.watchedStates) == 0
+}
+
 // Serialize serializes the message into a string.
 func (m Message) Serialize() (string, error) {
-	bytes, err := json.Marshal(jsonMessage{m.To, m.payloads})
+	bytes, err := json.Marshal(m.toJSON())
 	return string(bytes), err
 }
 
+func (m Message) toJSON() jsonMessage {
+	return jsonMessage{m.To, m.From, m.payloads, m.payments, m.watchedStates, m.delivery, m.hello, m.signature}
+}
+
 // jsonMessage is a private struct with public members, allowing a Message to be easily serialized
 type jsonMessage struct {
-	To       types.Address
-	Payloads []messagePayload
+	To            types.Address
+	From          types.Address
+	Payloads      []messagePayload
+	Payments      []payments.Voucher  `json:",omitempty"`
+	WatchedStates []state.SignedState `json:",omitempty"`
+	Delivery      *DeliveryInfo       `json:",omitempty"`
+	Hello         *Hello              `json:",omitempty"`
+	Signature     *nc.Signature       `json:",omitempty"`
+}
+
+// signedContent holds the fields of a serialized message which are covered by its signature, exactly as they were serialized.
+// Re-serializing it gives the same bytes for the sender and recipient, however the recipient's json library represents the fields' values.
+type signedContent struct {
+	To            json.RawMessage
+	From          json.RawMessage
+	Payloads      json.RawMessage
+	Payments      json.RawMessage `json:",omitempty"`
+	WatchedStates json.RawMessage `json:",omitempty"`
+	Delivery      json.RawMessage `json:",omitempty"`
+	Hello         json.RawMessage `json:",omitempty"`
+}
+
+// signedContentOf returns the bytes covered by the signature of the serialized message.
+func signedContentOf(serialized []byte) ([]byte, error) {
+	var content signedContent
+	if err := json.Unmarshal(serialized, &content); err != nil {
+		return nil, err
+	}
+	return json.Marshal(content)
 }
 
 // MarshalJSON provides a custom json marshaler that avoids marshaling empty structs
@@ -122,10 +262,17 @@ func (p *messagePayload) MarshalJSON() ([]byte, error) {
 // ErrInvalidPayload is returned when the payload has too many values
 var ErrInvalidPayload = fmt.Errorf("payload has too many values")
 
+// ErrInvalidMessageSignature is returned when a message is signed by someone other than its sender
+var ErrInvalidMessageSignature = errors.New("message is not signed by its sender")
+
 // DeserializeMessage deserializes the passed string into a protocols.Message.
+// If the message is signed, an error is returned unless it was signed by its sender.
 func DeserializeMessage(s string) (Message, error) {
 	msg := jsonMessage{}
 	err := json.Unmarshal([]byte(s), &msg)
+	if err != nil {
+		return Message{}, err
+	}
 
 	for _, p := range msg.Payloads {
 		numPresent := 0
@@ -140,7 +287,21 @@ func DeserializeMessage(s string) (Message, error) {
 		}
 	}
 
-	return Message{To: msg.To, payloads: msg.Payloads}, err
+	if msg.Signature != nil {
+		content, err := signedContentOf([]byte(s))
+		if err != nil {
+			return Message{}, err
+		}
+		signer, err := nc.RecoverEthereumMessageSigner(content, *msg.Signature)
+		if err != nil {
+			return Message{}, fmt.Errorf("%w: %v", ErrInvalidMessageSignature, err)
+		}
+		if signer != msg.From {
+			return Message{}, fmt.Errorf("%w: message from %s is signed by %s", ErrInvalidMessageSignature, msg.From, signer)
+		}
+	}
+
+	return Message{To: msg.To, From: msg.From, payloads: msg.Payloads, payments: msg.Payments, watchedStates: msg.WatchedStates, delivery: msg.Delivery, hello: msg.Hello, signature: msg.Signature}, nil
 }
 
 // CreateSignedStateMessages creates a set of messages containing the signed state.
@@ -213,11 +374,20 @@ type StateSummary struct {
 	TurnNum     uint64
 }
 
+// PaymentSummary contains some basic info about a payment voucher for logging.
+type PaymentSummary struct {
+	ChannelId string
+	Amount    string
+}
+
 // MessagarSummary contains some basic info about a message for logging.
 type MessageSummary struct {
-	To        string
-	Proposals []ProposalSummary
-	States    []StateSummary
+	To            string
+	From          string
+	Proposals     []ProposalSummary
+	States        []StateSummary
+	Payments      []PaymentSummary
+	WatchedStates []StateSummary
 }
 
 // SummarizeMessage returns a MessageSummary for the provided message.
@@ -238,7 +408,23 @@ func SummarizeMessage(m Message) MessageSummary {
 		}
 	}
 
-	return MessageSummary{To: m.To.String(), Proposals: proposals, States: states}
+	payments := make([]PaymentSummary, len(m.payments))
+	for i, v := range m.payments {
+		payments[i] = PaymentSummary{
+			ChannelId: v.ChannelId.String(),
+			Amount:    v.Amount.String(),
+		}
+	}
+
+	watched := make([]StateSummary, len(m.watchedStates))
+	for i, s := range m.watchedStates {
+		watched[i] = StateSummary{
+			ChannelId: s.ChannelId().String(),
+			TurnNum:   s.State().TurnNum,
+		}
+	}
+
+	return MessageSummary{To: m.To.String(), From: m.From.String(), Proposals: proposals, States: states, Payments: payments, WatchedStates: watched}
 }
 
 // SummarizeProposal returns a ProposalSummary for the provided signed proposal.
@@ -272,6 +458,31 @@ func CreateSignedProposalMessage(recipient types.Address, proposals ...consensus
 	}
 }
 
+// CreateVoucherMessage returns a message addressed to the recipient containing the supplied payment vouchers.
+func CreateVoucherMessage(recipient types.Address, vouchers ...payments.Voucher) Message {
+	return Message{
+		To:       recipient,
+		payments: vouchers,
+	}
+}
+
+// CreateWatchMessage returns a message addressed to a watchtower, asking it to guard the channels of the supplied supported states.
+func CreateWatchMessage(watchtower types.Address, states ...state.SignedState) Message {
+	return Message{
+		To:            watchtower,
+		watchedStates: states,
+	}
+}
+
+// CreateHelloMessage returns a message announcing to the recipient that the sender's message service listens on the given url.
+// The message should be signed, since a recipient only trusts a signed hello.
+func CreateHelloMessage(recipient types.Address, url string, issuedAt uint64) Message {
+	return Message{
+		To:    recipient,
+		hello: &Hello{Url: url, IssuedAt: issuedAt},
+	}
+}
+
 // getProposalObjectiveId returns the objectiveId for a proposal.
 func getProposalObjectiveId(p consensus_channel.Proposal) ObjectiveId {
 	switch p.Type() {
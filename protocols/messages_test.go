@@ -4,20 +4,23 @@ import (
 	"errors"
 	"math/big"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/types"
 )
 
 func removeProposal() consensus_channel.SignedProposal {
-	remove := consensus_channel.NewRemoveProposal(types.Destination{'l'}, types.Destination{'a'}, big.NewInt(1))
+	remove := consensus_channel.NewRemoveProposal(types.Destination{'l'}, types.Destination{'a'}, types.Funds{types.Address{}: big.NewInt(1)})
 	return consensus_channel.SignedProposal{Proposal: remove, Signature: state.Signature{}}
 }
 
 func addProposal() consensus_channel.SignedProposal {
-	amount := big.NewInt(1)
+	amount := types.Funds{types.Address{}: big.NewInt(1)}
 	add := consensus_channel.NewAddProposal(types.Destination{'l'}, consensus_channel.NewGuarantee(
 		amount,
 		types.Destination{'a'},
@@ -49,7 +52,7 @@ func TestMessage(t *testing.T) {
 	}
 
 	msgString :=
-		`{"To":"0x6100000000000000000000000000000000000000","Payloads":[{"ObjectiveId":"say-hello-to-my-little-friend","SignedState":{"State":{"ChainId":9001,"Participants":["0xf5a1bb5607c9d079e46d1b3dc33f257d937b43bd","0x760bf27cd45036a6c486802d30b5d90cffbe31fe"],"ChannelNonce":37140676580,"AppDefinition":"0x5e29e5ab8ef33f050c7cc10b5a0456d975c5f88d","ChallengeDuration":60,"AppData":"","Outcome":[{"Asset":"0x0000000000000000000000000000000000000000","Metadata":null,"Allocations":[{"Destination":"0x000000000000000000000000f5a1bb5607c9d079e46d1b3dc33f257d937b43bd","Amount":5,"AllocationType":0,"Metadata":null},{"Destination":"0x000000000000000000000000ee18ff1575055691009aa246ae608132c57a422c","Amount":5,"AllocationType":0,"Metadata":null}]}],"TurnNum":5,"IsFinal":false},"Sigs":{}}},{"ObjectiveId":"say-hello-to-my-little-friend2","SignedProposal":{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":1,"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","Left":"0x6200000000000000000000000000000000000000000000000000000000000000","Right":"0x6300000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":1},"ToRemove":{"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","LeftAmount":null}},"TurnNum":0}},{"ObjectiveId":"say-hello-to-my-little-friend3","SignedProposal":{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":null,"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","Left":"0x0000000000000000000000000000000000000000000000000000000000000000","Right":"0x0000000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":null},"ToRemove":{"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","LeftAmount":1}},"TurnNum":0}}]}`
+		`{"To":"0x6100000000000000000000000000000000000000","From":"0x0000000000000000000000000000000000000000","Payloads":[{"ObjectiveId":"say-hello-to-my-little-friend","SignedState":{"State":{"ChainId":9001,"Participants":["0xf5a1bb5607c9d079e46d1b3dc33f257d937b43bd","0x760bf27cd45036a6c486802d30b5d90cffbe31fe"],"ChannelNonce":37140676580,"AppDefinition":"0x5e29e5ab8ef33f050c7cc10b5a0456d975c5f88d","ChallengeDuration":60,"AppData":"","Outcome":[{"Asset":"0x0000000000000000000000000000000000000000","Metadata":null,"Allocations":[{"Destination":"0x000000000000000000000000f5a1bb5607c9d079e46d1b3dc33f257d937b43bd","Amount":5,"AllocationType":0,"Metadata":null},{"Destination":"0x000000000000000000000000ee18ff1575055691009aa246ae608132c57a422c","Amount":5,"AllocationType":0,"Metadata":null}]}],"TurnNum":5,"IsFinal":false},"Sigs":{}}},{"ObjectiveId":"say-hello-to-my-little-friend2","SignedProposal":{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":{"0x0000000000000000000000000000000000000000":1},"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","Left":"0x6200000000000000000000000000000000000000000000000000000000000000","Right":"0x6300000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":{"0x0000000000000000000000000000000000000000":1}},"ToRemove":{"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","LeftAmount":null}},"TurnNum":0}},{"ObjectiveId":"say-hello-to-my-little-friend3","SignedProposal":{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":null,"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","Left":"0x0000000000000000000000000000000000000000000000000000000000000000","Right":"0x0000000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":null},"ToRemove":{"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","LeftAmount":{"0x0000000000000000000000000000000000000000":1}}},"TurnNum":0}}]}`
 
 	t.Run(`serialize`, func(t *testing.T) {
 		got, err := msg.Serialize()
@@ -75,7 +78,7 @@ func TestMessage(t *testing.T) {
 
 	t.Run(`validation`, func(t *testing.T) {
 
-		invalidMsg := `{"To":"0x6100000000000000000000000000000000000000","Payloads":[{"ObjectiveId":"say-hello-to-my-little-friend","SignedState":{"State":{"ChainId":9001,"Participants":["0xf5a1bb5607c9d079e46d1b3dc33f257d937b43bd","0x760bf27cd45036a6c486802d30b5d90cffbe31fe"],"ChannelNonce":37140676580,"AppDefinition":"0x5e29e5ab8ef33f050c7cc10b5a0456d975c5f88d","ChallengeDuration":60,"AppData":"","Outcome":[{"Asset":"0x0000000000000000000000000000000000000000","Metadata":null,"Allocations":[{"Destination":"0x000000000000000000000000f5a1bb5607c9d079e46d1b3dc33f257d937b43bd","Amount":5,"AllocationType":0,"Metadata":null},{"Destination":"0x000000000000000000000000ee18ff1575055691009aa246ae608132c57a422c","Amount":5,"AllocationType":0,"Metadata":null}]}],"TurnNum":5,"IsFinal":false},"Sigs":{}},"SignedProposal":{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":1,"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","Left":"0x6200000000000000000000000000000000000000000000000000000000000000","Right":"0x6300000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":1},"ToRemove":{"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","LeftAmount":null}},"TurnNum":0}}]}`
+		invalidMsg := `{"To":"0x6100000000000000000000000000000000000000","Payloads":[{"ObjectiveId":"say-hello-to-my-little-friend","SignedState":{"State":{"ChainId":9001,"Participants":["0xf5a1bb5607c9d079e46d1b3dc33f257d937b43bd","0x760bf27cd45036a6c486802d30b5d90cffbe31fe"],"ChannelNonce":37140676580,"AppDefinition":"0x5e29e5ab8ef33f050c7cc10b5a0456d975c5f88d","ChallengeDuration":60,"AppData":"","Outcome":[{"Asset":"0x0000000000000000000000000000000000000000","Metadata":null,"Allocations":[{"Destination":"0x000000000000000000000000f5a1bb5607c9d079e46d1b3dc33f257d937b43bd","Amount":5,"AllocationType":0,"Metadata":null},{"Destination":"0x000000000000000000000000ee18ff1575055691009aa246ae608132c57a422c","Amount":5,"AllocationType":0,"Metadata":null}]}],"TurnNum":5,"IsFinal":false},"Sigs":{}},"SignedProposal":{"R":null,"S":null,"V":0,"Proposal":{"LedgerID":"0x6c00000000000000000000000000000000000000000000000000000000000000","ToAdd":{"Guarantee":{"Amount":{"0x0000000000000000000000000000000000000000":1},"Target":"0x6100000000000000000000000000000000000000000000000000000000000000","Left":"0x6200000000000000000000000000000000000000000000000000000000000000","Right":"0x6300000000000000000000000000000000000000000000000000000000000000"},"LeftDeposit":{"0x0000000000000000000000000000000000000000":1}},"ToRemove":{"Target":"0x0000000000000000000000000000000000000000000000000000000000000000","LeftAmount":null}},"TurnNum":0}}]}`
 
 		_, err := DeserializeMessage(invalidMsg)
 		if !errors.Is(err, ErrInvalidPayload) {
@@ -83,3 +86,196 @@ func TestMessage(t *testing.T) {
 		}
 	})
 }
+
+func TestVoucherMessage(t *testing.T) {
+	voucher := payments.Voucher{ChannelId: types.Destination{'a'}, Amount: big.NewInt(7), Signature: state.Signature{R: []byte{1}, S: []byte{2}, V: 27}}
+	msg := CreateVoucherMessage(types.Address{'b'}, voucher)
+
+	msgString := `{"To":"0x6200000000000000000000000000000000000000","From":"0x0000000000000000000000000000000000000000","Payloads":null,"Payments":[{"ChannelId":"0x6100000000000000000000000000000000000000000000000000000000000000","Amount":7,"Signature":{"R":"AQ==","S":"Ag==","V":27}}]}`
+
+	got, err := msg.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != msgString {
+		t.Fatalf("incorrect serialization: got:\n%v\nwanted:\n%v", got, msgString)
+	}
+
+	deserialized, err := DeserializeMessage(msgString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deserialized, msg) {
+		t.Errorf("incorrect deserialization: got:\n%v\nwanted:\n%v", deserialized, msg)
+	}
+	if len(deserialized.Payments()) != 1 || !deserialized.Payments()[0].Equal(voucher) {
+		t.Errorf("expected message to contain voucher %+v, got %+v", voucher, deserialized.Payments())
+	}
+}
+
+func TestWatchMessage(t *testing.T) {
+	ss := state.NewSignedState(state.TestState)
+	msg := CreateWatchMessage(types.Address{'w'}, ss)
+
+	msgString := `{"To":"0x7700000000000000000000000000000000000000","From":"0x0000000000000000000000000000000000000000","Payloads":null,"WatchedStates":[{"State":{"ChainId":9001,"Participants":["0xf5a1bb5607c9d079e46d1b3dc33f257d937b43bd","0x760bf27cd45036a6c486802d30b5d90cffbe31fe"],"ChannelNonce":37140676580,"AppDefinition":"0x5e29e5ab8ef33f050c7cc10b5a0456d975c5f88d","ChallengeDuration":60,"AppData":"","Outcome":[{"Asset":"0x0000000000000000000000000000000000000000","Metadata":null,"Allocations":[{"Destination":"0x000000000000000000000000f5a1bb5607c9d079e46d1b3dc33f257d937b43bd","Amount":5,"AllocationType":0,"Metadata":null},{"Destination":"0x000000000000000000000000ee18ff1575055691009aa246ae608132c57a422c","Amount":5,"AllocationType":0,"Metadata":null}]}],"TurnNum":5,"IsFinal":false},"Sigs":{}}]}`
+
+	got, err := msg.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != msgString {
+		t.Fatalf("incorrect serialization: got:\n%v\nwanted:\n%v", got, msgString)
+	}
+
+	deserialized, err := DeserializeMessage(msgString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deserialized, msg) {
+		t.Errorf("incorrect deserialization: got:\n%v\nwanted:\n%v", deserialized, msg)
+	}
+	if len(deserialized.WatchedStates()) != 1 || !deserialized.WatchedStates()[0].State().Equal(ss.State()) {
+		t.Errorf("expected message to contain state %+v, got %+v", ss, deserialized.WatchedStates())
+	}
+}
+
+func TestDeliveryInfo(t *testing.T) {
+	voucher := payments.Voucher{ChannelId: types.Destination{'a'}, Amount: big.NewInt(7), Signature: state.Signature{R: []byte{1}, S: []byte{2}, V: 27}}
+	msg := CreateVoucherMessage(types.Address{'b'}, voucher).WithDelivery(DeliveryInfo{Id: "s-1", Acks: []string{"t-1", "t-2"}})
+
+	msgString := `{"To":"0x6200000000000000000000000000000000000000","From":"0x0000000000000000000000000000000000000000","Payloads":null,"Payments":[{"ChannelId":"0x6100000000000000000000000000000000000000000000000000000000000000","Amount":7,"Signature":{"R":"AQ==","S":"Ag==","V":27}}],"Delivery":{"Id":"s-1","Acks":["t-1","t-2"]}}`
+
+	got, err := msg.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != msgString {
+		t.Fatalf("incorrect serialization: got:\n%v\nwanted:\n%v", got, msgString)
+	}
+
+	deserialized, err := DeserializeMessage(msgString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(deserialized, msg) {
+		t.Errorf("incorrect deserialization: got:\n%v\nwanted:\n%v", deserialized, msg)
+	}
+	if _, ok := CreateVoucherMessage(types.Address{'b'}, voucher).Delivery(); ok {
+		t.Errorf("expected a message without delivery information")
+	}
+}
+
+func TestSignedMessage(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+	msg, err := CreateSignedProposalMessage(bob.Address(), addProposal(), removeProposal()).Sign(alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.From != alice.Address() || !msg.HasSignature() {
+		t.Fatalf("expected a message signed by alice, got one from %s", msg.From)
+	}
+	if signer, err := msg.Signer(); err != nil || signer != alice.Address() {
+		t.Fatalf("expected the message to be signed by alice, got %s (%v)", signer, err)
+	}
+
+	t.Run("round trip", func(t *testing.T) {
+		withDelivery, err := msg.WithDelivery(DeliveryInfo{Id: "s-1", Acks: []string{"t-1"}}).Sign(alice.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		serialized, err := withDelivery.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DeserializeMessage(serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, withDelivery) {
+			t.Errorf("incorrect deserialization: got:\n%v\nwanted:\n%v", got, withDelivery)
+		}
+		if signer, err := got.Signer(); err != nil || signer != alice.Address() {
+			t.Errorf("expected the deserialized message to be signed by %s, got %s (%v)", alice.Address(), signer, err)
+		}
+	})
+
+	t.Run("delivery attached after signing", func(t *testing.T) {
+		// Delivery information is covered by the signature, so attaching it invalidates the signature
+		serialized, err := msg.WithDelivery(DeliveryInfo{Id: "s-1"}).Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DeserializeMessage(serialized); !errors.Is(err, ErrInvalidMessageSignature) {
+			t.Errorf("expected %v, got %v", ErrInvalidMessageSignature, err)
+		}
+		if signer, err := msg.WithDelivery(DeliveryInfo{Id: "s-1"}).Signer(); err == nil && signer == alice.Address() {
+			t.Errorf("expected the signature not to cover the attached delivery information")
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		serialized, err := msg.Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		aliceHex := strings.ToLower(alice.Address().Hex())
+		bobHex := strings.ToLower(bob.Address().Hex())
+		testCases := map[string]string{
+			"forged sender":     strings.Replace(serialized, `"From":"`+aliceHex, `"From":"`+bobHex, 1),
+			"altered recipient": strings.Replace(serialized, `"To":"`+bobHex, `"To":"`+aliceHex, 1),
+			"altered payload":   strings.Replace(serialized, `"TurnNum":0`, `"TurnNum":1`, 1),
+		}
+		for name, tampered := range testCases {
+			if tampered == serialized {
+				t.Fatalf("%s: expected the serialized message to be altered", name)
+			}
+			if _, err := DeserializeMessage(tampered); !errors.Is(err, ErrInvalidMessageSignature) {
+				t.Errorf("%s: expected %v, got %v", name, ErrInvalidMessageSignature, err)
+			}
+		}
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		serialized, err := CreateSignedProposalMessage(bob.Address(), addProposal()).Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DeserializeMessage(serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.HasSignature() {
+			t.Errorf("expected an unsigned message")
+		}
+		if _, err := got.Signer(); !errors.Is(err, ErrInvalidMessageSignature) {
+			t.Errorf("expected %v, got %v", ErrInvalidMessageSignature, err)
+		}
+	})
+}
+
+func TestHelloMessage(t *testing.T) {
+	msg, err := CreateHelloMessage(testactors.Bob.Address(), "localhost:3005", 1700000000000).Sign(testactors.Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serialized, err := msg.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DeserializeMessage(serialized)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hello, ok := got.Hello(); !ok || hello.Url != "localhost:3005" || hello.IssuedAt != 1700000000000 {
+		t.Fatalf("expected the hello to survive a round trip, got %+v", got)
+	}
+	if !got.IsEmpty() {
+		t.Errorf("expected a hello message to carry nothing for the engine")
+	}
+
+	// The hello is covered by the signature
+	tampered := strings.Replace(serialized, "localhost:3005", "localhost:6666", 1)
+	if _, err := DeserializeMessage(tampered); !errors.Is(err, ErrInvalidMessageSignature) {
+		t.Errorf("expected %v, got %v", ErrInvalidMessageSignature, err)
+	}
+}
@@ -65,7 +65,7 @@ func generateStoreGetters(myRole uint, vId types.Destination, vFinal state.State
 
 // generateGuarantee generates a guarantee for the given participants and vId
 func generateGuarantee(left, right ta.Actor, vId types.Destination) consensus_channel.Guarantee {
-	return consensus_channel.NewGuarantee(big.NewInt(10), vId, left.Destination(), right.Destination())
+	return consensus_channel.NewGuarantee(types.Funds{types.Address{}: big.NewInt(10)}, vId, left.Destination(), right.Destination())
 
 }
 
@@ -82,10 +82,10 @@ func prepareConsensusChannel(role uint, left, right ta.Actor, guarantees ...cons
 		ChallengeDuration: big.NewInt(45),
 	}
 
-	leftBal := consensus_channel.NewBalance(left.Destination(), big.NewInt(0))
-	rightBal := consensus_channel.NewBalance(right.Destination(), big.NewInt(0))
+	leftBal := consensus_channel.NewBalance(left.Destination(), types.Funds{types.Address{}: big.NewInt(0)})
+	rightBal := consensus_channel.NewBalance(right.Destination(), types.Funds{types.Address{}: big.NewInt(0)})
 
-	lo := *consensus_channel.NewLedgerOutcome(types.Address{}, leftBal, rightBal, guarantees)
+	lo := *consensus_channel.NewLedgerOutcome([]types.Address{{}}, leftBal, rightBal, guarantees)
 
 	signedVars := consensus_channel.SignedVars{Vars: consensus_channel.Vars{Outcome: lo, TurnNum: 1}}
 	leftSig, err := signedVars.Vars.AsState(fp).Sign(left.PrivateKey)
@@ -244,7 +244,7 @@ type testdata struct {
 // generateRemoveProposal generates a remove proposal for the given channelId and test data
 func generateRemoveProposal(cId types.Destination, td testdata) consensus_channel.Proposal {
 	vId := td.vFinal.ChannelId()
-	return consensus_channel.NewRemoveProposal(cId, vId, big.NewInt(int64(td.finalAliceAmount)))
+	return consensus_channel.NewRemoveProposal(cId, vId, types.Funds{types.Address{}: big.NewInt(int64(td.finalAliceAmount))})
 
 }
 
@@ -16,8 +16,8 @@ import (
 type jsonObjective struct {
 	Status         protocols.ObjectiveStatus
 	VFixed         state.FixedPart
-	InitialOutcome outcome.SingleAssetExit
-	Signatures     [3]state.Signature
+	InitialOutcome outcome.Exit
+	Signatures     []state.Signature
 	PaidToBob      *big.Int
 	ToMyLeft       []byte
 	ToMyRight      []byte
@@ -15,6 +15,7 @@ import (
 )
 
 const (
+	WaitingForFinalStateProposal      protocols.WaitingFor = "WaitingForFinalStateProposal"      // Intermediaries only: the amount paid to Bob is unknown until Alice or Bob propose the final state
 	WaitingForCompleteFinal           protocols.WaitingFor = "WaitingForCompleteFinal"           // Round 1
 	WaitingForCompleteLedgerDefunding protocols.WaitingFor = "WaitingForCompleteLedgerDefunding" // Round 2
 	WaitingForNothing                 protocols.WaitingFor = "WaitingForNothing"                 // Finished
@@ -23,31 +24,35 @@ const (
 // The turn number used for the final state
 const FinalTurnNum = 2
 
+// ErrInvalidPaidToBob is returned when a proposed final state does not agree with the payments made on the virtual channel.
+var ErrInvalidPaidToBob = errors.New("final state does not match payments made")
+
 // Objective contains relevant information for the defund objective
 type Objective struct {
 	Status protocols.ObjectiveStatus
 
 	// InitialOutcome is the initial outcome of the virtual channel
-	InitialOutcome outcome.SingleAssetExit
+	InitialOutcome outcome.Exit
 
-	// PaidToBob is the amount that should be paid from Alice (participant 0) to Bob (participant 2)
+	// PaidToBob is the amount that should be paid from Alice (participant 0) to Bob (the last participant).
+	// It is nil for an intermediary until the intermediary receives the final state from Alice or Bob.
 	PaidToBob *big.Int
 
 	// VFixed is the fixed channel information for the virtual channel
 	VFixed state.FixedPart
 
 	// Signatures are the signatures for the final virtual state from each participant
-	// Signatures are ordered by participant order: Signatures[0] is Alice's signature, Signatures[1] is Irene's signature, Signatures[2] is Bob's signature
+	// Signatures are ordered by participant order: Signatures[0] is Alice's signature, followed by the intermediaries' signatures, and Bob's signature last
 	// Signatures gets updated as participants sign and send states to each other.
-	Signatures [3]state.Signature
+	Signatures []state.Signature
 
 	ToMyLeft  *consensus_channel.ConsensusChannel
 	ToMyRight *consensus_channel.ConsensusChannel
 
 	// MyRole is the index of the participant in the participants list
 	// 0 is Alice
-	// 1 is Irene
-	// 2 is Bob
+	// 1...n are the intermediaries
+	// n+1 is Bob
 	MyRole uint
 }
 
@@ -60,10 +65,14 @@ type GetChannelByIdFunction func(id types.Destination) (channel *channel.Channel
 // the calling client and the given counterparty, if such a channel exists.
 type GetTwoPartyConsensusLedgerFunction func(counterparty types.Address) (ledger *consensus_channel.ConsensusChannel, ok bool)
 
-// NewObjective constructs a new virtual defund objective
+// NewObjective constructs a new virtual defund objective, which pays paidToBob from Alice to Bob in the final state of the virtual channel.
+//
+// An intermediary does not track the payments made on the virtual channel, so may supply a nil paidToBob. The objective then waits for
+// Alice or Bob to propose the final state, and takes the amount paid to Bob from it.
 func NewObjective(request ObjectiveRequest,
 	preApprove bool,
 	myAddress types.Address,
+	paidToBob *big.Int,
 	getChannel GetChannelByIdFunction,
 	getConsensusChannel GetTwoPartyConsensusLedgerFunction) (Objective, error) {
 	var status protocols.ObjectiveStatus
@@ -79,47 +88,48 @@ func NewObjective(request ObjectiveRequest,
 		return Objective{}, fmt.Errorf("could not find channel %s", request.ChannelId)
 	}
 
-	initialOutcome := V.PostFundState().Outcome[0]
+	initialOutcome := V.PostFundState().Outcome
+	// Vouchers do not name an asset, so payments are only made on channels holding a single asset
+	if len(initialOutcome) != 1 && paidToBob != nil && paidToBob.Sign() != 0 {
+		return Objective{}, fmt.Errorf("%w: cannot pay bob %s on a channel holding more than one asset", ErrInvalidPaidToBob, paidToBob)
+	}
+
+	participants := V.Participants
+	if V.MyIndex >= uint(len(participants)) || participants[V.MyIndex] != myAddress {
+		return Objective{}, fmt.Errorf("client address not found in an expected participant index")
+	}
 
-	// This logic assumes a single hop virtual channel.
-	// Currently this is the only type of virtual channel supported.
-	alice := V.Participants[0]
-	intermediary := V.Participants[1]
-	bob := V.Participants[2]
+	isIntermediary := V.MyIndex > 0 && V.MyIndex < uint(len(participants)-1)
+	if paidToBob == nil && !isIntermediary {
+		return Objective{}, fmt.Errorf("the amount paid to bob must be supplied")
+	}
 
 	var toMyLeft, toMyRight *consensus_channel.ConsensusChannel
 	var ok bool
 
-	switch myAddress {
-	case alice:
-		toMyRight, ok = getConsensusChannel(intermediary)
-		if !ok {
-			return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", alice, intermediary)
-		}
-	case intermediary:
-		toMyLeft, ok = getConsensusChannel(alice)
+	// Everyone other than Alice has a ledger channel with the participant to their left
+	if V.MyIndex > 0 {
+		left := participants[V.MyIndex-1]
+		toMyLeft, ok = getConsensusChannel(left)
 		if !ok {
-			return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", alice, intermediary)
+			return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", left, myAddress)
 		}
-		toMyRight, ok = getConsensusChannel(bob)
-		if !ok {
-			return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", intermediary, bob)
-		}
-	case bob:
-		toMyLeft, ok = getConsensusChannel(intermediary)
+	}
+	// Everyone other than Bob has a ledger channel with the participant to their right
+	if V.MyIndex < uint(len(participants)-1) {
+		right := participants[V.MyIndex+1]
+		toMyRight, ok = getConsensusChannel(right)
 		if !ok {
-			return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", intermediary, bob)
+			return Objective{}, fmt.Errorf("could not find a ledger channel between %v and %v", myAddress, right)
 		}
-	default:
-		return Objective{}, fmt.Errorf("client address not found in an expected participant index")
-
 	}
+
 	return Objective{
 		Status:         status,
 		InitialOutcome: initialOutcome,
-		PaidToBob:      request.PaidToBob,
+		PaidToBob:      cloneAmount(paidToBob),
 		VFixed:         V.FixedPart,
-		Signatures:     [3]state.Signature{},
+		Signatures:     make([]state.Signature, len(participants)),
 		MyRole:         V.MyIndex,
 		ToMyLeft:       toMyLeft,
 		ToMyRight:      toMyRight,
@@ -129,12 +139,17 @@ func NewObjective(request ObjectiveRequest,
 
 // ConstructObjectiveFromState takes in a message and constructs an objective from it.
 // It accepts the message, myAddress, and a function to to retrieve ledgers from a store.
+//
+// largestPaymentAmount is the total the calling client has paid (as Alice) or been paid (as Bob) on the virtual channel, according to the vouchers it holds.
+// The proposed final state is rejected if it pays Bob more than Alice has paid, or less than Bob has been paid.
+// The intermediary does not track payments, and may supply a nil largestPaymentAmount.
 func ConstructObjectiveFromState(
 	initialState state.State,
 	preapprove bool,
 	myAddress types.Address,
 	getChannel GetChannelByIdFunction,
 	getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction,
+	largestPaymentAmount *big.Int,
 ) (Objective, error) {
 
 	err := initialState.FixedPart().Validate()
@@ -144,18 +159,28 @@ func ConstructObjectiveFromState(
 
 	channelId := initialState.ChannelId()
 
-	// TODO: Because there is no payment system we are not able to query or verify how much was paid.
-	// So the current behaviour is as follows:
-	// - whomever calls CloseVirtualChannel gets to set paidToBob however they like
-	// - the responder will go along with it
 	paidToBob, err := calculatePaidToBob(initialState, getChannel)
 	if err != nil {
 		return Objective{}, err
 	}
+
+	V, _ := getChannel(channelId) // calculatePaidToBob has checked that the channel exists
+	switch V.MyIndex {
+	case 0:
+		if largestPaymentAmount == nil || paidToBob.Cmp(largestPaymentAmount) > 0 {
+			return Objective{}, fmt.Errorf("%w: final state pays bob %s, but alice has paid %s", ErrInvalidPaidToBob, paidToBob, largestPaymentAmount)
+		}
+	case uint(len(V.Participants) - 1):
+		if largestPaymentAmount == nil || paidToBob.Cmp(largestPaymentAmount) < 0 {
+			return Objective{}, fmt.Errorf("%w: final state pays bob %s, but bob has been paid %s", ErrInvalidPaidToBob, paidToBob, largestPaymentAmount)
+		}
+	}
+
 	return NewObjective(
-		ObjectiveRequest{channelId, paidToBob},
+		ObjectiveRequest{channelId},
 		preapprove,
 		myAddress,
+		paidToBob,
 		getChannel,
 		getTwoPartyConsensusLedger)
 }
@@ -167,16 +192,46 @@ func calculatePaidToBob(proposedFinalState state.State, getChannel GetChannelByI
 	}
 	cId := proposedFinalState.ChannelId()
 	c, found := getChannel(cId)
-	pf := c.PreFundState()
-
 	if !found {
 		return big.NewInt(0), fmt.Errorf("could not find channel %s", cId)
 	}
+	pf := c.PreFundState()
+	if len(pf.Outcome) == 0 || len(pf.Outcome[0].Allocations) != 2 ||
+		len(proposedFinalState.Outcome) != len(pf.Outcome) || len(proposedFinalState.Outcome[0].Allocations) != 2 {
+		return big.NewInt(0), fmt.Errorf("%w: expected a final state with the same allocations as the initial outcome", ErrInvalidPaidToBob)
+	}
 	initialBobAmount := pf.Outcome[0].Allocations[1].Amount
 	finalBobAmount := proposedFinalState.Outcome[0].Allocations[1].Amount
+	if initialBobAmount == nil || finalBobAmount == nil {
+		return big.NewInt(0), fmt.Errorf("%w: missing amount for bob", ErrInvalidPaidToBob)
+	}
 	return big.NewInt(0).Sub(finalBobAmount, initialBobAmount), nil
 }
 
+// paidToBobIn returns the amount the proposed final state pays to bob, relative to the initial outcome of the virtual channel.
+func (o *Objective) paidToBobIn(proposedFinalState state.State) (*big.Int, error) {
+	if !proposedFinalState.IsFinal || len(proposedFinalState.Outcome) != len(o.InitialOutcome) || len(proposedFinalState.Outcome[0].Allocations) != 2 {
+		return nil, fmt.Errorf("%w: expected a final state with the same allocations as the initial outcome", ErrInvalidPaidToBob)
+	}
+	if err := proposedFinalState.Outcome.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPaidToBob, err)
+	}
+	initial := o.InitialOutcome[0]
+	paidToBob := big.NewInt(0).Sub(proposedFinalState.Outcome[0].Allocations[1].Amount, initial.Allocations[1].Amount)
+	if paidToBob.Sign() < 0 || paidToBob.Cmp(initial.Allocations[0].Amount) > 0 {
+		return nil, fmt.Errorf("%w: final state pays bob %s, but alice started with %s", ErrInvalidPaidToBob, paidToBob, initial.Allocations[0].Amount)
+	}
+	return paidToBob, nil
+}
+
+// cloneAmount returns a copy of the amount, or nil if it is nil.
+func cloneAmount(amount *big.Int) *big.Int {
+	if amount == nil {
+		return nil
+	}
+	return big.NewInt(0).Set(amount)
+}
+
 // IsVirtualDefundObjective inspects a objective id and returns true if the objective id is for a virtualdefund objective.
 func IsVirtualDefundObjective(id protocols.ObjectiveId) bool {
 	return strings.HasPrefix(string(id), ObjectivePrefix)
@@ -198,16 +253,17 @@ func (o *Objective) signedFinalState() (state.SignedState, error) {
 
 // finalState returns the final state for the virtual channel
 func (o *Objective) finalState() state.State {
-	vp := state.VariablePart{Outcome: outcome.Exit{o.finalOutcome()}, TurnNum: FinalTurnNum, IsFinal: true}
+	vp := state.VariablePart{Outcome: o.finalOutcome(), TurnNum: FinalTurnNum, IsFinal: true}
 	return state.StateFromFixedAndVariablePart(o.VFixed, vp)
 }
 
-// finalOutcome returns the outcome for the final state calculated from the InitialOutcome and PaidToBob
-func (o *Objective) finalOutcome() outcome.SingleAssetExit {
+// finalOutcome returns the outcome for the final state calculated from the InitialOutcome and PaidToBob.
+// Payments are made in the first asset, which is the only asset of a channel on which payments are made.
+func (o *Objective) finalOutcome() outcome.Exit {
 	finalOutcome := o.InitialOutcome.Clone()
 
-	finalOutcome.Allocations[0].Amount.Sub(finalOutcome.Allocations[0].Amount, o.PaidToBob)
-	finalOutcome.Allocations[1].Amount.Add(finalOutcome.Allocations[1].Amount, o.PaidToBob)
+	finalOutcome[0].Allocations[0].Amount.Sub(finalOutcome[0].Allocations[0].Amount, o.PaidToBob)
+	finalOutcome[0].Allocations[1].Amount.Add(finalOutcome[0].Allocations[1].Amount, o.PaidToBob)
 
 	return finalOutcome
 }
@@ -234,6 +290,13 @@ func (o *Objective) Reject() protocols.Objective {
 	return &updated
 }
 
+// Fail returns a failed copy of the objective.
+func (o *Objective) Fail() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Failed
+	return &updated
+}
+
 // OwnsChannel returns the channel that the objective is funding.
 func (o *Objective) OwnsChannel() types.Destination {
 	vId := o.VFixed.ChannelId()
@@ -266,9 +329,9 @@ func (o *Objective) clone() Objective {
 
 	clone.VFixed = o.VFixed.Clone()
 	clone.InitialOutcome = o.InitialOutcome.Clone()
-	clone.PaidToBob = big.NewInt(0).Set(o.PaidToBob)
+	clone.PaidToBob = cloneAmount(o.PaidToBob)
 
-	clone.Signatures = [3]state.Signature{}
+	clone.Signatures = make([]state.Signature, len(o.Signatures))
 	for i, s := range o.Signatures {
 		clone.Signatures[i] = state.CloneSignature(s)
 	}
@@ -295,6 +358,11 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
 	}
 
+	// An intermediary cannot sign the final state until it learns the amount paid to Bob
+	if updated.PaidToBob == nil {
+		return &updated, sideEffects, WaitingForFinalStateProposal, nil
+	}
+
 	// Signing of the final state
 	if !updated.signedByMe() {
 
@@ -345,6 +413,32 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 
 }
 
+// Resume returns side effects which resend the final state (if it has been signed by the calling client),
+// along with any ledger proposals or countersignatures the calling client has made to defund the virtual channel.
+func (o *Objective) Resume() protocols.SideEffects {
+	sideEffects := protocols.SideEffects{}
+	if o.PaidToBob == nil {
+		// Nothing has been signed yet
+		return sideEffects
+	}
+
+	if o.signedByMe() {
+		signedFinal, err := o.signedFinalState()
+		if err == nil {
+			messages := protocols.CreateSignedStateMessages(o.Id(), signedFinal, o.MyRole)
+			sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+		}
+	}
+
+	if !o.isAlice() {
+		sideEffects.Merge(o.resendLedgerUpdate(o.ToMyLeft))
+	}
+	if !o.isBob() {
+		sideEffects.Merge(o.resendLedgerUpdate(o.ToMyRight))
+	}
+	return sideEffects
+}
+
 // fullySigned returns whether we have a signature from every partciapant
 func (o *Objective) fullySigned() bool {
 	for _, sig := range o.Signatures {
@@ -360,14 +454,17 @@ func (o *Objective) isAlice() bool {
 	return o.MyRole == 0
 }
 
-// isBob returns true if the receiver represents participant 2 in the virtualdefund protocol
+// isBob returns true if the receiver represents the last participant in the virtualdefund protocol
 func (o *Objective) isBob() bool {
-	return o.MyRole == 2
+	return o.MyRole == uint(len(o.VFixed.Participants)-1)
 }
 
-// ledgerProposal generates a ledger proposal to remove the guarantee for V for ledger
+// ledgerProposal generates a ledger proposal to remove the guarantee for V for ledger, crediting Alice's final amount of each asset to the left of the guarantee
 func (o *Objective) ledgerProposal(ledger *consensus_channel.ConsensusChannel) consensus_channel.Proposal {
-	left := o.finalOutcome().Allocations[0].Amount
+	left := types.Funds{}
+	for _, sae := range o.finalOutcome() {
+		left[sae.Asset] = sae.Allocations[0].Amount
+	}
 
 	return consensus_channel.NewRemoveProposal(ledger.Id, o.VId(), left)
 }
@@ -416,6 +513,30 @@ func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.Cons
 	return sideEffects, nil
 }
 
+// resendLedgerUpdate returns side effects which resend the calling client's contribution to removing the guarantee for V:
+//  - the leader resends its outstanding proposals,
+//  - the follower resends its countersignature, once the guarantee has been removed from the ledger.
+func (o *Objective) resendLedgerUpdate(ledger *consensus_channel.ConsensusChannel) protocols.SideEffects {
+	sideEffects := protocols.SideEffects{}
+
+	if ledger.IsLeader() {
+		if proposals := ledger.ProposalQueue(); len(proposals) != 0 {
+			message := protocols.CreateSignedProposalMessage(ledger.Follower(), proposals...)
+			sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, message)
+		}
+	} else if !ledger.IncludesTarget(o.VId()) {
+		sp := consensus_channel.SignedProposal{
+			Signature: ledger.Signatures()[consensus_channel.Follower],
+			Proposal:  o.ledgerProposal(ledger),
+			TurnNum:   ledger.ConsensusTurnNum(),
+		}
+		message := protocols.CreateSignedProposalMessage(ledger.Leader(), sp)
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, message)
+	}
+
+	return sideEffects
+}
+
 // VId returns the channel id of the virtual channel.
 func (o *Objective) VId() types.Destination {
 	vId := o.VFixed.ChannelId() // TODO Deal with error
@@ -459,7 +580,7 @@ func (o *Objective) isLeftDefunded() bool {
 // validateSignature returns whether the given signature is valid for the given participant
 // If a signature is invalid an error will be returned containing the reason
 func (o *Objective) validateSignature(sig state.Signature, participantIndex uint) (bool, error) {
-	if participantIndex > 2 {
+	if participantIndex >= uint(len(o.VFixed.Participants)) {
 		return false, fmt.Errorf("participant index %d is out of bounds", participantIndex)
 	}
 
@@ -490,8 +611,15 @@ func (o *Objective) Update(event protocols.ObjectiveEvent) (protocols.Objective,
 		if incomingChannelId != vChannelId {
 			return o, errors.New("event channelId out of scope of objective")
 		} else {
+			if updated.PaidToBob == nil {
+				paidToBob, err := updated.paidToBobIn(ss.State())
+				if err != nil {
+					return o, err
+				}
+				updated.PaidToBob = paidToBob
+			}
 			incomingSignatures := ss.Signatures()
-			for i := uint(0); i < 3; i++ {
+			for i := range o.Signatures {
 				existingSig := o.Signatures[i]
 				incomingSig := incomingSignatures[i]
 
@@ -508,7 +636,7 @@ func (o *Objective) Update(event protocols.ObjectiveEvent) (protocols.Objective,
 					}
 				}
 				// Otherwise we validate the incoming signature and update our signatures
-				isValid, err := updated.validateSignature(incomingSig, i)
+				isValid, err := updated.validateSignature(incomingSig, uint(i))
 				if isValid {
 					// Update the signature
 					updated.Signatures[i] = incomingSig
@@ -559,10 +687,9 @@ func isZero(sig state.Signature) bool {
 	return sig.Equal(zeroSig)
 }
 
-// ObjectiveRequest represents a request to create a new direct defund objective.
+// ObjectiveRequest represents a request to create a new virtual defund objective.
 type ObjectiveRequest struct {
 	ChannelId types.Destination
-	PaidToBob *big.Int
 }
 
 // Id returns the objective id for the request.
@@ -1,6 +1,7 @@
 package virtualdefund
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"testing"
@@ -37,12 +38,11 @@ func TestInvalidUpdate(t *testing.T) {
 	vId := data.vFinal.ChannelId()
 	request := ObjectiveRequest{
 		ChannelId: vId,
-		PaidToBob: big.NewInt(int64(data.paid)),
 	}
 
 	getChannel, getConsensusChannel := generateStoreGetters(0, vId, data.vFinal)
 
-	virtualDefund, err := NewObjective(request, false, alice.Address(), getChannel, getConsensusChannel)
+	virtualDefund, err := NewObjective(request, false, alice.Address(), big.NewInt(int64(data.paid)), getChannel, getConsensusChannel)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -68,12 +68,11 @@ func testUpdateAs(my ta.Actor) func(t *testing.T) {
 		vId := data.vFinal.ChannelId()
 		request := ObjectiveRequest{
 			ChannelId: vId,
-			PaidToBob: big.NewInt(int64(data.paid)),
 		}
 
 		getChannel, getConsensusChannel := generateStoreGetters(my.Role, vId, data.vInitial)
 
-		virtualDefund, err := NewObjective(request, false, my.Address(), getChannel, getConsensusChannel)
+		virtualDefund, err := NewObjective(request, false, my.Address(), big.NewInt(int64(data.paid)), getChannel, getConsensusChannel)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -103,11 +102,10 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 		vId := data.vFinal.ChannelId()
 		request := ObjectiveRequest{
 			ChannelId: vId,
-			PaidToBob: big.NewInt(int64(data.paid)),
 		}
 
 		getChannel, getConsensusChannel := generateStoreGetters(my.Role, vId, data.vInitial)
-		virtualDefund, err := NewObjective(request, true, my.Address(), getChannel, getConsensusChannel)
+		virtualDefund, err := NewObjective(request, true, my.Address(), big.NewInt(int64(data.paid)), getChannel, getConsensusChannel)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -163,23 +161,58 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 
 }
 
+func TestIntermediaryWaitsForFinalState(t *testing.T) {
+	data := generateTestData()
+	vId := data.vFinal.ChannelId()
+	getChannel, getConsensusChannel := generateStoreGetters(irene.Role, vId, data.vInitial)
+
+	if _, err := NewObjective(ObjectiveRequest{vId}, true, alice.Address(), nil, getChannel, getConsensusChannel); err == nil {
+		t.Fatalf("expected alice to be required to supply the amount paid to bob")
+	}
+
+	virtualDefund, err := NewObjective(ObjectiveRequest{vId}, true, irene.Address(), nil, getChannel, getConsensusChannel)
+	testhelpers.Ok(t, err)
+
+	// Irene does not know what has been paid, so cannot sign the final state
+	_, se, waitingFor, err := virtualDefund.Crank(&irene.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForFinalStateProposal, waitingFor)
+	testhelpers.Equals(t, 0, len(se.MessagesToSend))
+
+	// Irene learns what has been paid from the final state bob proposes
+	signedByBob := state.NewSignedState(data.vFinal)
+	testhelpers.SignState(&signedByBob, &bob.PrivateKey)
+	updatedObj, err := virtualDefund.Update(protocols.ObjectiveEvent{ObjectiveId: virtualDefund.Id(), SignedState: signedByBob})
+	testhelpers.Ok(t, err)
+	updated := updatedObj.(*Objective)
+	testhelpers.Equals(t, big.NewInt(int64(data.paid)), updated.PaidToBob)
+
+	_, se, waitingFor, err = updated.Crank(&irene.PrivateKey)
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteFinal, waitingFor)
+	signedByIrene := state.NewSignedState(data.vFinal)
+	testhelpers.SignState(&signedByIrene, &irene.PrivateKey)
+	testhelpers.SignState(&signedByIrene, &bob.PrivateKey)
+	testhelpers.AssertStateSentToEveryone(t, se, signedByIrene, irene, allActors)
+}
+
 func TestConstructObjectiveFromState(t *testing.T) {
 	data := generateTestData()
 	vId := data.vFinal.ChannelId()
 
 	getChannel, getConsensusChannel := generateStoreGetters(alice.Role, vId, data.vInitial)
 
-	got, err := ConstructObjectiveFromState(data.vFinal, true, alice.Address(), getChannel, getConsensusChannel)
+	got, err := ConstructObjectiveFromState(data.vFinal, true, alice.Address(), getChannel, getConsensusChannel, big.NewInt(int64(data.paid)))
 	if err != nil {
 		t.Fatal(err)
 	}
 	left, right := generateLedgers(alice.Role, vId)
 	want := Objective{
 		Status:         protocols.Approved,
-		InitialOutcome: data.vInitial.Outcome[0],
+		InitialOutcome: data.vInitial.Outcome,
 		PaidToBob:      big.NewInt(int64(data.paid)),
 		VFixed:         data.vFinal.FixedPart(),
-		Signatures:     [3]state.Signature{},
+		Signatures:     make([]state.Signature, 3),
 		ToMyLeft:       left,
 		ToMyRight:      right,
 	}
@@ -188,17 +221,73 @@ func TestConstructObjectiveFromState(t *testing.T) {
 	}
 }
 
+func TestConstructObjectiveFromStateChecksPayments(t *testing.T) {
+	data := generateTestData()
+	vId := data.vFinal.ChannelId()
+	paid := big.NewInt(int64(data.paid))
+
+	testCases := []struct {
+		my                   ta.Actor
+		largestPaymentAmount *big.Int
+		valid                bool
+	}{
+		{alice, paid, true},
+		{alice, big.NewInt(0).Add(paid, big.NewInt(1)), true},
+		{alice, big.NewInt(0).Sub(paid, big.NewInt(1)), false},
+		{alice, nil, false},
+		{bob, paid, true},
+		{bob, big.NewInt(0).Sub(paid, big.NewInt(1)), true},
+		{bob, big.NewInt(0).Add(paid, big.NewInt(1)), false},
+		{bob, nil, false},
+		{irene, nil, true},
+	}
+
+	for _, tc := range testCases {
+		getChannel, getConsensusChannel := generateStoreGetters(tc.my.Role, vId, data.vInitial)
+
+		_, err := ConstructObjectiveFromState(data.vFinal, true, tc.my.Address(), getChannel, getConsensusChannel, tc.largestPaymentAmount)
+		if tc.valid && err != nil {
+			t.Errorf("%s with payments of %v: expected no error, got %v", tc.my.Name, tc.largestPaymentAmount, err)
+		}
+		if !tc.valid && !errors.Is(err, ErrInvalidPaidToBob) {
+			t.Errorf("%s with payments of %v: expected %v, got %v", tc.my.Name, tc.largestPaymentAmount, ErrInvalidPaidToBob, err)
+		}
+	}
+}
+
+func TestMalformedFinalStatesAreRejected(t *testing.T) {
+	data := generateTestData()
+	vId := data.vFinal.ChannelId()
+	getChannel, getConsensusChannel := generateStoreGetters(irene.Role, vId, data.vInitial)
+
+	missingAmount := data.vFinal.Clone()
+	missingAmount.Outcome[0].Allocations[1].Amount = nil
+	missingAllocation := data.vFinal.Clone()
+	missingAllocation.Outcome[0].Allocations = missingAllocation.Outcome[0].Allocations[:1]
+
+	for _, s := range []state.State{missingAmount, missingAllocation} {
+		if _, err := ConstructObjectiveFromState(s, true, irene.Address(), getChannel, getConsensusChannel, nil); !errors.Is(err, ErrInvalidPaidToBob) {
+			t.Errorf("expected %v constructing an objective from %+v, but got %v", ErrInvalidPaidToBob, s.Outcome, err)
+		}
+
+		virtualDefund, err := NewObjective(ObjectiveRequest{vId}, true, irene.Address(), nil, getChannel, getConsensusChannel)
+		testhelpers.Ok(t, err)
+		if _, err := virtualDefund.Update(protocols.ObjectiveEvent{ObjectiveId: virtualDefund.Id(), SignedState: state.NewSignedState(s)}); !errors.Is(err, ErrInvalidPaidToBob) {
+			t.Errorf("expected %v updating with %+v, but got %v", ErrInvalidPaidToBob, s.Outcome, err)
+		}
+	}
+}
+
 func TestApproveReject(t *testing.T) {
 	data := generateTestData()
 	vId := data.vFinal.ChannelId()
 	request := ObjectiveRequest{
 		ChannelId: vId,
-		PaidToBob: big.NewInt(int64(data.paid)),
 	}
 
 	getChannel, getConsensusChannel := generateStoreGetters(0, vId, data.vInitial)
 
-	virtualDefund, err := NewObjective(request, false, alice.Address(), getChannel, getConsensusChannel)
+	virtualDefund, err := NewObjective(request, false, alice.Address(), big.NewInt(int64(data.paid)), getChannel, getConsensusChannel)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -31,10 +31,10 @@ func prepareConsensusChannelHelper(role uint, leader, follower testactors.Actor,
 		ChallengeDuration: big.NewInt(45),
 	}
 
-	leaderBal := consensus_channel.NewBalance(leader.Destination(), big.NewInt(int64(leftBalance)))
-	followerBal := consensus_channel.NewBalance(follower.Destination(), big.NewInt(int64(rightBalance)))
+	leaderBal := consensus_channel.NewBalance(leader.Destination(), types.Funds{types.Address{}: big.NewInt(int64(leftBalance))})
+	followerBal := consensus_channel.NewBalance(follower.Destination(), types.Funds{types.Address{}: big.NewInt(int64(rightBalance))})
 
-	lo := *consensus_channel.NewLedgerOutcome(types.Address{}, leaderBal, followerBal, guarantees)
+	lo := *consensus_channel.NewLedgerOutcome([]types.Address{{}}, leaderBal, followerBal, guarantees)
 
 	signedVars := consensus_channel.SignedVars{Vars: consensus_channel.Vars{Outcome: lo, TurnNum: uint64(turnNum)}}
 	leaderSig, err := signedVars.Vars.AsState(fp).Sign(leader.PrivateKey)
@@ -85,25 +85,40 @@ func (c *Connection) handleProposal(sp consensus_channel.SignedProposal) error {
 	return nil
 }
 
+// resendLedgerUpdate returns side effects which resend the calling client's contribution to funding the expected guarantee:
+//  - the leader resends its outstanding proposals,
+//  - the follower resends its countersignature, once the guarantee is included in the ledger.
+func (c *Connection) resendLedgerUpdate() protocols.SideEffects {
+	sideEffects := protocols.SideEffects{}
+	ledger := c.Channel
+
+	if ledger.IsLeader() {
+		if proposals := ledger.ProposalQueue(); len(proposals) != 0 {
+			message := protocols.CreateSignedProposalMessage(ledger.Follower(), proposals...)
+			sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, message)
+		}
+	} else if c.Funded() {
+		sp := consensus_channel.SignedProposal{
+			Signature: ledger.Signatures()[consensus_channel.Follower],
+			Proposal:  c.expectedProposal(),
+			TurnNum:   ledger.ConsensusTurnNum(),
+		}
+		message := protocols.CreateSignedProposalMessage(ledger.Leader(), sp)
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, message)
+	}
+
+	return sideEffects
+}
+
 // Funded computes whether the ledger channel on the receiver funds the guarantee expected by this connection
 func (c *Connection) Funded() bool {
 	g := c.getExpectedGuarantee()
 	return c.Channel.Includes(g)
 }
 
-// getExpectedGuarantee returns a map of asset addresses to guarantees for a Connection.
+// getExpectedGuarantee returns the guarantee, covering every asset in V, expected for a Connection.
 func (c *Connection) getExpectedGuarantee() consensus_channel.Guarantee {
-	amountFunds := c.GuaranteeInfo.LeftAmount.Add(c.GuaranteeInfo.RightAmount)
-
-	//HACK: GuaranteeInfo stores amounts as types.Funds.
-	// We only expect a single asset type, and we want to know how much is to be
-	// diverted for that asset type.
-	// So, we loop through amountFunds and break after the first asset type ...
-	var amount *big.Int
-	for _, val := range amountFunds {
-		amount = val
-		break
-	}
+	amount := c.GuaranteeInfo.LeftAmount.Add(c.GuaranteeInfo.RightAmount)
 
 	target := c.GuaranteeInfo.GuaranteeDestination
 	left := c.GuaranteeInfo.Left
@@ -115,7 +130,7 @@ func (c *Connection) getExpectedGuarantee() consensus_channel.Guarantee {
 // Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data.
 type Objective struct {
 	Status protocols.ObjectiveStatus
-	V      *channel.SingleHopVirtualChannel
+	V      *channel.VirtualChannel
 
 	ToMyLeft  *Connection
 	ToMyRight *Connection
@@ -130,17 +145,20 @@ type Objective struct {
 
 // NewObjective creates a new virtual funding objective from a given request.
 func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Address, getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction) (Objective, error) {
-	rightCC, ok := getTwoPartyConsensusLedger(request.Intermediary)
+	if len(request.Intermediaries) == 0 {
+		return Objective{}, errors.New("a virtual channel must have at least one intermediary")
+	}
+	rightCC, ok := getTwoPartyConsensusLedger(request.Intermediaries[0])
 
 	if !ok {
-		return Objective{}, fmt.Errorf("could not find ledger for %s and %s", myAddress, request.Intermediary)
+		return Objective{}, fmt.Errorf("could not find ledger for %s and %s", myAddress, request.Intermediaries[0])
 	}
 	var leftCC *consensus_channel.ConsensusChannel
 
 	objective, err := constructFromState(preApprove,
 		state.State{
-			ChainId:           big.NewInt(9001), // TODO https://github.com/statechannels/go-nitro/issues/601
-			Participants:      []types.Address{myAddress, request.Intermediary, request.CounterParty},
+			ChainId:           request.ChainId,
+			Participants:      request.participants(myAddress),
 			ChannelNonce:      big.NewInt(request.Nonce),
 			ChallengeDuration: request.ChallengeDuration,
 			AppData:           request.AppData,
@@ -188,14 +206,14 @@ func constructFromState(
 	}
 
 	// Initialize virtual channel
-	v, err := channel.NewSingleHopVirtualChannel(initialStateOfV, init.MyRole)
+	v, err := channel.NewVirtualChannel(initialStateOfV, init.MyRole)
 	if err != nil {
 		return Objective{}, err
 	}
 
 	init.V = v
 
-	init.n = uint(len(initialStateOfV.Participants)) - 2 // NewSingleHopVirtualChannel will error unless there are at least 3 participants
+	init.n = uint(len(initialStateOfV.Participants)) - 2 // NewVirtualChannel will error unless there are at least 3 participants
 
 	init.a0 = make(map[types.Address]*big.Int)
 	init.b0 = make(map[types.Address]*big.Int)
@@ -203,6 +221,9 @@ func constructFromState(
 	// Compute a0 and b0 from the initial state of J
 	for i := range initialStateOfV.Outcome {
 		asset := initialStateOfV.Outcome[i].Asset
+		if len(initialStateOfV.Outcome[i].Allocations) != 2 {
+			return Objective{}, fmt.Errorf("expected 2 allocations for asset %s, got %d", asset, len(initialStateOfV.Outcome[i].Allocations))
+		}
 		if initialStateOfV.Outcome[i].Allocations[0].Destination != types.AddressToDestination(initialStateOfV.Participants[0]) {
 			return Objective{}, errors.New("allocation in slot 0 does not correspond to participant 0")
 		}
@@ -285,6 +306,13 @@ func (o *Objective) Reject() protocols.Objective {
 	return &updated
 }
 
+// Fail returns a failed copy of the objective.
+func (o *Objective) Fail() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Failed
+	return &updated
+}
+
 // OwnsChannel returns the channel that the objective is funding.
 func (o *Objective) OwnsChannel() types.Destination {
 	return o.V.Id
@@ -418,6 +446,24 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	return &updated, sideEffects, WaitingForNothing, nil
 }
 
+// Resume returns side effects which resend every virtual channel state signed by the calling client,
+// along with any ledger proposals or countersignatures the calling client has made to fund the virtual channel.
+func (o *Objective) Resume() protocols.SideEffects {
+	sideEffects := protocols.SideEffects{}
+	for _, ss := range o.V.SignedStatesSignedByMe() {
+		messages := protocols.CreateSignedStateMessages(o.Id(), ss, o.V.MyIndex)
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	if !o.isAlice() {
+		sideEffects.Merge(o.ToMyLeft.resendLedgerUpdate())
+	}
+	if !o.isBob() {
+		sideEffects.Merge(o.ToMyRight.resendLedgerUpdate())
+	}
+	return sideEffects
+}
+
 func (o *Objective) Related() []protocols.Storable {
 	ret := []protocols.Storable{&o.V.Channel}
 
@@ -508,37 +554,39 @@ func ConstructObjectiveFromState(
 
 	participants := initialState.Participants
 
-	// This logic assumes a single hop virtual channel.
-	// Currently this is the only type of virtual channel supported.
-	alice := participants[0]
-	intermediary := participants[1]
-	bob := participants[2]
+	myIndex := -1
+	for i, p := range participants {
+		if p == myAddress {
+			myIndex = i
+			break
+		}
+	}
 
 	var leftC *consensus_channel.ConsensusChannel
 	var rightC *consensus_channel.ConsensusChannel
 	var ok bool
 
-	if myAddress == alice {
+	switch {
+	case myIndex == -1:
+		return Objective{}, fmt.Errorf("client address not found in an expected participant index")
+	case myIndex == 0:
 		return Objective{}, errors.New("participant[0] should not construct objectives from peer messages")
-	} else if myAddress == bob {
-		leftC, ok = getTwoPartyConsensusLedger(intermediary)
-		if !ok {
-			return Objective{}, fmt.Errorf("could not find a left ledger channel between %v and %v", intermediary, bob)
-		}
-
-	} else if myAddress == intermediary {
-		leftC, ok = getTwoPartyConsensusLedger(alice)
+	default:
+		// Everyone other than Alice has a ledger channel with the participant to their left
+		left := participants[myIndex-1]
+		leftC, ok = getTwoPartyConsensusLedger(left)
 		if !ok {
-			return Objective{}, fmt.Errorf("could not find a left ledger channel between %v and %v", alice, intermediary)
+			return Objective{}, fmt.Errorf("could not find a left ledger channel between %v and %v", left, myAddress)
 		}
 
-		rightC, ok = getTwoPartyConsensusLedger(bob)
-		if !ok {
-			return Objective{}, fmt.Errorf("could not find a right ledger channel between %v and %v", intermediary, bob)
+		// Everyone other than Bob has a ledger channel with the participant to their right
+		if myIndex < len(participants)-1 {
+			right := participants[myIndex+1]
+			rightC, ok = getTwoPartyConsensusLedger(right)
+			if !ok {
+				return Objective{}, fmt.Errorf("could not find a right ledger channel between %v and %v", myAddress, right)
+			}
 		}
-
-	} else {
-		return Objective{}, fmt.Errorf("client address not found in an expected participant index")
 	}
 
 	return constructFromState(
@@ -557,13 +605,7 @@ func IsVirtualFundObjective(id protocols.ObjectiveId) bool {
 
 func (c *Connection) expectedProposal() consensus_channel.Proposal {
 	g := c.getExpectedGuarantee()
-
-	var leftAmount *big.Int
-	for _, val := range c.GuaranteeInfo.LeftAmount {
-		leftAmount = val
-		break
-	}
-	proposal := consensus_channel.NewAddProposal(c.Channel.Id, g, leftAmount)
+	proposal := consensus_channel.NewAddProposal(c.Channel.Id, g, c.GuaranteeInfo.LeftAmount.Clone())
 
 	return proposal
 }
@@ -656,25 +698,41 @@ func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, sk *[
 }
 
 // ObjectiveRequest represents a request to create a new virtual funding objective.
+// The virtual channel is funded through the Intermediaries, in order: the caller must have a ledger channel with the first intermediary,
+// each intermediary must have a ledger channel with the next, and the last intermediary must have a ledger channel with the CounterParty.
+// ChainId is the id of the chain on which the channel's ledger channels are funded.
 type ObjectiveRequest struct {
-	Intermediary      types.Address
+	Intermediaries    []types.Address
 	CounterParty      types.Address
 	AppDefinition     types.Address
 	AppData           types.Bytes
 	ChallengeDuration *types.Uint256
 	Outcome           outcome.Exit
 	Nonce             int64
+	ChainId           *big.Int
 }
 
 // Id returns the objective id for the request.
 func (r ObjectiveRequest) Id(myAddress types.Address) protocols.ObjectiveId {
-	fixedPart := state.FixedPart{ChainId: big.NewInt(9001), // TODO https://github.com/statechannels/go-nitro/issues/601
-		Participants:      []types.Address{myAddress, r.Intermediary, r.CounterParty},
+	return protocols.ObjectiveId(ObjectivePrefix + r.channelId(myAddress).String())
+}
+
+// channelId returns the id of the virtual channel requested by the client with the supplied address.
+func (r ObjectiveRequest) channelId(myAddress types.Address) types.Destination {
+	fixedPart := state.FixedPart{
+		ChainId:           r.ChainId,
+		Participants:      r.participants(myAddress),
 		ChannelNonce:      big.NewInt(r.Nonce),
-		ChallengeDuration: r.ChallengeDuration}
+		ChallengeDuration: r.ChallengeDuration,
+	}
+	return fixedPart.ChannelId()
+}
 
-	channelId := fixedPart.ChannelId()
-	return protocols.ObjectiveId(ObjectivePrefix + channelId.String())
+// participants returns the participants of the virtual channel requested by the client with the supplied address.
+func (r ObjectiveRequest) participants(myAddress types.Address) []types.Address {
+	participants := []types.Address{myAddress}
+	participants = append(participants, r.Intermediaries...)
+	return append(participants, r.CounterParty)
 }
 
 // ObjectiveResponse is the type returned across the API in response to the ObjectiveRequest.
@@ -685,15 +743,8 @@ type ObjectiveResponse struct {
 
 // Response computes and returns the appropriate response from the request.
 func (r ObjectiveRequest) Response(myAddress types.Address) ObjectiveResponse {
-	fixedPart := state.FixedPart{ChainId: big.NewInt(9001), // TODO add this field to the request and pull it from there. https://github.com/statechannels/go-nitro/issues/601
-		Participants:      []types.Address{myAddress, r.Intermediary, r.CounterParty},
-		ChannelNonce:      big.NewInt(r.Nonce),
-		ChallengeDuration: r.ChallengeDuration}
-
-	channelId := fixedPart.ChannelId()
-
 	return ObjectiveResponse{
-		Id:        protocols.ObjectiveId(ObjectivePrefix + channelId.String()),
-		ChannelId: channelId,
+		Id:        r.Id(myAddress),
+		ChannelId: r.channelId(myAddress),
 	}
 }
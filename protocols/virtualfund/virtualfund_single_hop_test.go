@@ -133,7 +133,7 @@ func diffFromCorrectConnection(c *Connection, left, right ta.Actor) string {
 	// I am not sure how these types are meant to be used, and am
 	// comparing the _guarantees_ that we expect to include, instead of the GuaranteeInfo
 
-	expectedAmount := big.NewInt(0).Set(vPreFund.VariablePart().Outcome[0].TotalAllocated())
+	expectedAmount := types.Funds{vPreFund.VariablePart().Outcome[0].Asset: vPreFund.VariablePart().Outcome[0].TotalAllocated()}
 	want := consensus_channel.NewGuarantee(expectedAmount, Id, left.Destination(), right.Destination())
 	got := c.getExpectedGuarantee()
 
@@ -171,7 +171,7 @@ func TestClone(t *testing.T) {
 	}
 }
 
-func cloneAndSignSetupStateByPeers(v channel.SingleHopVirtualChannel, myRole uint, prefund bool) *channel.SingleHopVirtualChannel {
+func cloneAndSignSetupStateByPeers(v channel.VirtualChannel, myRole uint, prefund bool) *channel.VirtualChannel {
 	withSigs := v.Clone()
 
 	var state state.State
@@ -259,7 +259,7 @@ func TestCrankAsAlice(t *testing.T) {
 	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
 	o = oObj.(*Objective)
 
-	p := consensus_channel.NewAddProposal(o.ToMyRight.Channel.Id, o.ToMyRight.getExpectedGuarantee(), big.NewInt(6))
+	p := consensus_channel.NewAddProposal(o.ToMyRight.Channel.Id, o.ToMyRight.getExpectedGuarantee(), types.Funds{types.Address{}: big.NewInt(6)})
 	sp := consensus_channel.SignedProposal{Proposal: p, Signature: consensusStateSignatures(alice, p1, o.ToMyRight.getExpectedGuarantee())[0], TurnNum: 2}
 	Ok(t, err)
 	assertOneProposalSent(t, effects, sp, p1)
@@ -354,7 +354,7 @@ func TestCrankAsBob(t *testing.T) {
 	Equals(t, waitingFor, WaitingForCompleteFunding)
 
 	// If Bob had received a signed counterproposal, he should proceed to postFundSetup
-	p := consensus_channel.NewAddProposal(o.ToMyLeft.Channel.Id, o.ToMyLeft.getExpectedGuarantee(), big.NewInt(6))
+	p := consensus_channel.NewAddProposal(o.ToMyLeft.Channel.Id, o.ToMyLeft.getExpectedGuarantee(), types.Funds{types.Address{}: big.NewInt(6)})
 	sp := consensus_channel.SignedProposal{Proposal: p, Signature: consensusStateSignatures(p1, bob, o.ToMyLeft.getExpectedGuarantee())[0], TurnNum: 2}
 	e = protocols.ObjectiveEvent{ObjectiveId: o.Id(), SignedProposal: sp}
 
@@ -424,7 +424,7 @@ func TestCrankAsP1(t *testing.T) {
 	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
 	o = oObj.(*Objective)
 
-	p := consensus_channel.NewAddProposal(o.ToMyLeft.Channel.Id, o.ToMyLeft.getExpectedGuarantee(), big.NewInt(6))
+	p := consensus_channel.NewAddProposal(o.ToMyLeft.Channel.Id, o.ToMyLeft.getExpectedGuarantee(), types.Funds{types.Address{}: big.NewInt(6)})
 	sp := consensus_channel.SignedProposal{Proposal: p, Signature: consensusStateSignatures(p1, alice, o.ToMyLeft.getExpectedGuarantee())[0], TurnNum: 2}
 	Ok(t, err)
 	assertOneProposalSent(t, effects, sp, alice)
@@ -439,7 +439,7 @@ func TestCrankAsP1(t *testing.T) {
 	Equals(t, waitingFor, WaitingForCompleteFunding)
 
 	// If P1 had received a signed counterproposal, she should proceed to postFundSetup
-	p = consensus_channel.NewAddProposal(o.ToMyLeft.Channel.Id, o.ToMyLeft.getExpectedGuarantee(), big.NewInt(6))
+	p = consensus_channel.NewAddProposal(o.ToMyLeft.Channel.Id, o.ToMyLeft.getExpectedGuarantee(), types.Funds{types.Address{}: big.NewInt(6)})
 	sp = consensus_channel.SignedProposal{Proposal: p, Signature: consensusStateSignatures(p1, alice, o.ToMyLeft.getExpectedGuarantee())[1], TurnNum: 2}
 	e = protocols.ObjectiveEvent{ObjectiveId: o.Id(), SignedProposal: sp}
 
@@ -0,0 +1,40 @@
+package watchtower
+
+import (
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/client/engine/store/safesync"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Store holds the latest supported state of each channel guarded by a Watchtower.
+type Store interface {
+	GetState(channelId types.Destination) (ss state.SignedState, ok bool) // Get the latest supported state held for the channel
+	SetState(ss state.SignedState)                                        // Write the latest supported state for the state's channel
+	GetStates() []state.SignedState                                       // Get the latest supported state held for every channel
+}
+
+// MemStore is an in-memory Store.
+type MemStore struct {
+	states safesync.Map[state.SignedState]
+}
+
+func NewMemStore() *MemStore {
+	return &MemStore{}
+}
+
+func (ms *MemStore) GetState(channelId types.Destination) (state.SignedState, bool) {
+	return ms.states.Load(channelId.String())
+}
+
+func (ms *MemStore) SetState(ss state.SignedState) {
+	ms.states.Store(ss.ChannelId().String(), ss.Clone())
+}
+
+func (ms *MemStore) GetStates() []state.SignedState {
+	states := []state.SignedState{}
+	ms.states.Range(func(_ string, ss state.SignedState) bool {
+		states = append(states, ss.Clone())
+		return true
+	})
+	return states
+}
@@ -0,0 +1,162 @@
+// Package watchtower contains a service which guards channels on behalf of clients which may be offline.
+//
+// Clients hand the watchtower the supported states of their channels in messages (see protocols.CreateWatchMessage).
+// Whenever a challenge is registered against one of those channels with an older state, the watchtower clears it by checkpointing the latest state it holds.
+package watchtower // import "github.com/statechannels/go-nitro/watchtower"
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/client/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrUnsupportedState is returned when a watchtower is handed a state which is not signed by every participant in the channel.
+var ErrUnsupportedState = errors.New("state is not signed by every participant")
+
+// Watchtower watches the adjudicator for challenges on the channels it has been asked to guard.
+type Watchtower struct {
+	address types.Address // the address with which the watchtower subscribes to chain events
+
+	fromMsg   <-chan protocols.Message
+	fromChain <-chan chainservice.Event
+
+	chain chainservice.ChainService
+	store Store
+
+	errors chan error
+	logger *log.Logger
+}
+
+// New is the constructor for a Watchtower. The watchtower subscribes to chain events with the supplied address,
+// receives messages from the supplied message service, and submits transactions with the supplied chain service.
+//
+// The watchtower resumes guarding the channels whose states are held in the supplied store, and clears any stale challenges
+// registered against them while it was not running.
+func New(address types.Address, msg messageservice.MessageService, chain chainservice.ChainService, store Store, logDestination io.Writer) Watchtower {
+	w := Watchtower{}
+	w.address = address
+	w.fromMsg = msg.Out()
+	w.fromChain = chain.SubscribeToEvents(address)
+	w.chain = chain
+	w.store = store
+	w.errors = make(chan error, 100)
+
+	logPrefix := address.String()[0:8] + ": "
+	w.logger = log.New(logDestination, logPrefix, log.Lmicroseconds|log.Lshortfile)
+
+	w.resume()
+
+	go w.run()
+
+	return w
+}
+
+// Errors returns a chan that receives errors encountered by the watchtower, such as invalid states handed to it or failures to checkpoint.
+// Errors are dropped if the chan is full.
+func (w *Watchtower) Errors() <-chan error {
+	return w.errors
+}
+
+// resume monitors the channels held in the store, and clears any challenge registered against them with an older state.
+// Channels are monitored before their adjudication status is read, so that no challenge is missed in between.
+func (w *Watchtower) resume() {
+	held := w.store.GetStates()
+	channelIds := make([]types.Destination, len(held))
+	for i, ss := range held {
+		channelIds[i] = ss.ChannelId()
+	}
+	w.chain.Monitor(w.address, channelIds...)
+
+	for _, channelId := range channelIds {
+		status, err := w.chain.GetAdjudicationStatus(channelId)
+		if err != nil {
+			w.reportError(fmt.Errorf("could not read the adjudication status of channel %s: %w", channelId, err))
+			continue
+		}
+		if status.FinalizesAt != 0 {
+			w.reportError(w.checkpoint(channelId, status.TurnNumRecord))
+		}
+	}
+}
+
+// run is an infinite loop that handles messages and chain events.
+func (w *Watchtower) run() {
+	for {
+		select {
+		case message := <-w.fromMsg:
+			for _, ss := range message.WatchedStates() {
+				w.reportError(w.guard(ss))
+			}
+		case chainEvent := <-w.fromChain:
+			switch e := chainEvent.(type) {
+			case chainservice.ChallengeRegisteredEvent:
+				w.reportError(w.respondToChallenge(e))
+			case chainservice.TransactionFailedEvent:
+				w.reportError(fmt.Errorf("checkpoint transaction %s for channel %s failed: %w", e.TxHash, e.ChannelID(), e.Err))
+			}
+		}
+	}
+}
+
+// guard stores the supplied state, if it is supported and newer than any state held for the channel.
+func (w *Watchtower) guard(ss state.SignedState) error {
+	if err := validateSupported(ss); err != nil {
+		return fmt.Errorf("could not guard channel %s: %w", ss.ChannelId(), err)
+	}
+	if held, ok := w.store.GetState(ss.ChannelId()); ok && held.State().TurnNum >= ss.State().TurnNum {
+		return nil
+	}
+	w.logger.Printf("Guarding channel %s with turn number %d", ss.ChannelId(), ss.State().TurnNum)
+	w.store.SetState(ss)
+	w.chain.Monitor(w.address, ss.ChannelId())
+	return nil
+}
+
+// respondToChallenge checkpoints the latest state held for the challenged channel, if it is newer than the challenged state.
+func (w *Watchtower) respondToChallenge(event chainservice.ChallengeRegisteredEvent) error {
+	return w.checkpoint(event.ChannelID(), event.TurnNumRecord)
+}
+
+// checkpoint checkpoints the latest state held for the channel, if it is newer than the challenged state with the given turn number.
+func (w *Watchtower) checkpoint(channelId types.Destination, challengedTurnNum uint64) error {
+	held, ok := w.store.GetState(channelId)
+	if !ok || held.State().TurnNum <= challengedTurnNum {
+		return nil
+	}
+	w.logger.Printf("Checkpointing channel %s with turn number %d, to clear a challenge with turn number %d", channelId, held.State().TurnNum, challengedTurnNum)
+	return w.chain.SendTransaction(protocols.NewCheckpointTransaction(channelId, held))
+}
+
+// reportError sends a non-nil error to the Errors chan, or drops it if the chan is full.
+func (w *Watchtower) reportError(err error) {
+	if err == nil {
+		return
+	}
+	w.logger.Print(err)
+	select {
+	case w.errors <- err:
+	default:
+	}
+}
+
+// validateSupported checks that the state carries a valid signature from every participant.
+func validateSupported(ss state.SignedState) error {
+	s := ss.State()
+	if !ss.HasAllSignatures() {
+		return ErrUnsupportedState
+	}
+	for i, sig := range ss.Signatures() {
+		signer, err := s.RecoverSigner(sig)
+		if err != nil || signer != s.Participants[i] {
+			return ErrUnsupportedState
+		}
+	}
+	return nil
+}
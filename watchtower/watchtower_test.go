@@ -0,0 +1,59 @@
+package watchtower
+
+import (
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/client/engine/chainservice"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestGuard(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+	w := Watchtower{chain: chainservice.NewMockChain(), store: NewMemStore(), logger: log.New(io.Discard, "", 0)}
+
+	s := state.TestState.Clone()
+	s.Participants = []types.Address{alice.Address(), bob.Address()}
+
+	// A state which is not signed by every participant is rejected
+	ss := state.NewSignedState(s)
+	aliceSig, _ := s.Sign(alice.PrivateKey)
+	_ = ss.AddSignature(aliceSig)
+	if err := w.guard(ss); !errors.Is(err, ErrUnsupportedState) {
+		t.Fatalf("expected %v, got %v", ErrUnsupportedState, err)
+	}
+	if _, ok := w.store.GetState(ss.ChannelId()); ok {
+		t.Fatal("expected the watchtower not to hold an unsupported state")
+	}
+
+	// A supported state is held
+	bobSig, _ := s.Sign(bob.PrivateKey)
+	_ = ss.AddSignature(bobSig)
+	if err := w.guard(ss); err != nil {
+		t.Fatal(err)
+	}
+	held, ok := w.store.GetState(ss.ChannelId())
+	if !ok || held.State().TurnNum != s.TurnNum {
+		t.Fatalf("expected the watchtower to hold turn %d, got %+v", s.TurnNum, held)
+	}
+
+	// An older supported state does not replace it
+	older := s.Clone()
+	older.TurnNum--
+	oss := state.NewSignedState(older)
+	for _, sk := range [][]byte{alice.PrivateKey, bob.PrivateKey} {
+		sig, _ := older.Sign(sk)
+		_ = oss.AddSignature(sig)
+	}
+	if err := w.guard(oss); err != nil {
+		t.Fatal(err)
+	}
+	held, _ = w.store.GetState(ss.ChannelId())
+	if held.State().TurnNum != s.TurnNum {
+		t.Fatalf("expected the watchtower to hold turn %d, got %d", s.TurnNum, held.State().TurnNum)
+	}
+}